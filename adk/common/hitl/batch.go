@@ -0,0 +1,137 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package hitl contains helpers for human-in-the-loop runners that may be interrupted
+// at several points at once, e.g. a ChatModelAgent emitting parallel tool calls that
+// are each wrapped with an approval tool.
+package hitl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cloudwego/eino/adk"
+)
+
+// PendingInterrupts returns every interrupt point of an interrupted event, in the order
+// the agent reported them. It returns nil if the event was not interrupted.
+func PendingInterrupts(event *adk.AgentEvent) []*adk.InterruptCtx {
+	if event == nil || event.Action == nil || event.Action.Interrupted == nil {
+		return nil
+	}
+	return event.Action.Interrupted.InterruptContexts
+}
+
+// Batch collects resume data for all interrupts of one checkpoint, so that they are
+// resumed together by a single Runner.ResumeWithParams call.
+//
+// Interrupts left undecided are not resumed; the tools behind them interrupt again and
+// show up in the next interrupted event.
+type Batch struct {
+	CheckPointID string
+
+	pending   []*adk.InterruptCtx
+	decisions map[string]any
+}
+
+// NewBatch creates a batch for the interrupts reported by event.
+func NewBatch(checkPointID string, event *adk.AgentEvent) *Batch {
+	return &Batch{
+		CheckPointID: checkPointID,
+		pending:      PendingInterrupts(event),
+		decisions:    make(map[string]any),
+	}
+}
+
+// Pending returns all interrupts of the batch, decided or not.
+func (b *Batch) Pending() []*adk.InterruptCtx {
+	return b.pending
+}
+
+// Undecided returns the interrupts that have no resume data yet.
+func (b *Batch) Undecided() []*adk.InterruptCtx {
+	var out []*adk.InterruptCtx
+	for _, ic := range b.pending {
+		if _, ok := b.decisions[ic.ID]; !ok {
+			out = append(out, ic)
+		}
+	}
+	return out
+}
+
+// Decide sets the resume data for one interrupt, replacing any previous decision.
+func (b *Batch) Decide(interruptID string, data any) error {
+	if b.find(interruptID) == nil {
+		return fmt.Errorf("interrupt '%s' is not pending in checkpoint '%s'", interruptID, b.CheckPointID)
+	}
+	b.decisions[interruptID] = data
+	return nil
+}
+
+// DecideAll sets the resume data for several interrupts at once. It is all or nothing:
+// if any ID is not pending, no decision is recorded.
+func (b *Batch) DecideAll(decisions map[string]any) error {
+	ids := make([]string, 0, len(decisions))
+	for id := range decisions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if b.find(id) == nil {
+			return fmt.Errorf("interrupt '%s' is not pending in checkpoint '%s'", id, b.CheckPointID)
+		}
+	}
+	for id, data := range decisions {
+		b.decisions[id] = data
+	}
+	return nil
+}
+
+// Targets returns a copy of the decisions, keyed by interrupt ID, as expected by adk.ResumeParams.
+func (b *Batch) Targets() map[string]any {
+	targets := make(map[string]any, len(b.decisions))
+	for id, data := range b.decisions {
+		targets[id] = data
+	}
+	return targets
+}
+
+// Resume resumes every decided interrupt in one call.
+func (b *Batch) Resume(ctx context.Context, runner *adk.Runner, opts ...adk.AgentRunOption) (
+	*adk.AsyncIterator[*adk.AgentEvent], error) {
+	return ResumeAll(ctx, runner, b.CheckPointID, b.Targets(), opts...)
+}
+
+func (b *Batch) find(interruptID string) *adk.InterruptCtx {
+	for _, ic := range b.pending {
+		if ic.ID == interruptID {
+			return ic
+		}
+	}
+	return nil
+}
+
+// ResumeAll resumes the interrupts in targets, keyed by interrupt ID, with a single
+// Runner.ResumeWithParams call. Resuming with no targets is an error, since it would
+// only make every interrupt fire again.
+func ResumeAll(ctx context.Context, runner *adk.Runner, checkPointID string, targets map[string]any,
+	opts ...adk.AgentRunOption) (*adk.AsyncIterator[*adk.AgentEvent], error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no resume data for checkpoint '%s'", checkPointID)
+	}
+	return runner.ResumeWithParams(ctx, checkPointID, &adk.ResumeParams{Targets: targets}, opts...)
+}
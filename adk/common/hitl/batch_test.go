@@ -0,0 +1,136 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hitl
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+func interruptedEvent(contexts ...*adk.InterruptCtx) *adk.AgentEvent {
+	return &adk.AgentEvent{Action: &adk.AgentAction{Interrupted: &adk.InterruptInfo{InterruptContexts: contexts}}}
+}
+
+func TestBatch_Decide(t *testing.T) {
+	b := NewBatch("cp-1", interruptedEvent(
+		&adk.InterruptCtx{ID: "a", Info: &tool.ApprovalInfo{ToolName: "t1"}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "b", Info: &tool.ApprovalInfo{ToolName: "t2"}, IsRootCause: true},
+	))
+
+	if err := b.Decide("c", true); err == nil {
+		t.Fatalf("deciding an unknown interrupt should fail")
+	}
+	if err := b.DecideAll(map[string]any{"a": 1, "c": 2}); err == nil {
+		t.Fatalf("DecideAll with an unknown interrupt should fail")
+	}
+	if len(b.Targets()) != 0 {
+		t.Fatalf("failed DecideAll must not record anything, got %v", b.Targets())
+	}
+
+	if err := b.Decide("b", &tool.ApprovalResult{Approved: true}); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	undecided := b.Undecided()
+	if len(undecided) != 1 || undecided[0].ID != "a" {
+		t.Fatalf("unexpected undecided interrupts: %v", undecided)
+	}
+	if targets := b.Targets(); len(targets) != 1 || targets["b"] == nil {
+		t.Fatalf("unexpected targets: %v", targets)
+	}
+
+	if PendingInterrupts(&adk.AgentEvent{}) != nil {
+		t.Fatalf("an event without interrupt has no pending interrupts")
+	}
+}
+
+func TestTerminal_Collect(t *testing.T) {
+	b := NewBatch("cp-1", interruptedEvent(
+		&adk.InterruptCtx{ID: "approve", Info: &tool.ApprovalInfo{ToolName: "t1"}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "deny", Info: &tool.ApprovalInfo{ToolName: "t2"}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "skip", Info: &tool.ApprovalInfo{ToolName: "t3"}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "edit", Info: &tool.ReviewEditInfo{ToolName: "t4", ArgumentsInJSON: `{}`}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "ask", Info: &tool.FollowUpInfo{Questions: []string{"why?"}}, IsRootCause: true},
+	))
+
+	input := strings.Join([]string{
+		"maybe", "y", // invalid input is asked again
+		"n", "too expensive",
+		"s",
+		`{"a": 1}`,
+		"because",
+	}, "\n")
+	term := NewTerminal(&TerminalConfig{In: strings.NewReader(input), Out: io.Discard})
+	if err := term.Collect(b); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	targets := b.Targets()
+	if len(targets) != 4 {
+		t.Fatalf("expected 4 decisions, got %v", targets)
+	}
+	if r := targets["approve"].(*tool.ApprovalResult); !r.Approved {
+		t.Fatalf("expected approval, got %+v", r)
+	}
+	if r := targets["deny"].(*tool.ApprovalResult); r.Approved || r.DisapproveReason == nil || *r.DisapproveReason != "too expensive" {
+		t.Fatalf("expected denial with reason, got %+v", r)
+	}
+	if _, ok := targets["skip"]; ok {
+		t.Fatalf("skipped interrupt must stay undecided")
+	}
+	if r := targets["edit"].(*tool.ReviewEditInfo); r.ReviewResult == nil || *r.ReviewResult.EditedArgumentsInJSON != `{"a": 1}` {
+		t.Fatalf("unexpected review result: %+v", r.ReviewResult)
+	}
+	if r := targets["ask"].(*tool.FollowUpInfo); r.UserAnswer != "because" {
+		t.Fatalf("unexpected follow-up answer: %+v", r)
+	}
+
+	// the input is exhausted, so asking about the skipped interrupt again ends with io.EOF
+	if err := term.Collect(b); err != io.EOF {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestApprovalPrompter_RememberOnlyForGate(t *testing.T) {
+	b := NewBatch("cp-1", interruptedEvent(
+		&adk.InterruptCtx{ID: "wrapper", Info: &tool.ApprovalInfo{ToolName: "t1"}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "gate", Info: &tool.ApprovalInfo{ToolName: "t2", Rememberable: true}, IsRootCause: true},
+	))
+
+	// "a" is not offered for the wrapper, which would ignore it, and is asked again
+	input := strings.Join([]string{"a", "y", "a"}, "\n")
+	var out strings.Builder
+	term := NewTerminal(&TerminalConfig{In: strings.NewReader(input), Out: &out})
+	if err := term.Collect(b); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+
+	targets := b.Targets()
+	if r := targets["wrapper"].(*tool.ApprovalResult); !r.Approved || r.RememberForSession {
+		t.Fatalf("expected a plain approval for the wrapper, got %+v", r)
+	}
+	if r := targets["gate"].(*tool.ApprovalResult); !r.Approved || !r.RememberForSession {
+		t.Fatalf("expected a remembered approval for the gate, got %+v", r)
+	}
+	if strings.Count(out.String(), "A = yes for the rest of the session") != 1 {
+		t.Fatalf("the session choice should only be offered for the gate:\n%s", out.String())
+	}
+}
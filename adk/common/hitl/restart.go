@@ -0,0 +1,60 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hitl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/compose"
+
+	"github.com/cloudwego/eino-examples/adk/common/store"
+)
+
+// ResumeFlag registers the -resume flag shared by the human-in-the-loop examples.
+// It must be called before flag.Parse.
+func ResumeFlag() *bool {
+	return flag.Bool("resume", false,
+		"continue the run interrupted by a previous process, requires a durable CHECKPOINT_STORE (file or redis)")
+}
+
+// QueryOrResume starts a new run of query under checkPointID or, when resume is true, continues
+// the run that an earlier process persisted under checkPointID.
+//
+// No resume data is passed when continuing: the interrupted tools interrupt again with the state
+// they stored, so the pending interrupts are reported anew and can be answered as in the first process.
+func QueryOrResume(ctx context.Context, runner *adk.Runner, checkPointID, query string, resume bool) (
+	*adk.AsyncIterator[*adk.AgentEvent], error) {
+	if !resume {
+		return runner.Query(ctx, query, adk.WithCheckPointID(checkPointID)), nil
+	}
+	iter, err := runner.Resume(ctx, checkPointID)
+	if err != nil {
+		return nil, fmt.Errorf("resume checkpoint '%s' failed, err=%w", checkPointID, err)
+	}
+	return iter, nil
+}
+
+// PrintResumeHint tells the user that the pending interrupt can be answered from a new process,
+// when s survives a restart.
+func PrintResumeHint(s compose.CheckPointStore) {
+	if store.IsPersistent(s) {
+		fmt.Println("checkpoint persisted, you may stop here and continue later with: -resume")
+	}
+}
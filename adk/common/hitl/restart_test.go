@@ -0,0 +1,143 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hitl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	einotool "github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/adk/common/store"
+	"github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// bookingModel calls book_ticket once, then answers with the tool result.
+type bookingModel struct{}
+
+func (m bookingModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	if last := input[len(input)-1]; last.Role == schema.Tool {
+		return schema.AssistantMessage(last.Content, nil), nil
+	}
+	return schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call-1",
+		Function: schema.FunctionCall{Name: "book_ticket", Arguments: `{"to":"Beijing"}`},
+	}}), nil
+}
+
+func (m bookingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m bookingModel) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func newBookingRunner(t *testing.T, s compose.CheckPointStore) *adk.Runner {
+	return newBookingRunnerWithTimeout(t, s, nil)
+}
+
+// newBookingRunnerWithTimeout resolves the approval of book_ticket according to timeout.
+func newBookingRunnerWithTimeout(t *testing.T, s compose.CheckPointStore, timeout *tool.TimeoutPolicy) *adk.Runner {
+	type bookInput struct {
+		To string `json:"to"`
+	}
+	book, err := utils.InferTool("book_ticket", "book a ticket", func(_ context.Context, in *bookInput) (string, error) {
+		return "booked to " + in.To, nil
+	})
+	if err != nil {
+		t.Fatalf("infer tool: %v", err)
+	}
+	a, err := adk.NewChatModelAgent(context.Background(), &adk.ChatModelAgentConfig{
+		Name:        "booking",
+		Description: "books tickets",
+		Model:       bookingModel{},
+		ToolsConfig: adk.ToolsConfig{ToolsNodeConfig: compose.ToolsNodeConfig{
+			Tools: []einotool.BaseTool{&tool.InvokableApprovableTool{InvokableTool: book, Timeout: timeout}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	return adk.NewRunner(context.Background(), adk.RunnerConfig{Agent: a, CheckPointStore: s})
+}
+
+func lastEvent(t *testing.T, iter *adk.AsyncIterator[*adk.AgentEvent]) *adk.AgentEvent {
+	var last *adk.AgentEvent
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			return last
+		}
+		if event.Err != nil {
+			t.Fatalf("run: %v", event.Err)
+		}
+		last = event
+	}
+}
+
+func TestQueryOrResume_AfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	newStore := func() compose.CheckPointStore {
+		s, err := store.NewFileStore(&store.FileStoreConfig{Dir: dir})
+		if err != nil {
+			t.Fatalf("new file store: %v", err)
+		}
+		return s
+	}
+
+	iter, err := QueryOrResume(ctx, newBookingRunner(t, newStore()), "cp-1", "book a ticket", false)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if pending := PendingInterrupts(lastEvent(t, iter)); len(pending) != 1 {
+		t.Fatalf("expected one interrupt, got %d", len(pending))
+	}
+
+	// a new process only knows the checkpoint ID, the interrupt is reported again with its info
+	runner := newBookingRunner(t, newStore())
+	iter, err = QueryOrResume(ctx, runner, "cp-1", "", true)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	pending := PendingInterrupts(lastEvent(t, iter))
+	if len(pending) != 1 {
+		t.Fatalf("expected the interrupt to be reported again, got %d", len(pending))
+	}
+	info, ok := pending[0].Info.(*tool.ApprovalInfo)
+	if !ok || info.ArgumentsInJSON != `{"to":"Beijing"}` {
+		t.Fatalf("unexpected interrupt info %#v", pending[0].Info)
+	}
+
+	iter, err = ResumeAll(ctx, runner, "cp-1", map[string]any{pending[0].ID: &tool.ApprovalResult{Approved: true}})
+	if err != nil {
+		t.Fatalf("resume with approval: %v", err)
+	}
+	if last := lastEvent(t, iter); last == nil || last.Output == nil || last.Output.MessageOutput.Message.Content != "booked to Beijing" {
+		t.Fatalf("unexpected final event %+v", last)
+	}
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hitl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/compose"
+
+	"github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// Expiring is implemented by interrupt infos that resolve to a default decision after
+// a deadline, such as tool.ApprovalInfo and tool.FollowUpInfo with a TimeoutPolicy.
+type Expiring interface {
+	// ExpiresAt returns the deadline, zero if the interrupt never expires.
+	ExpiresAt() time.Time
+}
+
+// PendingCheckPoint records the deadlines of the expiring interrupts of one checkpoint.
+type PendingCheckPoint struct {
+	CheckPointID string               `json:"check_point_id"`
+	Deadlines    map[string]time.Time `json:"deadlines"`          // interrupt ID -> deadline
+	Attempts     int                  `json:"attempts,omitempty"` // failed resumes so far
+}
+
+// Expired returns the IDs of the interrupts whose deadline is not after now, sorted.
+func (p *PendingCheckPoint) Expired(now time.Time) []string {
+	var ids []string
+	for id, deadline := range p.Deadlines {
+		if !deadline.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// DeadlineIndex keeps track of the checkpoints that have expiring interrupts. The checkpoint
+// store itself cannot be listed, so the Sweeper relies on this index to find them.
+type DeadlineIndex interface {
+	Put(ctx context.Context, pending *PendingCheckPoint) error
+	Delete(ctx context.Context, checkPointID string) error
+	List(ctx context.Context) ([]*PendingCheckPoint, error)
+}
+
+// NewMemoryDeadlineIndex keeps the index in memory. It is lost when the process exits.
+func NewMemoryDeadlineIndex() DeadlineIndex {
+	return &storeDeadlineIndex{}
+}
+
+// NewStoreDeadlineIndex keeps the whole index as one JSON document under key in s, typically
+// the same store as the checkpoints, so a restarted sweeper still finds them.
+// It serializes access within one process; do not share the key between processes.
+func NewStoreDeadlineIndex(s compose.CheckPointStore, key string) DeadlineIndex {
+	return &storeDeadlineIndex{store: s, key: key}
+}
+
+type storeDeadlineIndex struct {
+	mu    sync.Mutex
+	store compose.CheckPointStore
+	key   string
+	mem   map[string]*PendingCheckPoint
+}
+
+func (x *storeDeadlineIndex) Put(ctx context.Context, pending *PendingCheckPoint) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	all, err := x.load(ctx)
+	if err != nil {
+		return err
+	}
+	all[pending.CheckPointID] = pending
+	return x.save(ctx, all)
+}
+
+func (x *storeDeadlineIndex) Delete(ctx context.Context, checkPointID string) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	all, err := x.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := all[checkPointID]; !ok {
+		return nil
+	}
+	delete(all, checkPointID)
+	return x.save(ctx, all)
+}
+
+func (x *storeDeadlineIndex) List(ctx context.Context) ([]*PendingCheckPoint, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	all, err := x.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*PendingCheckPoint, 0, len(all))
+	for _, p := range all {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CheckPointID < out[j].CheckPointID })
+	return out, nil
+}
+
+func (x *storeDeadlineIndex) load(ctx context.Context) (map[string]*PendingCheckPoint, error) {
+	if x.store == nil {
+		if x.mem == nil {
+			x.mem = make(map[string]*PendingCheckPoint)
+		}
+		return x.mem, nil
+	}
+	all := make(map[string]*PendingCheckPoint)
+	data, ok, err := x.store.Get(ctx, x.key)
+	if err != nil {
+		return nil, fmt.Errorf("load deadline index: %w", err)
+	}
+	if !ok || len(data) == 0 {
+		return all, nil
+	}
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("decode deadline index: %w", err)
+	}
+	return all, nil
+}
+
+func (x *storeDeadlineIndex) save(ctx context.Context, all map[string]*PendingCheckPoint) error {
+	if x.store == nil {
+		return nil
+	}
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	if err = x.store.Set(ctx, x.key, data); err != nil {
+		return fmt.Errorf("save deadline index: %w", err)
+	}
+	return nil
+}
+
+// SweeperConfig configures a Sweeper.
+type SweeperConfig struct {
+	// Runner resumes expired checkpoints. It must use the store the checkpoints were written to. Required.
+	Runner *adk.Runner
+
+	// Index defaults to NewMemoryDeadlineIndex.
+	Index DeadlineIndex
+
+	// Interval between sweeps in Run. Defaults to one minute.
+	Interval time.Duration
+
+	// MaxAttempts is how many times a checkpoint is resumed before the sweeper gives up on it,
+	// when resuming fails. Defaults to 3.
+	MaxAttempts int
+
+	// OnEvent receives every event of the resumed runs. Optional.
+	OnEvent func(checkPointID string, event *adk.AgentEvent)
+}
+
+// Sweeper resumes checkpoints whose interrupts have passed their deadline, letting the
+// interrupted tools apply their TimeoutPolicy without anyone answering.
+//
+// Interrupted runs are registered with Track. After a sweep resumes a checkpoint, the new
+// outcome is tracked again, so interrupts that are still pending keep being watched.
+// A run failed by a TimeoutFail policy counts as swept and is no longer tracked. A checkpoint
+// that fails to resume for another reason stays tracked and is retried by the next sweeps,
+// up to MaxAttempts times.
+type Sweeper struct {
+	runner      *adk.Runner
+	index       DeadlineIndex
+	interval    time.Duration
+	maxAttempts int
+	onEvent     func(checkPointID string, event *adk.AgentEvent)
+}
+
+func NewSweeper(config *SweeperConfig) (*Sweeper, error) {
+	if config == nil || config.Runner == nil {
+		return nil, fmt.Errorf("sweeper runner is required")
+	}
+	index := config.Index
+	if index == nil {
+		index = NewMemoryDeadlineIndex()
+	}
+	interval := config.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	maxAttempts := config.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	return &Sweeper{
+		runner:      config.Runner,
+		index:       index,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		onEvent:     config.OnEvent,
+	}, nil
+}
+
+// Track records the expiring interrupts of the last event of a run on checkPointID.
+// An event without expiring interrupts removes the checkpoint from the index, so Track
+// can be called with the last event of every run.
+func (s *Sweeper) Track(ctx context.Context, checkPointID string, event *adk.AgentEvent) error {
+	pending := pendingCheckPoint(checkPointID, event)
+	if pending == nil {
+		return s.index.Delete(ctx, checkPointID)
+	}
+	return s.index.Put(ctx, pending)
+}
+
+// Sweep resumes every tracked checkpoint with at least one expired interrupt and returns
+// how many were resumed. Only the expired interrupts are targeted; the others interrupt again.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	all, err := s.index.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		resumed int
+		errs    []error
+	)
+	now := time.Now()
+	for _, pending := range all {
+		expired := pending.Expired(now)
+		if len(expired) == 0 {
+			continue
+		}
+		if err = s.resume(ctx, pending, expired); err != nil {
+			errs = append(errs, fmt.Errorf("checkpoint '%s': %w", pending.CheckPointID, err))
+			continue
+		}
+		resumed++
+	}
+	return resumed, errors.Join(errs...)
+}
+
+// Run sweeps every Interval until ctx is done. Sweep errors are logged.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Sweep(ctx); err != nil {
+			log.Printf("sweep expired checkpoints: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Sweeper) resume(ctx context.Context, pending *PendingCheckPoint, expired []string) error {
+	lastEvent, err := s.resumeExpired(ctx, pending.CheckPointID, expired)
+	if err == nil {
+		return s.Track(ctx, pending.CheckPointID, lastEvent)
+	}
+	if errors.Is(err, tool.ErrInterruptExpired) {
+		// a TimeoutFail policy ended the run as configured, there is nothing left to resume
+		return s.index.Delete(ctx, pending.CheckPointID)
+	}
+
+	// keep the checkpoint tracked for the next sweeps, until it failed too many times
+	pending.Attempts++
+	if pending.Attempts >= s.maxAttempts {
+		if delErr := s.index.Delete(ctx, pending.CheckPointID); delErr != nil {
+			return errors.Join(err, delErr)
+		}
+		return fmt.Errorf("giving up after %d attempts: %w", pending.Attempts, err)
+	}
+	if putErr := s.index.Put(ctx, pending); putErr != nil {
+		return errors.Join(err, putErr)
+	}
+	return err
+}
+
+// resumeExpired resumes the expired interrupts of checkPointID and returns the last event of the run.
+func (s *Sweeper) resumeExpired(ctx context.Context, checkPointID string, expired []string) (*adk.AgentEvent, error) {
+	// the tools decide on their own once expired, so no resume data is needed
+	targets := make(map[string]any, len(expired))
+	for _, id := range expired {
+		targets[id] = nil
+	}
+
+	iter, err := ResumeAll(ctx, s.runner, checkPointID, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastEvent *adk.AgentEvent
+	var runErr error
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if s.onEvent != nil {
+			s.onEvent(checkPointID, event)
+		}
+		if event.Err != nil {
+			runErr = event.Err
+		}
+		lastEvent = event
+	}
+	return lastEvent, runErr
+}
+
+func pendingCheckPoint(checkPointID string, event *adk.AgentEvent) *PendingCheckPoint {
+	deadlines := make(map[string]time.Time)
+	for _, ic := range PendingInterrupts(event) {
+		e, ok := ic.Info.(Expiring)
+		if !ok {
+			continue
+		}
+		if deadline := e.ExpiresAt(); !deadline.IsZero() {
+			deadlines[ic.ID] = deadline
+		}
+	}
+	if len(deadlines) == 0 {
+		return nil
+	}
+	return &PendingCheckPoint{CheckPointID: checkPointID, Deadlines: deadlines}
+}
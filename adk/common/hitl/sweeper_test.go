@@ -0,0 +1,173 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hitl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/common/store"
+	"github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+func TestSweeper_Track(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	sweeper, err := NewSweeper(&SweeperConfig{
+		Runner: &adk.Runner{},
+		Index:  NewStoreDeadlineIndex(s, "deadlines"),
+	})
+	if err != nil {
+		t.Fatalf("new sweeper: %v", err)
+	}
+
+	now := time.Now()
+	err = sweeper.Track(ctx, "cp-1", interruptedEvent(
+		&adk.InterruptCtx{ID: "late", Info: &tool.ApprovalInfo{Deadline: now.Add(-time.Second)}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "early", Info: &tool.FollowUpInfo{Deadline: now.Add(time.Hour)}, IsRootCause: true},
+		&adk.InterruptCtx{ID: "never", Info: &tool.ApprovalInfo{}, IsRootCause: true},
+	))
+	if err != nil {
+		t.Fatalf("track: %v", err)
+	}
+	if err = sweeper.Track(ctx, "cp-2", interruptedEvent(
+		&adk.InterruptCtx{ID: "never", Info: &tool.ApprovalInfo{}, IsRootCause: true},
+	)); err != nil {
+		t.Fatalf("track: %v", err)
+	}
+
+	// a fresh index on the same store sees what was tracked
+	all, err := NewStoreDeadlineIndex(s, "deadlines").List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 1 || all[0].CheckPointID != "cp-1" || len(all[0].Deadlines) != 2 {
+		t.Fatalf("unexpected index: %+v", all)
+	}
+	if expired := all[0].Expired(now); len(expired) != 1 || expired[0] != "late" {
+		t.Fatalf("unexpected expired interrupts: %v", expired)
+	}
+	if expired := all[0].Expired(now.Add(2 * time.Hour)); len(expired) != 2 {
+		t.Fatalf("unexpected expired interrupts: %v", expired)
+	}
+
+	// a run that completed is no longer tracked
+	if err = sweeper.Track(ctx, "cp-1", &adk.AgentEvent{}); err != nil {
+		t.Fatalf("track: %v", err)
+	}
+	if all, _ = sweeper.index.List(ctx); len(all) != 0 {
+		t.Fatalf("expected empty index, got %+v", all)
+	}
+}
+
+func TestSweeper_RetriesFailedResume(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewInMemoryStore()
+	sweeper, err := NewSweeper(&SweeperConfig{
+		Runner:      newBookingRunner(t, s),
+		Index:       NewStoreDeadlineIndex(s, "deadlines"),
+		MaxAttempts: 2,
+	})
+	if err != nil {
+		t.Fatalf("new sweeper: %v", err)
+	}
+
+	// the checkpoint is missing from the store, so resuming it fails
+	if err = sweeper.Track(ctx, "cp-lost", interruptedEvent(
+		&adk.InterruptCtx{ID: "late", Info: &tool.ApprovalInfo{Deadline: time.Now().Add(-time.Second)}, IsRootCause: true},
+	)); err != nil {
+		t.Fatalf("track: %v", err)
+	}
+
+	if n, err := sweeper.Sweep(ctx); n != 0 || err == nil {
+		t.Fatalf("expected the resume to fail, got %d resumed, err %v", n, err)
+	}
+	all, _ := sweeper.index.List(ctx)
+	if len(all) != 1 || all[0].Attempts != 1 {
+		t.Fatalf("expected the checkpoint to stay tracked after one attempt, got %+v", all)
+	}
+
+	if _, err = sweeper.Sweep(ctx); err == nil {
+		t.Fatalf("expected the resume to fail again")
+	}
+	if all, _ = sweeper.index.List(ctx); len(all) != 0 {
+		t.Fatalf("expected the sweeper to give up after 2 attempts, got %+v", all)
+	}
+}
+
+func TestSweeper_ResumesExpired(t *testing.T) {
+	cases := []struct {
+		action  tool.TimeoutAction
+		want    string
+		wantErr error
+	}{
+		{action: tool.TimeoutApprove, want: "booked to Beijing"},
+		{action: tool.TimeoutReject, want: "tool 'book_ticket' disapproved, reason: nobody answered"},
+		// the run fails as configured, the sweep does not
+		{action: tool.TimeoutFail, wantErr: tool.ErrInterruptExpired},
+	}
+	for _, c := range cases {
+		t.Run(string(c.action), func(t *testing.T) {
+			ctx := context.Background()
+			s := store.NewInMemoryStore()
+			runner := newBookingRunnerWithTimeout(t, s, &tool.TimeoutPolicy{
+				After: 10 * time.Millisecond, Action: c.action, Reason: "nobody answered"})
+
+			var last *adk.AgentEvent
+			sweeper, err := NewSweeper(&SweeperConfig{
+				Runner:  runner,
+				Index:   NewStoreDeadlineIndex(s, "deadlines"),
+				OnEvent: func(_ string, event *adk.AgentEvent) { last = event },
+			})
+			if err != nil {
+				t.Fatalf("new sweeper: %v", err)
+			}
+
+			iter := runner.Query(ctx, "book a ticket", adk.WithCheckPointID("cp-1"))
+			if err = sweeper.Track(ctx, "cp-1", lastEvent(t, iter)); err != nil {
+				t.Fatalf("track: %v", err)
+			}
+
+			// nothing has expired yet
+			if n, err := sweeper.Sweep(ctx); n != 0 || err != nil {
+				t.Fatalf("expected nothing to sweep, got %d resumed, err %v", n, err)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			if n, err := sweeper.Sweep(ctx); n != 1 || err != nil {
+				t.Fatalf("expected the checkpoint to be resumed, got %d resumed, err %v", n, err)
+			}
+			if all, _ := sweeper.index.List(ctx); len(all) != 0 {
+				t.Fatalf("expected the finished run to be untracked, got %+v", all)
+			}
+
+			if c.wantErr != nil {
+				if last == nil || !errors.Is(last.Err, c.wantErr) {
+					t.Fatalf("expected the run to fail with %v, got %+v", c.wantErr, last)
+				}
+				return
+			}
+			if last == nil || last.Output == nil || last.Output.MessageOutput.Message.Content != c.want {
+				t.Fatalf("unexpected final event %+v", last)
+			}
+		})
+	}
+}
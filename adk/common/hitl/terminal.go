@@ -0,0 +1,226 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hitl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// Prompter asks the user for the resume data of one interrupt.
+// handled is false if the prompter does not know the interrupt's Info type.
+// Returning handled with nil data skips the interrupt, leaving it pending.
+type Prompter func(t *Terminal, ic *adk.InterruptCtx) (data any, handled bool, err error)
+
+// TerminalConfig configures a Terminal.
+type TerminalConfig struct {
+	// In defaults to os.Stdin.
+	In io.Reader
+	// Out defaults to os.Stdout.
+	Out io.Writer
+
+	// Prompters are tried in order before the built-in prompters for
+	// ApprovalInfo, ReviewEditInfo and FollowUpInfo.
+	Prompters []Prompter
+}
+
+// Terminal walks the user through every pending decision of a Batch on a line-based terminal.
+type Terminal struct {
+	scanner   *bufio.Scanner
+	out       io.Writer
+	prompters []Prompter
+}
+
+func NewTerminal(config *TerminalConfig) *Terminal {
+	if config == nil {
+		config = &TerminalConfig{}
+	}
+	in, out := config.In, config.Out
+	if in == nil {
+		in = os.Stdin
+	}
+	if out == nil {
+		out = os.Stdout
+	}
+	prompters := append([]Prompter{}, config.Prompters...)
+	prompters = append(prompters, ApprovalPrompter, ReviewEditPrompter, FollowUpPrompter)
+	return &Terminal{
+		scanner:   bufio.NewScanner(in),
+		out:       out,
+		prompters: prompters,
+	}
+}
+
+// Printf writes to the terminal.
+func (t *Terminal) Printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(t.out, format, args...)
+}
+
+// Ask prints prompt and returns the next input line without surrounding spaces.
+// It returns io.EOF once the input is closed.
+func (t *Terminal) Ask(prompt string) (string, error) {
+	t.Printf("%s", prompt)
+	if !t.scanner.Scan() {
+		if err := t.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	t.Printf("\n")
+	return strings.TrimSpace(t.scanner.Text()), nil
+}
+
+// Collect asks for a decision on every undecided interrupt of b and records the answers.
+// It fails if no prompter handles an interrupt.
+func (t *Terminal) Collect(b *Batch) error {
+	undecided := b.Undecided()
+	for i, ic := range undecided {
+		t.Printf("\n========================================\n")
+		t.Printf("Pending decision %d of %d\n", i+1, len(undecided))
+		t.Printf("interrupt id: %s\n", ic.ID)
+		t.Printf("========================================\n")
+		if s, ok := ic.Info.(fmt.Stringer); ok {
+			t.Printf("%s\n\n", s.String())
+		} else {
+			t.Printf("%v\n\n", ic.Info)
+		}
+
+		data, err := t.prompt(ic)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			t.Printf("skipped, it will be asked again after resuming\n")
+			continue
+		}
+		if err = b.Decide(ic.ID, data); err != nil {
+			return err
+		}
+	}
+
+	t.Printf("\n%d of %d pending decision(s) will be resumed together\n", len(b.Targets()), len(b.Pending()))
+	return nil
+}
+
+func (t *Terminal) prompt(ic *adk.InterruptCtx) (any, error) {
+	for _, p := range t.prompters {
+		data, handled, err := p(t, ic)
+		if err != nil {
+			return nil, err
+		}
+		if handled {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("no prompter for interrupt '%s' with info %T", ic.ID, ic.Info)
+}
+
+// ApprovalPrompter answers a *tool.ApprovalInfo with a *tool.ApprovalResult. "yes for the rest
+// of the session" is only offered for the interrupts of an ApprovalGate, which can remember it.
+func ApprovalPrompter(t *Terminal, ic *adk.InterruptCtx) (any, bool, error) {
+	info, ok := ic.Info.(*tool.ApprovalInfo)
+	if !ok {
+		return nil, false, nil
+	}
+	prompt, choices := "Approve? Y = yes, N = no, S = skip: ", "Y, N or S"
+	if info.Rememberable {
+		prompt, choices = "Approve? Y = yes, N = no, A = yes for the rest of the session, S = skip: ", "Y, N, A or S"
+	}
+	for {
+		input, err := t.Ask(prompt)
+		if err != nil {
+			return nil, true, err
+		}
+		switch strings.ToUpper(input) {
+		case "Y":
+			return &tool.ApprovalResult{Approved: true}, true, nil
+		case "A":
+			if info.Rememberable {
+				return &tool.ApprovalResult{Approved: true, RememberForSession: true}, true, nil
+			}
+		case "N":
+			reason, err := t.Ask("Please provide a reason for denial: ")
+			if err != nil {
+				return nil, true, err
+			}
+			return &tool.ApprovalResult{Approved: false, DisapproveReason: &reason}, true, nil
+		case "S":
+			return nil, true, nil
+		}
+		t.Printf("invalid input, please input %s\n", choices)
+	}
+}
+
+// ReviewEditPrompter answers a *tool.ReviewEditInfo with the same info carrying a ReviewResult.
+func ReviewEditPrompter(t *Terminal, ic *adk.InterruptCtx) (any, bool, error) {
+	info, ok := ic.Info.(*tool.ReviewEditInfo)
+	if !ok {
+		return nil, false, nil
+	}
+	input, err := t.Ask("Your input (edited JSON, 'no need to edit', 'N' or 'S' to skip): ")
+	if err != nil {
+		return nil, true, err
+	}
+
+	result := &tool.ReviewEditResult{}
+	switch strings.ToLower(input) {
+	case "s":
+		return nil, true, nil
+	case "no need to edit":
+		result.NoNeedToEdit = true
+	case "n":
+		result.Disapproved = true
+		reason, err := t.Ask("Reason for disapproval (optional): ")
+		if err != nil {
+			return nil, true, err
+		}
+		if reason != "" {
+			result.DisapproveReason = &reason
+		}
+	default:
+		result.EditedArgumentsInJSON = &input
+	}
+
+	resumed := *info
+	resumed.ReviewResult = result
+	return &resumed, true, nil
+}
+
+// FollowUpPrompter answers a *tool.FollowUpInfo with the same info carrying the user's answer.
+func FollowUpPrompter(t *Terminal, ic *adk.InterruptCtx) (any, bool, error) {
+	info, ok := ic.Info.(*tool.FollowUpInfo)
+	if !ok {
+		return nil, false, nil
+	}
+	answer, err := t.Ask("Your answer (empty to skip): ")
+	if err != nil {
+		return nil, true, err
+	}
+	if answer == "" {
+		return nil, true, nil
+	}
+	resumed := *info
+	resumed.UserAnswer = answer
+	return &resumed, true, nil
+}
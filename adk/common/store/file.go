@@ -0,0 +1,240 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const checkpointFileExt = ".ckpt"
+
+// FileStoreConfig configures a FileStore.
+type FileStoreConfig struct {
+	// Dir is the directory holding one file per checkpoint. It is created if missing.
+	Dir string
+
+	// TTL is how long a checkpoint stays readable after its last write.
+	// Zero means checkpoints never expire.
+	TTL time.Duration
+
+	// GCInterval, when positive together with TTL, starts a background goroutine
+	// that removes expired checkpoint files. Stop it with Close.
+	GCInterval time.Duration
+}
+
+// FileStore is a compose.CheckPointStore backed by a local directory.
+// Each Set writes to a temp file, fsyncs it and renames it over the target,
+// so readers never observe a partially written checkpoint, even across crashes.
+type FileStore struct {
+	dir string
+	ttl time.Duration
+
+	mu sync.RWMutex
+
+	stopGC chan struct{}
+	gcDone chan struct{}
+	once   sync.Once
+}
+
+// NewFileStore creates a FileStore rooted at config.Dir.
+func NewFileStore(config *FileStoreConfig) (*FileStore, error) {
+	if config == nil || config.Dir == "" {
+		return nil, fmt.Errorf("file store dir is required")
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create checkpoint dir: %w", err)
+	}
+
+	s := &FileStore{
+		dir: config.Dir,
+		ttl: config.TTL,
+	}
+
+	if config.TTL > 0 && config.GCInterval > 0 {
+		s.stopGC = make(chan struct{})
+		s.gcDone = make(chan struct{})
+		go s.gcLoop(config.GCInterval)
+	}
+
+	return s, nil
+}
+
+// Get returns the checkpoint stored under key. Expired checkpoints are reported as absent.
+func (s *FileStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	if err := checkKey(key); err != nil {
+		return nil, false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	path := s.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if s.expired(info) {
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Set atomically replaces the checkpoint stored under key.
+func (s *FileStore) Set(_ context.Context, key string, value []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		// no-op once the rename succeeded
+		_ = os.Remove(tmpName)
+	}()
+
+	if _, err = tmp.Write(value); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, s.path(key))
+}
+
+// Delete removes the checkpoint stored under key, if any.
+func (s *FileStore) Delete(_ context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// GC removes every expired checkpoint file and returns how many were removed.
+// It is a no-op when the store has no TTL.
+func (s *FileStore) GC(_ context.Context) (int, error) {
+	if s.ttl <= 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), checkpointFileExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if !s.expired(info) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(s.dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Close stops the background GC goroutine, if one was started.
+func (s *FileStore) Close() error {
+	if s.stopGC == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		close(s.stopGC)
+		<-s.gcDone
+	})
+	return nil
+}
+
+func (s *FileStore) gcLoop(interval time.Duration) {
+	defer close(s.gcDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopGC:
+			return
+		case <-ticker.C:
+			if _, err := s.GC(context.Background()); err != nil {
+				log.Printf("checkpoint gc in %s failed: %v", s.dir, err)
+			}
+		}
+	}
+}
+
+func (s *FileStore) expired(info fs.FileInfo) bool {
+	return s.ttl > 0 && time.Since(info.ModTime()) > s.ttl
+}
+
+// path maps a checkpoint key to a file name. Keys are escaped so that IDs
+// containing separators (e.g. "session/42") stay inside the store directory.
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, url.PathEscape(key)+checkpointFileExt)
+}
@@ -14,14 +14,88 @@
  * limitations under the License.
  */
 
+// Package store provides compose.CheckPointStore implementations shared by the ADK examples.
+//
+// Three backends are available:
+//   - NewInMemoryStore: process-local map, lost on restart.
+//   - NewFileStore: one file per checkpoint under a directory, written atomically, with optional TTL.
+//   - NewRedisStore: one Redis key per checkpoint, with optional TTL.
+//
+// NewCheckPointStore picks one of them from environment variables, so the same example
+// can run in-memory by default and survive a process restart when a durable backend is configured.
 package store
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/cloudwego/eino/compose"
+	"github.com/redis/go-redis/v9"
 )
 
+// NewCheckPointStore creates a CheckPointStore according to environment variables:
+//   - CHECKPOINT_STORE: "memory" (default), "file" or "redis".
+//   - CHECKPOINT_DIR: directory used by the file store, defaults to "./data/checkpoints".
+//   - CHECKPOINT_TTL: optional expiry for file and redis stores, e.g. "24h".
+//   - REDIS_ADDR / REDIS_PASSWORD: connection settings for the redis store.
+func NewCheckPointStore() compose.CheckPointStore {
+	var ttl time.Duration
+	if v := os.Getenv("CHECKPOINT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("invalid CHECKPOINT_TTL %q: %v", v, err)
+		}
+		ttl = d
+	}
+
+	switch storeType := strings.ToLower(os.Getenv("CHECKPOINT_STORE")); storeType {
+	case "", "memory":
+		return NewInMemoryStore()
+	case "file":
+		dir := os.Getenv("CHECKPOINT_DIR")
+		if dir == "" {
+			dir = "./data/checkpoints"
+		}
+		s, err := NewFileStore(&FileStoreConfig{Dir: dir, TTL: ttl})
+		if err != nil {
+			log.Fatalf("NewFileStore failed: %v", err)
+		}
+		return s
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		cli := redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: os.Getenv("REDIS_PASSWORD"),
+		})
+		s, err := NewRedisStore(&RedisStoreConfig{Client: cli, TTL: ttl})
+		if err != nil {
+			log.Fatalf("NewRedisStore failed: %v", err)
+		}
+		return s
+	default:
+		log.Fatalf("unknown CHECKPOINT_STORE %q, expected memory, file or redis", storeType)
+		return nil
+	}
+}
+
+// IsPersistent reports whether checkpoints written to s survive a process restart.
+func IsPersistent(s compose.CheckPointStore) bool {
+	switch s.(type) {
+	case *FileStore, *RedisStore:
+		return true
+	default:
+		return false
+	}
+}
+
 func NewInMemoryStore() compose.CheckPointStore {
 	return &inMemoryStore{
 		mem: map[string][]byte{},
@@ -29,15 +103,35 @@ func NewInMemoryStore() compose.CheckPointStore {
 }
 
 type inMemoryStore struct {
+	mu  sync.RWMutex
 	mem map[string][]byte
 }
 
 func (i *inMemoryStore) Set(ctx context.Context, key string, value []byte) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	i.mem[key] = value
 	return nil
 }
 
 func (i *inMemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	v, ok := i.mem[key]
 	return v, ok, nil
 }
+
+// Delete removes the checkpoint stored under key, if any.
+func (i *inMemoryStore) Delete(ctx context.Context, key string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.mem, key)
+	return nil
+}
+
+func checkKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("checkpoint key is empty")
+	}
+	return nil
+}
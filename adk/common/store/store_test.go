@@ -0,0 +1,146 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/cloudwego/eino/compose"
+	"github.com/redis/go-redis/v9"
+)
+
+func testRoundTrip(t *testing.T, s compose.CheckPointStore) {
+	ctx := context.Background()
+
+	if _, ok, err := s.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("expected miss, got ok=%v err=%v", ok, err)
+	}
+	if err := s.Set(ctx, "session/1", []byte("v1")); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := s.Set(ctx, "session/1", []byte("v2")); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	v, ok, err := s.Get(ctx, "session/1")
+	if err != nil || !ok || string(v) != "v2" {
+		t.Fatalf("get: %q %v %v", v, ok, err)
+	}
+}
+
+func testConcurrent(t *testing.T, s compose.CheckPointStore) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("k%d", i%4)
+			if err := s.Set(ctx, key, []byte(key)); err != nil {
+				t.Errorf("set: %v", err)
+			}
+			if v, ok, err := s.Get(ctx, key); err != nil || !ok || string(v) != key {
+				t.Errorf("get %s: %q %v %v", key, v, ok, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestInMemoryStore(t *testing.T) {
+	testRoundTrip(t, NewInMemoryStore())
+	testConcurrent(t, NewInMemoryStore())
+}
+
+func TestFileStore_SurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileStore(&FileStoreConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	testRoundTrip(t, s)
+	testConcurrent(t, s)
+
+	reopened, err := NewFileStore(&FileStoreConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	v, ok, err := reopened.Get(context.Background(), "session/1")
+	if err != nil || !ok || string(v) != "v2" {
+		t.Fatalf("get after reopen: %q %v %v", v, ok, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != checkpointFileExt {
+			t.Fatalf("leftover temp file %s", e.Name())
+		}
+	}
+}
+
+func TestFileStore_TTLAndGC(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(&FileStoreConfig{Dir: t.TempDir(), TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	_ = s.Set(ctx, "old", []byte("x"))
+	_ = s.Set(ctx, "new", []byte("y"))
+
+	past := time.Now().Add(-2 * time.Hour)
+	if err = os.Chtimes(s.path("old"), past, past); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	if _, ok, _ := s.Get(ctx, "old"); ok {
+		t.Fatalf("expired checkpoint should be absent")
+	}
+	n, err := s.GC(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("gc removed %d, err %v", n, err)
+	}
+	if _, ok, _ := s.Get(ctx, "new"); !ok {
+		t.Fatalf("fresh checkpoint should survive gc")
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	srv := miniredis.RunT(t)
+	cli := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	defer cli.Close()
+
+	s, err := NewRedisStore(&RedisStoreConfig{Client: cli, TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("new redis store: %v", err)
+	}
+	testRoundTrip(t, s)
+	testConcurrent(t, s)
+
+	if !srv.Exists(defaultRedisKeyPrefix + "session/1") {
+		t.Fatalf("expected prefixed key in redis")
+	}
+	srv.FastForward(2 * time.Minute)
+	if _, ok, err := s.Get(context.Background(), "session/1"); err != nil || ok {
+		t.Fatalf("expected expiry, got ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,363 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// DecisionSource tells where the final decision of an audited tool call came from.
+type DecisionSource string
+
+const (
+	DecisionSourcePolicy     DecisionSource = "policy"     // an allow/deny rule or the policy default
+	DecisionSourceHuman      DecisionSource = "human"      // an ApprovalResult supplied on resume
+	DecisionSourceRemembered DecisionSource = "remembered" // a human decision remembered for the session
+)
+
+// ApprovalAuditRecord describes one decision taken by an ApprovalGate.
+type ApprovalAuditRecord struct {
+	Time      time.Time      `json:"time"`
+	ToolName  string         `json:"tool_name"`
+	CallID    string         `json:"call_id,omitempty"`
+	Arguments string         `json:"arguments"`
+	Rule      string         `json:"rule,omitempty"`
+	Action    ApprovalAction `json:"action"`
+	Source    DecisionSource `json:"source"`
+	Approved  bool           `json:"approved"`
+	Reason    string         `json:"reason,omitempty"`
+}
+
+// AuditLogger receives every decision made by an ApprovalGate, including the
+// initial "ask" that leads to an interrupt.
+type AuditLogger interface {
+	LogDecision(ctx context.Context, record *ApprovalAuditRecord)
+}
+
+// AuditFunc adapts a function to AuditLogger.
+type AuditFunc func(ctx context.Context, record *ApprovalAuditRecord)
+
+func (f AuditFunc) LogDecision(ctx context.Context, record *ApprovalAuditRecord) {
+	f(ctx, record)
+}
+
+// NewJSONLAuditLogger writes one JSON object per decision to w. Writes are serialized.
+func NewJSONLAuditLogger(w io.Writer) AuditLogger {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return AuditFunc(func(_ context.Context, record *ApprovalAuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(record)
+	})
+}
+
+// DecisionMemory keeps human decisions the user asked to remember, so that later
+// calls matching the same rule and tool are not asked again.
+type DecisionMemory interface {
+	Recall(ctx context.Context, key string) (*ApprovalResult, bool)
+	Remember(ctx context.Context, key string, result *ApprovalResult)
+}
+
+// NewSessionDecisionMemory stores remembered decisions as ADK session values. They live
+// as long as the agent run, including across interrupts and checkpoint-based resumes.
+func NewSessionDecisionMemory() DecisionMemory {
+	return sessionDecisionMemory{}
+}
+
+type sessionDecisionMemory struct{}
+
+func (sessionDecisionMemory) Recall(ctx context.Context, key string) (*ApprovalResult, bool) {
+	v, ok := adk.GetSessionValue(ctx, key)
+	if !ok {
+		return nil, false
+	}
+	r, ok := v.(*ApprovalResult)
+	return r, ok && r != nil
+}
+
+func (sessionDecisionMemory) Remember(ctx context.Context, key string, result *ApprovalResult) {
+	adk.AddSessionValue(ctx, key, result)
+}
+
+// ApprovalGateConfig configures an ApprovalGate.
+type ApprovalGateConfig struct {
+	// Policy decides allow/deny/ask per call. Required.
+	Policy *ApprovalPolicy
+
+	// Memory stores decisions resumed with ApprovalResult.RememberForSession.
+	// Defaults to NewSessionDecisionMemory.
+	Memory DecisionMemory
+
+	// Audit receives every decision. Optional.
+	Audit AuditLogger
+}
+
+// ApprovalGate applies an ApprovalPolicy in front of tool calls. It can be installed as a
+// compose.ToolMiddleware via Middleware, or around individual tools via WrapInvokable
+// and WrapStreamable.
+//
+// When the policy asks, the gate interrupts with the same *ApprovalInfo payload as
+// InvokableApprovableTool and expects an *ApprovalResult on resume, so existing
+// resume code keeps working. Do not gate a tool that raises its own interrupts
+// (such as InvokableApprovableTool) since both would share the tool's interrupt state.
+type ApprovalGate struct {
+	policy *ApprovalPolicy
+	memory DecisionMemory
+	audit  AuditLogger
+}
+
+// approvalGateState is saved with the interrupt so a resume runs the exact
+// arguments the human approved, even if the model re-issues the call differently.
+type approvalGateState struct {
+	ToolName  string
+	Arguments string
+	Rule      string
+}
+
+func init() {
+	schema.RegisterName[*approvalGateState]("_eino_examples_approval_gate_state")
+	schema.RegisterName[*ApprovalResult]("_eino_examples_approval_result")
+}
+
+// NewApprovalGate validates the policy and creates a gate.
+func NewApprovalGate(config *ApprovalGateConfig) (*ApprovalGate, error) {
+	if config == nil || config.Policy == nil {
+		return nil, fmt.Errorf("approval gate policy is required")
+	}
+	if err := config.Policy.Validate(); err != nil {
+		return nil, err
+	}
+	memory := config.Memory
+	if memory == nil {
+		memory = NewSessionDecisionMemory()
+	}
+	return &ApprovalGate{
+		policy: config.Policy,
+		memory: memory,
+		audit:  config.Audit,
+	}, nil
+}
+
+// Middleware returns the gate as a compose.ToolMiddleware for ToolsNodeConfig.ToolCallMiddlewares.
+func (g *ApprovalGate) Middleware() compose.ToolMiddleware {
+	return compose.ToolMiddleware{Invokable: g.Invokable, Streamable: g.Streamable}
+}
+
+// Invokable gates a non-stream tool endpoint.
+func (g *ApprovalGate) Invokable(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+	return func(ctx context.Context, in *compose.ToolInput) (*compose.ToolOutput, error) {
+		args, refusal, err := g.check(ctx, in.Name, in.CallID, in.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		if refusal != "" {
+			return &compose.ToolOutput{Result: refusal}, nil
+		}
+		in.Arguments = args
+		return next(ctx, in)
+	}
+}
+
+// Streamable gates a stream tool endpoint. The decision is taken before the stream starts.
+func (g *ApprovalGate) Streamable(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
+	return func(ctx context.Context, in *compose.ToolInput) (*compose.StreamToolOutput, error) {
+		args, refusal, err := g.check(ctx, in.Name, in.CallID, in.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		if refusal != "" {
+			return &compose.StreamToolOutput{Result: schema.StreamReaderFromArray([]string{refusal})}, nil
+		}
+		in.Arguments = args
+		return next(ctx, in)
+	}
+}
+
+// WrapInvokable gates a single invokable tool.
+func (g *ApprovalGate) WrapInvokable(t tool.InvokableTool) tool.InvokableTool {
+	return &gatedInvokableTool{InvokableTool: t, gate: g}
+}
+
+// WrapStreamable gates a single streamable tool.
+func (g *ApprovalGate) WrapStreamable(t tool.StreamableTool) tool.StreamableTool {
+	return &gatedStreamableTool{StreamableTool: t, gate: g}
+}
+
+type gatedInvokableTool struct {
+	tool.InvokableTool
+	gate *ApprovalGate
+}
+
+func (t *gatedInvokableTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	info, err := t.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	args, refusal, err := t.gate.check(ctx, info.Name, compose.GetToolCallID(ctx), argumentsInJSON)
+	if err != nil {
+		return "", err
+	}
+	if refusal != "" {
+		return refusal, nil
+	}
+	return t.InvokableTool.InvokableRun(ctx, args, opts...)
+}
+
+type gatedStreamableTool struct {
+	tool.StreamableTool
+	gate *ApprovalGate
+}
+
+func (t *gatedStreamableTool) StreamableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (*schema.StreamReader[string], error) {
+	info, err := t.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args, refusal, err := t.gate.check(ctx, info.Name, compose.GetToolCallID(ctx), argumentsInJSON)
+	if err != nil {
+		return nil, err
+	}
+	if refusal != "" {
+		return schema.StreamReaderFromArray([]string{refusal}), nil
+	}
+	return t.StreamableTool.StreamableRun(ctx, args, opts...)
+}
+
+// check decides one tool call. It returns the arguments to run the tool with, or a
+// non-empty refusal message to hand back to the model instead of running the tool.
+// An interrupt is returned as err.
+func (g *ApprovalGate) check(ctx context.Context, toolName, callID, argumentsInJSON string) (args, refusal string, err error) {
+	wasInterrupted, hasState, state := tool.GetInterruptState[*approvalGateState](ctx)
+	if wasInterrupted && hasState {
+		return g.resume(ctx, callID, state)
+	}
+
+	action, rule, err := g.policy.Evaluate(toolName, argumentsInJSON)
+	if err != nil {
+		return "", "", err
+	}
+	ruleName := ""
+	if rule != nil {
+		ruleName = rule.Name
+	}
+	record := &ApprovalAuditRecord{
+		ToolName:  toolName,
+		CallID:    callID,
+		Arguments: argumentsInJSON,
+		Rule:      ruleName,
+		Action:    action,
+		Source:    DecisionSourcePolicy,
+	}
+
+	switch action {
+	case ApprovalActionAllow:
+		record.Approved = true
+		g.log(ctx, record)
+		return argumentsInJSON, "", nil
+	case ApprovalActionDeny:
+		reason := "denied by policy"
+		if rule != nil && rule.Reason != "" {
+			reason = rule.Reason
+		}
+		record.Reason = reason
+		g.log(ctx, record)
+		return "", fmt.Sprintf("tool '%s' disapproved, reason: %s", toolName, reason), nil
+	}
+
+	if remembered, ok := g.memory.Recall(ctx, rememberKey(toolName, ruleName)); ok {
+		record.Source = DecisionSourceRemembered
+		return g.apply(ctx, record, remembered, argumentsInJSON)
+	}
+
+	g.log(ctx, record)
+	return "", "", tool.StatefulInterrupt(ctx, &ApprovalInfo{
+		ToolName:        toolName,
+		ArgumentsInJSON: argumentsInJSON,
+		Rememberable:    true,
+	}, &approvalGateState{
+		ToolName:  toolName,
+		Arguments: argumentsInJSON,
+		Rule:      ruleName,
+	})
+}
+
+func (g *ApprovalGate) resume(ctx context.Context, callID string, state *approvalGateState) (string, string, error) {
+	isResumeTarget, hasData, data := tool.GetResumeContext[*ApprovalResult](ctx)
+	if !isResumeTarget {
+		return "", "", tool.StatefulInterrupt(ctx, &ApprovalInfo{
+			ToolName:        state.ToolName,
+			ArgumentsInJSON: state.Arguments,
+			Rememberable:    true,
+		}, state)
+	}
+	if !hasData || data == nil {
+		return "", "", fmt.Errorf("tool '%s' resumed with no approval result", state.ToolName)
+	}
+
+	if data.RememberForSession {
+		g.memory.Remember(ctx, rememberKey(state.ToolName, state.Rule), data)
+	}
+
+	return g.apply(ctx, &ApprovalAuditRecord{
+		ToolName:  state.ToolName,
+		CallID:    callID,
+		Arguments: state.Arguments,
+		Rule:      state.Rule,
+		Action:    ApprovalActionAsk,
+		Source:    DecisionSourceHuman,
+	}, data, state.Arguments)
+}
+
+func (g *ApprovalGate) apply(ctx context.Context, record *ApprovalAuditRecord, result *ApprovalResult,
+	argumentsInJSON string) (string, string, error) {
+	record.Approved = result.Approved
+	if result.DisapproveReason != nil {
+		record.Reason = *result.DisapproveReason
+	}
+	g.log(ctx, record)
+
+	if result.Approved {
+		return argumentsInJSON, "", nil
+	}
+	if result.DisapproveReason != nil {
+		return "", fmt.Sprintf("tool '%s' disapproved, reason: %s", record.ToolName, *result.DisapproveReason), nil
+	}
+	return "", fmt.Sprintf("tool '%s' disapproved", record.ToolName), nil
+}
+
+func (g *ApprovalGate) log(ctx context.Context, record *ApprovalAuditRecord) {
+	if g.audit == nil {
+		return
+	}
+	record.Time = time.Now()
+	g.audit.LogDecision(ctx, record)
+}
+
+func rememberKey(toolName, rule string) string {
+	return "_approval_decision:" + rule + ":" + toolName
+}
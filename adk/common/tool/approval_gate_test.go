@@ -0,0 +1,266 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/adk/common/store"
+)
+
+// scriptedModel makes the tool calls of calls one per reply, then answers with the last tool result.
+type scriptedModel struct {
+	calls []schema.ToolCall
+}
+
+func (m *scriptedModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	var results []*schema.Message
+	for _, msg := range input {
+		if msg.Role == schema.Tool {
+			results = append(results, msg)
+		}
+	}
+	if len(results) < len(m.calls) {
+		return schema.AssistantMessage("", []schema.ToolCall{m.calls[len(results)]}), nil
+	}
+	return schema.AssistantMessage(results[len(results)-1].Content, nil), nil
+}
+
+func (m *scriptedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *scriptedModel) WithTools([]*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+func transferCall(id, arguments string) schema.ToolCall {
+	return schema.ToolCall{ID: id, Type: "function", Function: schema.FunctionCall{Name: "transfer", Arguments: arguments}}
+}
+
+// gatePath installs a gate in front of a tool, as a ToolsNode middleware or by wrapping the tool.
+type gatePath struct {
+	name    string
+	install func(g *ApprovalGate, t *fakeTool) compose.ToolsNodeConfig
+}
+
+var gatePaths = []gatePath{
+	{name: "middleware", install: func(g *ApprovalGate, t *fakeTool) compose.ToolsNodeConfig {
+		return compose.ToolsNodeConfig{Tools: []tool.BaseTool{t}, ToolCallMiddlewares: []compose.ToolMiddleware{g.Middleware()}}
+	}},
+	{name: "wrap", install: func(g *ApprovalGate, t *fakeTool) compose.ToolsNodeConfig {
+		return compose.ToolsNodeConfig{Tools: []tool.BaseTool{g.WrapInvokable(t)}}
+	}},
+}
+
+// gateRun is an agent making scripted calls to a gated fake "transfer" tool.
+type gateRun struct {
+	t      *testing.T
+	fake   *fakeTool
+	runner *adk.Runner
+	audit  []ApprovalAuditRecord
+}
+
+func newGateRun(t *testing.T, path gatePath, policy *ApprovalPolicy, calls ...schema.ToolCall) *gateRun {
+	r := &gateRun{t: t, fake: &fakeTool{name: "transfer"}}
+	gate, err := NewApprovalGate(&ApprovalGateConfig{
+		Policy: policy,
+		Audit: AuditFunc(func(_ context.Context, record *ApprovalAuditRecord) {
+			if record.Time.IsZero() {
+				t.Errorf("audit record without time: %+v", record)
+			}
+			rec := *record
+			rec.Time = time.Time{}
+			r.audit = append(r.audit, rec)
+		}),
+	})
+	if err != nil {
+		t.Fatalf("new gate: %v", err)
+	}
+	a, err := adk.NewChatModelAgent(context.Background(), &adk.ChatModelAgentConfig{
+		Name:        "payer",
+		Description: "makes transfers",
+		Model:       &scriptedModel{calls: calls},
+		ToolsConfig: adk.ToolsConfig{ToolsNodeConfig: path.install(gate, r.fake)},
+	})
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	r.runner = adk.NewRunner(context.Background(), adk.RunnerConfig{Agent: a, CheckPointStore: store.NewInMemoryStore()})
+	return r
+}
+
+func (r *gateRun) query() *adk.AgentEvent {
+	return r.last(r.runner.Query(context.Background(), "pay", adk.WithCheckPointID("cp")))
+}
+
+func (r *gateRun) resume(id string, result *ApprovalResult) *adk.AgentEvent {
+	iter, err := r.runner.ResumeWithParams(context.Background(), "cp", &adk.ResumeParams{Targets: map[string]any{id: result}})
+	if err != nil {
+		r.t.Fatalf("resume: %v", err)
+	}
+	return r.last(iter)
+}
+
+func (r *gateRun) last(iter *adk.AsyncIterator[*adk.AgentEvent]) *adk.AgentEvent {
+	var last *adk.AgentEvent
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			return last
+		}
+		if event.Err != nil {
+			r.t.Fatalf("run: %v", event.Err)
+		}
+		last = event
+	}
+}
+
+// pendingApproval returns the interrupt of an event, which must be a single approval.
+func (r *gateRun) pendingApproval(event *adk.AgentEvent) (string, *ApprovalInfo) {
+	if event == nil || event.Action == nil || event.Action.Interrupted == nil {
+		r.t.Fatalf("expected an interrupt, got %+v", event)
+	}
+	var roots []*adk.InterruptCtx
+	for _, ic := range event.Action.Interrupted.InterruptContexts {
+		if ic.IsRootCause {
+			roots = append(roots, ic)
+		}
+	}
+	if len(roots) != 1 {
+		r.t.Fatalf("expected one interrupt, got %d", len(roots))
+	}
+	info, ok := roots[0].Info.(*ApprovalInfo)
+	if !ok {
+		r.t.Fatalf("unexpected interrupt info %#v", roots[0].Info)
+	}
+	return roots[0].ID, info
+}
+
+func (r *gateRun) answer(event *adk.AgentEvent, want string) {
+	if event == nil || event.Action != nil || event.Output == nil || event.Output.MessageOutput.Message.Content != want {
+		r.t.Fatalf("expected the answer %q, got %+v", want, event)
+	}
+}
+
+func (r *gateRun) checkAudit(want ...ApprovalAuditRecord) {
+	if !reflect.DeepEqual(r.audit, want) {
+		got, _ := json.Marshal(r.audit)
+		exp, _ := json.Marshal(want)
+		r.t.Fatalf("audit records:\n%s\nwant\n%s", got, exp)
+	}
+}
+
+func TestApprovalGate_AskInterruptResume(t *testing.T) {
+	for _, path := range gatePaths {
+		t.Run(path.name, func(t *testing.T) {
+			r := newGateRun(t, path, &ApprovalPolicy{}, transferCall("call-1", `{"amount":10}`))
+
+			id, info := r.pendingApproval(r.query())
+			if info.ToolName != "transfer" || info.ArgumentsInJSON != `{"amount":10}` || !info.Rememberable {
+				t.Fatalf("unexpected approval info %+v", info)
+			}
+			if r.fake.calls != 0 {
+				t.Fatalf("the tool ran before the approval")
+			}
+
+			r.answer(r.resume(id, &ApprovalResult{Approved: true}), `ran with {"amount":10}`)
+			if r.fake.calls != 1 {
+				t.Fatalf("tool ran %d time(s), want 1", r.fake.calls)
+			}
+			r.checkAudit(
+				ApprovalAuditRecord{ToolName: "transfer", CallID: "call-1", Arguments: `{"amount":10}`,
+					Action: ApprovalActionAsk, Source: DecisionSourcePolicy},
+				ApprovalAuditRecord{ToolName: "transfer", CallID: "call-1", Arguments: `{"amount":10}`,
+					Action: ApprovalActionAsk, Source: DecisionSourceHuman, Approved: true},
+			)
+		})
+	}
+}
+
+func TestApprovalGate_Deny(t *testing.T) {
+	policy := &ApprovalPolicy{Rules: []ApprovalRule{{Name: "large", Action: ApprovalActionDeny, Reason: "over the limit",
+		When: []ArgCondition{{Path: "amount", Op: OpGt, Value: 1000}}}}}
+	for _, path := range gatePaths {
+		t.Run(path.name, func(t *testing.T) {
+			r := newGateRun(t, path, policy, transferCall("call-1", `{"amount":5000}`))
+
+			r.answer(r.query(), "tool 'transfer' disapproved, reason: over the limit")
+			if r.fake.calls != 0 {
+				t.Fatalf("a denied call must not run the tool")
+			}
+			r.checkAudit(ApprovalAuditRecord{ToolName: "transfer", CallID: "call-1", Arguments: `{"amount":5000}`,
+				Rule: "large", Action: ApprovalActionDeny, Source: DecisionSourcePolicy, Reason: "over the limit"})
+		})
+	}
+}
+
+func TestApprovalGate_RememberForSession(t *testing.T) {
+	policy := &ApprovalPolicy{Rules: []ApprovalRule{{Name: "transfers", Tools: []string{"transfer"}, Action: ApprovalActionAsk}}}
+	for _, path := range gatePaths {
+		t.Run(path.name, func(t *testing.T) {
+			r := newGateRun(t, path, policy,
+				transferCall("call-1", `{"amount":10}`), transferCall("call-2", `{"amount":20}`))
+
+			id, _ := r.pendingApproval(r.query())
+			// the second call is decided by the remembered approval, without interrupting
+			r.answer(r.resume(id, &ApprovalResult{Approved: true, RememberForSession: true}), `ran with {"amount":20}`)
+			if r.fake.calls != 2 {
+				t.Fatalf("tool ran %d time(s), want 2", r.fake.calls)
+			}
+			r.checkAudit(
+				ApprovalAuditRecord{ToolName: "transfer", CallID: "call-1", Arguments: `{"amount":10}`,
+					Rule: "transfers", Action: ApprovalActionAsk, Source: DecisionSourcePolicy},
+				ApprovalAuditRecord{ToolName: "transfer", CallID: "call-1", Arguments: `{"amount":10}`,
+					Rule: "transfers", Action: ApprovalActionAsk, Source: DecisionSourceHuman, Approved: true},
+				ApprovalAuditRecord{ToolName: "transfer", CallID: "call-2", Arguments: `{"amount":20}`,
+					Rule: "transfers", Action: ApprovalActionAsk, Source: DecisionSourceRemembered, Approved: true},
+			)
+		})
+	}
+}
+
+func TestJSONLAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLAuditLogger(&buf)
+	logger.LogDecision(context.Background(), &ApprovalAuditRecord{ToolName: "a", Action: ApprovalActionAllow, Source: DecisionSourcePolicy, Approved: true})
+	logger.LogDecision(context.Background(), &ApprovalAuditRecord{ToolName: "b", Action: ApprovalActionDeny, Source: DecisionSourcePolicy})
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per decision, got %q", buf.String())
+	}
+	var record ApprovalAuditRecord
+	if err := json.Unmarshal(lines[1], &record); err != nil || record.ToolName != "b" || record.Action != ApprovalActionDeny {
+		t.Fatalf("unexpected record %q: %v", lines[1], err)
+	}
+}
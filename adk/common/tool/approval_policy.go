@@ -0,0 +1,449 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ApprovalAction is what an ApprovalPolicy decides for a single tool call.
+type ApprovalAction string
+
+const (
+	// ApprovalActionAllow runs the tool without asking.
+	ApprovalActionAllow ApprovalAction = "allow"
+	// ApprovalActionDeny refuses the call and reports the reason back to the model.
+	ApprovalActionDeny ApprovalAction = "deny"
+	// ApprovalActionAsk interrupts the run and waits for an ApprovalResult.
+	ApprovalActionAsk ApprovalAction = "ask"
+)
+
+// ConditionOp is the comparison applied by an ArgCondition.
+type ConditionOp string
+
+const (
+	OpEq       ConditionOp = "eq"
+	OpNe       ConditionOp = "ne"
+	OpGt       ConditionOp = "gt"
+	OpGte      ConditionOp = "gte"
+	OpLt       ConditionOp = "lt"
+	OpLte      ConditionOp = "lte"
+	OpIn       ConditionOp = "in"       // Value is a list, argument equals one of its items
+	OpNotIn    ConditionOp = "not_in"   // Value is a list, argument equals none of its items
+	OpContains ConditionOp = "contains" // substring for strings, membership for arrays
+	OpRegex    ConditionOp = "regex"    // Value is a regular expression matched against a string argument
+	OpExists   ConditionOp = "exists"   // Value is a bool, true if the path must be present
+)
+
+// ArgCondition is a predicate over the JSON arguments of a tool call.
+//
+// Path uses a small JSON-path subset: "$.amount", "payee.country", "items[0].price"
+// and "items[*].price". When the path yields several values (via [*]) the condition
+// holds if any of them satisfies it.
+type ArgCondition struct {
+	Path  string      `json:"path"`
+	Op    ConditionOp `json:"op"`
+	Value any         `json:"value,omitempty"`
+}
+
+// ApprovalRule maps matching tool calls to an ApprovalAction.
+type ApprovalRule struct {
+	// Name identifies the rule in audit records and remembered decisions.
+	Name string `json:"name"`
+
+	// Tools are tool name patterns in path.Match syntax, e.g. "transfer_*". Empty matches every tool.
+	Tools []string `json:"tools,omitempty"`
+
+	// When lists conditions that must all hold for the rule to match. Empty always matches.
+	When []ArgCondition `json:"when,omitempty"`
+
+	Action ApprovalAction `json:"action"`
+
+	// Reason is reported to the model when Action is ApprovalActionDeny.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ApprovalPolicy evaluates rules in order, the first matching rule wins.
+// Its regular expressions are compiled once, by Validate or the first Evaluate,
+// so the rules must not change once the policy is in use.
+type ApprovalPolicy struct {
+	Rules []ApprovalRule `json:"rules"`
+
+	// Default applies when no rule matches. Defaults to ApprovalActionAsk,
+	// which keeps the behavior of InvokableApprovableTool.
+	Default ApprovalAction `json:"default,omitempty"`
+
+	compileOnce sync.Once
+	regexps     map[string]*regexp.Regexp // OpRegex values by pattern
+	compileErr  error
+}
+
+// Validate checks actions, operators, tool patterns and regular expressions up front,
+// so that a malformed policy fails at construction rather than on the first tool call.
+func (p *ApprovalPolicy) Validate() error {
+	if err := validateAction(p.Default, true); err != nil {
+		return fmt.Errorf("policy default: %w", err)
+	}
+	if err := p.compile(); err != nil {
+		return err
+	}
+	for i, r := range p.Rules {
+		if err := validateAction(r.Action, false); err != nil {
+			return fmt.Errorf("rule %d (%s): %w", i, r.Name, err)
+		}
+		for _, pattern := range r.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d (%s): invalid tool pattern %q: %w", i, r.Name, pattern, err)
+			}
+		}
+		for _, c := range r.When {
+			if _, err := parseArgPath(c.Path); err != nil {
+				return fmt.Errorf("rule %d (%s): %w", i, r.Name, err)
+			}
+			switch c.Op {
+			case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn, OpNotIn, OpContains, OpExists, OpRegex:
+			default:
+				return fmt.Errorf("rule %d (%s): unknown operator %q", i, r.Name, c.Op)
+			}
+		}
+	}
+	return nil
+}
+
+// compile compiles the regular expressions of the rules, the first time only.
+func (p *ApprovalPolicy) compile() error {
+	p.compileOnce.Do(func() {
+		p.regexps = make(map[string]*regexp.Regexp)
+		for i, r := range p.Rules {
+			for _, c := range r.When {
+				if c.Op != OpRegex {
+					continue
+				}
+				s, ok := c.Value.(string)
+				if !ok {
+					p.compileErr = fmt.Errorf("rule %d (%s): regex value must be a string", i, r.Name)
+					return
+				}
+				re, err := regexp.Compile(s)
+				if err != nil {
+					p.compileErr = fmt.Errorf("rule %d (%s): %w", i, r.Name, err)
+					return
+				}
+				p.regexps[s] = re
+			}
+		}
+	})
+	return p.compileErr
+}
+
+// Evaluate returns the action for a tool call and the rule that produced it.
+// The returned rule is nil when the policy default was applied.
+func (p *ApprovalPolicy) Evaluate(toolName, argumentsInJSON string) (ApprovalAction, *ApprovalRule, error) {
+	if err := p.compile(); err != nil {
+		return "", nil, err
+	}
+
+	var args any
+	if strings.TrimSpace(argumentsInJSON) != "" {
+		dec := json.NewDecoder(strings.NewReader(argumentsInJSON))
+		dec.UseNumber()
+		if err := dec.Decode(&args); err != nil {
+			return "", nil, fmt.Errorf("tool '%s' arguments are not valid JSON: %w", toolName, err)
+		}
+	}
+
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !matchToolName(r.Tools, toolName) {
+			continue
+		}
+		matched := true
+		for _, c := range r.When {
+			ok, err := c.match(args, p.regexps)
+			if err != nil {
+				return "", nil, fmt.Errorf("rule '%s': %w", r.Name, err)
+			}
+			if !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.Action, r, nil
+		}
+	}
+
+	if p.Default == "" {
+		return ApprovalActionAsk, nil, nil
+	}
+	return p.Default, nil, nil
+}
+
+func validateAction(a ApprovalAction, allowEmpty bool) error {
+	switch a {
+	case ApprovalActionAllow, ApprovalActionDeny, ApprovalActionAsk:
+		return nil
+	case "":
+		if allowEmpty {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action %q", a)
+}
+
+func matchToolName(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c ArgCondition) match(args any, regexps map[string]*regexp.Regexp) (bool, error) {
+	segs, err := parseArgPath(c.Path)
+	if err != nil {
+		return false, err
+	}
+	values := lookupArgPath(args, segs)
+
+	if c.Op == OpExists {
+		want, _ := c.Value.(bool)
+		if c.Value == nil {
+			want = true
+		}
+		return (len(values) > 0) == want, nil
+	}
+
+	// negative operators must hold for every value, otherwise a single differing
+	// element would satisfy them; every other operator holds if any value matches.
+	switch c.Op {
+	case OpNe:
+		for _, v := range values {
+			if equalJSON(v, c.Value) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case OpNotIn:
+		for _, v := range values {
+			in, err := ArgCondition{Path: c.Path, Op: OpIn, Value: c.Value}.compare(v, regexps)
+			if err != nil || in {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	for _, v := range values {
+		ok, err := c.compare(v, regexps)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c ArgCondition) compare(v any, regexps map[string]*regexp.Regexp) (bool, error) {
+	switch c.Op {
+	case OpEq:
+		return equalJSON(v, c.Value), nil
+	case OpGt, OpGte, OpLt, OpLte:
+		a, ok1 := toFloat(v)
+		b, ok2 := toFloat(c.Value)
+		if !ok1 || !ok2 {
+			return false, nil
+		}
+		switch c.Op {
+		case OpGt:
+			return a > b, nil
+		case OpGte:
+			return a >= b, nil
+		case OpLt:
+			return a < b, nil
+		default:
+			return a <= b, nil
+		}
+	case OpIn:
+		rv := reflect.ValueOf(c.Value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return false, fmt.Errorf("'in' value for path %s must be a list", c.Path)
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if equalJSON(v, rv.Index(i).Interface()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case OpContains:
+		switch tv := v.(type) {
+		case string:
+			s, ok := c.Value.(string)
+			return ok && strings.Contains(tv, s), nil
+		case []any:
+			for _, item := range tv {
+				if equalJSON(item, c.Value) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case OpRegex:
+		s, ok := v.(string)
+		if !ok {
+			return false, nil
+		}
+		pattern, _ := c.Value.(string)
+		return regexps[pattern].MatchString(s), nil
+	default:
+		return false, fmt.Errorf("unknown operator %q", c.Op)
+	}
+}
+
+// argPathSeg is either a map key or an array index; index -1 stands for [*].
+type argPathSeg struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func parseArgPath(p string) ([]argPathSeg, error) {
+	p = strings.TrimPrefix(strings.TrimPrefix(p, "$"), ".")
+	if p == "" {
+		return nil, nil
+	}
+
+	var segs []argPathSeg
+	for _, part := range strings.Split(p, ".") {
+		name := part
+		var indices []string
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name = part[:i]
+			rest := part[i:]
+			for rest != "" {
+				if rest[0] != '[' {
+					return nil, fmt.Errorf("invalid argument path %q", p)
+				}
+				end := strings.IndexByte(rest, ']')
+				if end < 0 {
+					return nil, fmt.Errorf("invalid argument path %q", p)
+				}
+				indices = append(indices, rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		if name != "" {
+			segs = append(segs, argPathSeg{key: name})
+		} else if len(indices) == 0 {
+			return nil, fmt.Errorf("invalid argument path %q", p)
+		}
+		for _, idx := range indices {
+			if idx == "*" {
+				segs = append(segs, argPathSeg{index: -1, isIndex: true})
+				continue
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in argument path %q", idx, p)
+			}
+			segs = append(segs, argPathSeg{index: n, isIndex: true})
+		}
+	}
+	return segs, nil
+}
+
+func lookupArgPath(v any, segs []argPathSeg) []any {
+	if len(segs) == 0 {
+		return []any{v}
+	}
+	seg := segs[0]
+	if !seg.isIndex {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		child, ok := m[seg.key]
+		if !ok {
+			return nil
+		}
+		return lookupArgPath(child, segs[1:])
+	}
+
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	if seg.index >= 0 {
+		if seg.index >= len(arr) {
+			return nil
+		}
+		return lookupArgPath(arr[seg.index], segs[1:])
+	}
+	var out []any
+	for _, item := range arr {
+		out = append(out, lookupArgPath(item, segs[1:])...)
+	}
+	return out
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equalJSON compares a decoded argument with a policy value, treating all numbers alike.
+func equalJSON(a, b any) bool {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA || okB {
+		return okA && okB && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import "testing"
+
+func TestApprovalPolicy_Evaluate(t *testing.T) {
+	policy := &ApprovalPolicy{
+		Rules: []ApprovalRule{
+			{Name: "no-offshore", Tools: []string{"transfer_*"}, Action: ApprovalActionDeny, Reason: "offshore",
+				When: []ArgCondition{{Path: "$.payee.country", Op: OpIn, Value: []any{"KY", "VG"}}}},
+			{Name: "large-transfer", Tools: []string{"transfer_*"}, Action: ApprovalActionAsk,
+				When: []ArgCondition{{Path: "$.amount", Op: OpGt, Value: 1000}}},
+			{Name: "small-transfer", Tools: []string{"transfer_*"}, Action: ApprovalActionAllow},
+			{Name: "risky-items", Action: ApprovalActionAsk,
+				When: []ArgCondition{{Path: "items[*].sku", Op: OpRegex, Value: "^X-"}}},
+			{Name: "currency", Tools: []string{"refund"}, Action: ApprovalActionDeny,
+				When: []ArgCondition{{Path: "currency", Op: OpNotIn, Value: []any{"USD", "CNY"}}}},
+		},
+		Default: ApprovalActionAllow,
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	cases := []struct {
+		tool, args string
+		action     ApprovalAction
+		rule       string
+	}{
+		{"transfer_money", `{"amount": 5000, "payee": {"country": "US"}}`, ApprovalActionAsk, "large-transfer"},
+		{"transfer_money", `{"amount": 20, "payee": {"country": "US"}}`, ApprovalActionAllow, "small-transfer"},
+		{"transfer_money", `{"amount": 20, "payee": {"country": "KY"}}`, ApprovalActionDeny, "no-offshore"},
+		{"order", `{"items": [{"sku": "A-1"}, {"sku": "X-9"}]}`, ApprovalActionAsk, "risky-items"},
+		{"order", `{"items": [{"sku": "A-1"}]}`, ApprovalActionAllow, ""},
+		{"refund", `{"currency": "EUR"}`, ApprovalActionDeny, "currency"},
+		{"refund", `{"currency": "USD"}`, ApprovalActionAllow, ""},
+	}
+	for _, c := range cases {
+		action, rule, err := policy.Evaluate(c.tool, c.args)
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.tool, c.args, err)
+		}
+		name := ""
+		if rule != nil {
+			name = rule.Name
+		}
+		if action != c.action || name != c.rule {
+			t.Fatalf("%s %s: got %s/%q, want %s/%q", c.tool, c.args, action, name, c.action, c.rule)
+		}
+	}
+}
+
+func TestApprovalPolicy_Validate(t *testing.T) {
+	bad := []*ApprovalPolicy{
+		{Rules: []ApprovalRule{{Name: "a", Action: "maybe"}}},
+		{Rules: []ApprovalRule{{Name: "b", Action: ApprovalActionAsk, When: []ArgCondition{{Path: "a[", Op: OpEq}}}}},
+		{Rules: []ApprovalRule{{Name: "c", Action: ApprovalActionAsk, When: []ArgCondition{{Path: "a", Op: OpRegex, Value: "("}}}}},
+		{Rules: []ApprovalRule{{Name: "d", Action: ApprovalActionAsk, When: []ArgCondition{{Path: "a", Op: "like"}}}}},
+	}
+	for i, p := range bad {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("policy %d should be invalid", i)
+		}
+	}
+}
+
+func TestApprovalPolicy_DefaultIsAsk(t *testing.T) {
+	action, rule, err := (&ApprovalPolicy{}).Evaluate("any", `{}`)
+	if err != nil || action != ApprovalActionAsk || rule != nil {
+		t.Fatalf("got %s %v %v", action, rule, err)
+	}
+}
@@ -19,6 +19,7 @@ package tool
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
@@ -27,25 +28,57 @@ import (
 type ApprovalInfo struct {
 	ToolName        string
 	ArgumentsInJSON string
+
+	// Deadline is set when the tool has a TimeoutPolicy; OnTimeout is applied after it.
+	Deadline  time.Time
+	OnTimeout TimeoutAction
+
+	// Rememberable is set when the interrupt comes from an ApprovalGate, the only one to
+	// honor ApprovalResult.RememberForSession.
+	Rememberable bool
 }
 
 type ApprovalResult struct {
 	Approved         bool
 	DisapproveReason *string
+
+	// RememberForSession asks an ApprovalGate to apply this decision to later calls
+	// of the same tool under the same rule, for the rest of the session.
+	// InvokableApprovableTool ignores it.
+	RememberForSession bool
 }
 
 func (ai *ApprovalInfo) String() string {
-	return fmt.Sprintf("tool '%s' interrupted with arguments '%s', waiting for your approval, "+
+	return fmt.Sprintf("tool '%s' interrupted with arguments '%s', waiting for your approval%s, "+
 		"please answer with Y/N",
-		ai.ToolName, ai.ArgumentsInJSON)
+		ai.ToolName, ai.ArgumentsInJSON, formatDeadline(ai.Deadline, ai.OnTimeout))
+}
+
+// ExpiresAt returns the deadline of the approval, zero if it never expires.
+func (ai *ApprovalInfo) ExpiresAt() time.Time {
+	return ai.Deadline
+}
+
+// approvalState is saved with the interrupt of InvokableApprovableTool and StreamableApprovableTool.
+// Checkpoints written before it existed hold the bare arguments string instead.
+type approvalState struct {
+	Arguments string
+	Deadline  time.Time
+	Timeout   *TimeoutPolicy
 }
 
 func init() {
 	schema.Register[*ApprovalInfo]()
+	schema.RegisterName[*approvalState]("_eino_examples_approval_state")
 }
 
+// InvokableApprovableTool interrupts on every call and waits for an ApprovalResult.
+// Use ApprovalGate to decide per call with an ApprovalPolicy instead.
 type InvokableApprovableTool struct {
 	tool.InvokableTool
+
+	// Timeout optionally resolves the approval to a default decision after a deadline.
+	Timeout *TimeoutPolicy
 }
 
 func (i InvokableApprovableTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
@@ -60,34 +93,118 @@ func (i InvokableApprovableTool) InvokableRun(ctx context.Context, argumentsInJS
 		return "", err
 	}
 
-	wasInterrupted, _, storedArguments := tool.GetInterruptState[string](ctx)
+	args, refusal, err := checkApproval(ctx, toolInfo.Name, argumentsInJSON, i.Timeout)
+	if err != nil {
+		return "", err
+	}
+	if refusal != "" {
+		return refusal, nil
+	}
+
+	return i.InvokableTool.InvokableRun(ctx, args, opts...)
+}
+
+// StreamableApprovableTool is the streaming counterpart of InvokableApprovableTool.
+// It interrupts before the wrapped tool starts streaming, with the same *ApprovalInfo payload,
+// and on approval returns the wrapped tool's own stream.
+type StreamableApprovableTool struct {
+	tool.StreamableTool
+
+	// Timeout optionally resolves the approval to a default decision after a deadline.
+	Timeout *TimeoutPolicy
+}
+
+func (s StreamableApprovableTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return s.StreamableTool.Info(ctx)
+}
+
+func (s StreamableApprovableTool) StreamableRun(ctx context.Context, argumentsInJSON string,
+	opts ...tool.Option) (*schema.StreamReader[string], error) {
+
+	toolInfo, err := s.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args, refusal, err := checkApproval(ctx, toolInfo.Name, argumentsInJSON, s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if refusal != "" {
+		return schema.StreamReaderFromArray([]string{refusal}), nil
+	}
+
+	return s.StreamableTool.StreamableRun(ctx, args, opts...)
+}
+
+// checkApproval interrupts on the first call and interprets the ApprovalResult on resume.
+// It returns the arguments to run the tool with, or a refusal message for the model.
+func checkApproval(ctx context.Context, toolName, argumentsInJSON string, timeout *TimeoutPolicy) (
+	args, refusal string, err error) {
+	wasInterrupted, state := getApprovalState(ctx)
 	if !wasInterrupted {
-		return "", tool.StatefulInterrupt(ctx, &ApprovalInfo{
-			ToolName:        toolInfo.Name,
-			ArgumentsInJSON: argumentsInJSON,
-		}, argumentsInJSON)
+		state = &approvalState{
+			Arguments: argumentsInJSON,
+			Deadline:  timeout.deadline(),
+		}
+		if !state.Deadline.IsZero() {
+			state.Timeout = timeout
+		}
+		return "", "", tool.StatefulInterrupt(ctx, approvalInfo(toolName, state), state)
+	}
+
+	if isExpired(state.Deadline) {
+		switch state.Timeout.action() {
+		case TimeoutApprove:
+			return state.Arguments, "", nil
+		case TimeoutFail:
+			return "", "", state.Timeout.expiredError(toolName, state.Deadline)
+		default:
+			return "", fmt.Sprintf("tool '%s' disapproved, reason: %s", toolName, state.Timeout.reason()), nil
+		}
 	}
 
 	isResumeTarget, hasData, data := tool.GetResumeContext[*ApprovalResult](ctx)
 	if isResumeTarget && hasData {
 		if data.Approved {
-			return i.InvokableTool.InvokableRun(ctx, storedArguments, opts...)
+			return state.Arguments, "", nil
 		}
 
 		if data.DisapproveReason != nil {
-			return fmt.Sprintf("tool '%s' disapproved, reason: %s", toolInfo.Name, *data.DisapproveReason), nil
+			return "", fmt.Sprintf("tool '%s' disapproved, reason: %s", toolName, *data.DisapproveReason), nil
 		}
 
-		return fmt.Sprintf("tool '%s' disapproved", toolInfo.Name), nil
+		return "", fmt.Sprintf("tool '%s' disapproved", toolName), nil
 	}
 
 	isResumeTarget, _, _ = tool.GetResumeContext[any](ctx)
 	if !isResumeTarget {
-		return "", tool.StatefulInterrupt(ctx, &ApprovalInfo{
-			ToolName:        toolInfo.Name,
-			ArgumentsInJSON: storedArguments,
-		}, storedArguments)
+		return "", "", tool.StatefulInterrupt(ctx, approvalInfo(toolName, state), state)
 	}
 
-	return i.InvokableTool.InvokableRun(ctx, storedArguments, opts...)
+	return state.Arguments, "", nil
+}
+
+func getApprovalState(ctx context.Context) (bool, *approvalState) {
+	wasInterrupted, hasState, state := tool.GetInterruptState[*approvalState](ctx)
+	if !wasInterrupted {
+		return false, nil
+	}
+	if hasState && state != nil {
+		return true, state
+	}
+	_, _, storedArguments := tool.GetInterruptState[string](ctx)
+	return true, &approvalState{Arguments: storedArguments}
+}
+
+func approvalInfo(toolName string, state *approvalState) *ApprovalInfo {
+	info := &ApprovalInfo{
+		ToolName:        toolName,
+		ArgumentsInJSON: state.Arguments,
+		Deadline:        state.Deadline,
+	}
+	if !state.Deadline.IsZero() {
+		info.OnTimeout = state.Timeout.action()
+	}
+	return info
 }
@@ -0,0 +1,327 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+)
+
+// ArgumentError is one problem found when validating tool arguments against the tool's parameters.
+type ArgumentError struct {
+	// Path is a JSON Pointer (RFC 6901) to the offending value, "" for the whole document.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ArgumentError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ArgumentChange is one difference between two argument documents, in the spirit of a
+// JSON Patch (RFC 6902) operation.
+type ArgumentChange struct {
+	Op       string `json:"op"` // "add", "remove" or "replace"
+	Path     string `json:"path"`
+	OldValue any    `json:"old_value,omitempty"`
+	Value    any    `json:"value,omitempty"`
+}
+
+// MarshalJSON writes the values of the operation even when they are null: value for "add"
+// and "replace", old_value for "replace" and "remove".
+func (c ArgumentChange) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Op       string          `json:"op"`
+		Path     string          `json:"path"`
+		OldValue json.RawMessage `json:"old_value,omitempty"`
+		Value    json.RawMessage `json:"value,omitempty"`
+	}{Op: c.Op, Path: c.Path}
+
+	var err error
+	if c.Op == "replace" || c.Op == "remove" || c.OldValue != nil {
+		if out.OldValue, err = json.Marshal(c.OldValue); err != nil {
+			return nil, err
+		}
+	}
+	if c.Op == "add" || c.Op == "replace" || c.Value != nil {
+		if out.Value, err = json.Marshal(c.Value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(out)
+}
+
+// ValidateArguments checks argumentsInJSON against the parameters declared in info:
+// types, required fields and enums, recursively through objects and arrays.
+// As in JSON Schema, an object property that is not declared is checked against
+// additionalProperties, and allowed when the schema does not set it. Set it to false
+// to report undeclared, often misspelled, properties.
+// A tool without declared parameters accepts any valid JSON.
+func ValidateArguments(info *schema.ToolInfo, argumentsInJSON string) ([]ArgumentError, error) {
+	v, err := decodeArguments(argumentsInJSON)
+	if err != nil {
+		return []ArgumentError{{Message: "invalid JSON: " + err.Error()}}, nil
+	}
+
+	if info == nil || info.ParamsOneOf == nil {
+		return nil, nil
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		return nil, fmt.Errorf("convert params of tool '%s' to json schema: %w", info.Name, err)
+	}
+
+	var errs []ArgumentError
+	validateValue(js, v, "", &errs)
+	return errs, nil
+}
+
+// DiffArguments lists the changes that turn originalJSON into editedJSON.
+// Object keys are visited in sorted order so the result is deterministic.
+func DiffArguments(originalJSON, editedJSON string) ([]ArgumentChange, error) {
+	a, err := decodeArguments(originalJSON)
+	if err != nil {
+		return nil, fmt.Errorf("original arguments: %w", err)
+	}
+	b, err := decodeArguments(editedJSON)
+	if err != nil {
+		return nil, fmt.Errorf("edited arguments: %w", err)
+	}
+	var changes []ArgumentChange
+	diffValue(a, b, "", &changes)
+	return changes, nil
+}
+
+func decodeArguments(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return v, nil
+}
+
+func validateValue(s *jsonschema.Schema, v any, path string, errs *[]ArgumentError) {
+	if s == nil {
+		return
+	}
+
+	types := s.TypeEnhanced
+	if s.Type != "" {
+		types = []string{s.Type}
+	}
+	if len(types) > 0 {
+		ok := false
+		for _, t := range types {
+			if matchesJSONType(t, v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			*errs = append(*errs, ArgumentError{Path: path,
+				Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeOf(v))})
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		ok := false
+		for _, e := range s.Enum {
+			if equalJSON(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			*errs = append(*errs, ArgumentError{Path: path, Message: fmt.Sprintf("must be one of %s", formatEnum(s.Enum))})
+		}
+	}
+
+	switch tv := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := tv[name]; !ok {
+				*errs = append(*errs, ArgumentError{Path: path + "/" + escapePointer(name), Message: "required field is missing"})
+			}
+		}
+		keys := make([]string, 0, len(tv))
+		for k := range tv {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var ps *jsonschema.Schema
+			if s.Properties != nil {
+				ps, _ = s.Properties.Get(k)
+			}
+			if ps == nil {
+				ps = s.AdditionalProperties
+			}
+			if ps == nil {
+				continue
+			}
+			if isFalseSchema(ps) {
+				*errs = append(*errs, ArgumentError{Path: path + "/" + escapePointer(k), Message: "unknown field"})
+				continue
+			}
+			validateValue(ps, tv[k], path+"/"+escapePointer(k), errs)
+		}
+	case []any:
+		for i, item := range tv {
+			validateValue(s.Items, item, path+"/"+strconv.Itoa(i), errs)
+		}
+	}
+}
+
+func isFalseSchema(s *jsonschema.Schema) bool {
+	return s == jsonschema.FalseSchema || reflect.DeepEqual(*s, *jsonschema.FalseSchema)
+}
+
+func matchesJSONType(t string, v any) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := n.Int64(); err == nil {
+			return true
+		}
+		f, err := n.Float64()
+		return err == nil && f == float64(int64(f))
+	default:
+		return true
+	}
+}
+
+func jsonTypeOf(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func formatEnum(values []any) string {
+	b, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(b)
+}
+
+func diffValue(a, b any, path string, changes *[]ArgumentChange) {
+	am, aIsMap := a.(map[string]any)
+	bm, bIsMap := b.(map[string]any)
+	if aIsMap && bIsMap {
+		keys := make([]string, 0, len(am)+len(bm))
+		for k := range am {
+			keys = append(keys, k)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + escapePointer(k)
+			av, inA := am[k]
+			bv, inB := bm[k]
+			switch {
+			case !inB:
+				*changes = append(*changes, ArgumentChange{Op: "remove", Path: p, OldValue: av})
+			case !inA:
+				*changes = append(*changes, ArgumentChange{Op: "add", Path: p, Value: bv})
+			default:
+				diffValue(av, bv, p, changes)
+			}
+		}
+		return
+	}
+
+	as, aIsArr := a.([]any)
+	bs, bIsArr := b.([]any)
+	if aIsArr && bIsArr {
+		n := len(as)
+		if len(bs) < n {
+			n = len(bs)
+		}
+		for i := 0; i < n; i++ {
+			diffValue(as[i], bs[i], path+"/"+strconv.Itoa(i), changes)
+		}
+		// remove from the tail first so that each path is still valid when applied in order
+		for i := len(as) - 1; i >= n; i-- {
+			*changes = append(*changes, ArgumentChange{Op: "remove", Path: path + "/" + strconv.Itoa(i), OldValue: as[i]})
+		}
+		for i := n; i < len(bs); i++ {
+			*changes = append(*changes, ArgumentChange{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: bs[i]})
+		}
+		return
+	}
+
+	if !equalJSON(a, b) {
+		*changes = append(*changes, ArgumentChange{Op: "replace", Path: path, OldValue: a, Value: b})
+	}
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
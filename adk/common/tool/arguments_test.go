@@ -0,0 +1,126 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+)
+
+func bookTicketInfo() *schema.ToolInfo {
+	return &schema.ToolInfo{
+		Name: "BookTicket",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"location":   {Type: schema.String, Required: true},
+			"seat_class": {Type: schema.String, Enum: []string{"economy", "business"}},
+			"passengers": {Type: schema.Array, ElemInfo: &schema.ParameterInfo{Type: schema.Object,
+				SubParams: map[string]*schema.ParameterInfo{
+					"name": {Type: schema.String, Required: true},
+					"age":  {Type: schema.Integer},
+				}}},
+		}),
+	}
+}
+
+func TestValidateArguments(t *testing.T) {
+	info := bookTicketInfo()
+
+	errs, err := ValidateArguments(info, `{"location":"Beijing","seat_class":"economy","passengers":[{"name":"Martin","age":30}]}`)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("valid arguments rejected: %v %v", errs, err)
+	}
+
+	errs, err = ValidateArguments(info, `{"locaton":"Beijing","seat_class":"first","passengers":[{"age":"30"}]}`)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	want := map[string]bool{
+		"/location":          true, // required, the typo "locaton" is allowed as additionalProperties is not set
+		"/seat_class":        true, // enum
+		"/passengers/0/name": true, // nested required
+		"/passengers/0/age":  true, // type
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for _, e := range errs {
+		if !want[e.Path] {
+			t.Fatalf("unexpected error %v", e)
+		}
+	}
+
+	errs, _ = ValidateArguments(info, `{"location":`)
+	if len(errs) != 1 || errs[0].Path != "" {
+		t.Fatalf("invalid JSON should yield one root error, got %v", errs)
+	}
+}
+
+func TestValidateArguments_AdditionalProperties(t *testing.T) {
+	var js jsonschema.Schema
+	err := json.Unmarshal([]byte(`{"type":"object","properties":{
+		"location":{"type":"string"},
+		"options":{"type":"object","additionalProperties":{"type":"boolean"}}
+	},"additionalProperties":false}`), &js)
+	if err != nil {
+		t.Fatalf("unmarshal schema: %v", err)
+	}
+	info := &schema.ToolInfo{Name: "BookTicket", ParamsOneOf: schema.NewParamsOneOfByJSONSchema(&js)}
+
+	errs, err := ValidateArguments(info, `{"locaton":"Beijing","options":{"window":true,"meal":"vegan"}}`)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(errs) != 2 || errs[0].Path != "/locaton" || errs[1].Path != "/options/meal" {
+		t.Fatalf("expected the unknown field and the mistyped additional property, got %v", errs)
+	}
+}
+
+func TestDiffArguments(t *testing.T) {
+	changes, err := DiffArguments(
+		`{"location":"Beijing","date":"2025-12-01","tags":["a","b","c"],"p":{"name":"Martin"}}`,
+		`{"location":"Shanghai","tags":["a","x"],"p":{"name":"Martin","phone":"123"}}`)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	got, _ := json.Marshal(changes)
+	want := `[{"op":"remove","path":"/date","old_value":"2025-12-01"},` +
+		`{"op":"replace","path":"/location","old_value":"Beijing","value":"Shanghai"},` +
+		`{"op":"add","path":"/p/phone","value":"123"},` +
+		`{"op":"replace","path":"/tags/1","old_value":"b","value":"x"},` +
+		`{"op":"remove","path":"/tags/2","old_value":"c"}]`
+	if string(got) != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	// null values are written, not dropped
+	changes, _ = DiffArguments(`{"a":null,"b":1}`, `{"b":null,"c":null}`)
+	got, _ = json.Marshal(changes)
+	want = `[{"op":"remove","path":"/a","old_value":null},` +
+		`{"op":"replace","path":"/b","old_value":1,"value":null},` +
+		`{"op":"add","path":"/c","value":null}]`
+	if string(got) != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+
+	changes, _ = DiffArguments(`{"amount": 1.0}`, `{"amount": 1}`)
+	if len(changes) != 0 {
+		t.Fatalf("numerically equal values should not differ: %v", changes)
+	}
+}
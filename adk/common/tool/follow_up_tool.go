@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -31,20 +32,33 @@ import (
 type FollowUpInfo struct {
 	Questions  []string
 	UserAnswer string // This field will be populated by the user.
+
+	// Deadline is set when the tool has a TimeoutPolicy; OnTimeout is applied after it.
+	Deadline  time.Time
+	OnTimeout TimeoutAction
 }
 
 func (fi *FollowUpInfo) String() string {
 	var sb strings.Builder
-	sb.WriteString("We need more information. Please answer the following questions:\n")
+	sb.WriteString("We need more information. Please answer the following questions")
+	sb.WriteString(formatDeadline(fi.Deadline, fi.OnTimeout))
+	sb.WriteString(":\n")
 	for i, q := range fi.Questions {
 		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, q))
 	}
 	return sb.String()
 }
 
+// ExpiresAt returns the deadline of the questions, zero if they never expire.
+func (fi *FollowUpInfo) ExpiresAt() time.Time {
+	return fi.Deadline
+}
+
 // FollowUpState is the state saved during the interrupt.
 type FollowUpState struct {
 	Questions []string
+	Deadline  time.Time
+	Timeout   *TimeoutPolicy
 }
 
 // FollowUpToolInput defines the input schema for our tool.
@@ -58,20 +72,47 @@ func init() {
 }
 
 func FollowUp(ctx context.Context, input *FollowUpToolInput) (string, error) {
+	return followUp(ctx, input, nil)
+}
+
+// NewFollowUpFunc returns FollowUp with a deadline: questions left unanswered until then
+// are resolved according to timeout.
+func NewFollowUpFunc(timeout *TimeoutPolicy) func(ctx context.Context, input *FollowUpToolInput) (string, error) {
+	return func(ctx context.Context, input *FollowUpToolInput) (string, error) {
+		return followUp(ctx, input, timeout)
+	}
+}
+
+func followUp(ctx context.Context, input *FollowUpToolInput, timeout *TimeoutPolicy) (string, error) {
 	wasInterrupted, _, storedState := tool.GetInterruptState[*FollowUpState](ctx)
 
 	if !wasInterrupted {
-		info := &FollowUpInfo{Questions: input.Questions}
-		state := &FollowUpState{Questions: input.Questions}
+		state := &FollowUpState{Questions: input.Questions, Deadline: timeout.deadline()}
+		if !state.Deadline.IsZero() {
+			state.Timeout = timeout
+		}
+
+		return "", tool.StatefulInterrupt(ctx, followUpInfo(state), state)
+	}
 
-		return "", tool.StatefulInterrupt(ctx, info, state)
+	if isExpired(storedState.Deadline) {
+		switch storedState.Timeout.action() {
+		case TimeoutApprove:
+			if storedState.Timeout.DefaultAnswer != "" {
+				return storedState.Timeout.DefaultAnswer, nil
+			}
+			return "The user did not answer in time, proceed with your best assumptions.", nil
+		case TimeoutFail:
+			return "", storedState.Timeout.expiredError("FollowUpTool", storedState.Deadline)
+		default:
+			return fmt.Sprintf("The user did not answer in time: %s", storedState.Timeout.reason()), nil
+		}
 	}
 
 	isResumeTarget, hasData, resumeData := tool.GetResumeContext[*FollowUpInfo](ctx)
 
 	if !isResumeTarget {
-		info := &FollowUpInfo{Questions: storedState.Questions}
-		return "", tool.StatefulInterrupt(ctx, info, storedState)
+		return "", tool.StatefulInterrupt(ctx, followUpInfo(storedState), storedState)
 	}
 
 	if !hasData || resumeData.UserAnswer == "" {
@@ -81,8 +122,40 @@ func FollowUp(ctx context.Context, input *FollowUpToolInput) (string, error) {
 	return resumeData.UserAnswer, nil
 }
 
+func followUpInfo(state *FollowUpState) *FollowUpInfo {
+	info := &FollowUpInfo{Questions: state.Questions, Deadline: state.Deadline}
+	if !state.Deadline.IsZero() {
+		info.OnTimeout = state.Timeout.action()
+	}
+	return info
+}
+
 func GetFollowUpTool() tool.InvokableTool {
-	t, err := utils.InferTool("FollowUpTool", "Asks the user for more information by providing a list of questions.", FollowUp)
+	return GetFollowUpToolWithTimeout(nil)
+}
+
+// GetFollowUpToolWithTimeout is GetFollowUpTool with a TimeoutPolicy for unanswered questions.
+func GetFollowUpToolWithTimeout(timeout *TimeoutPolicy) tool.InvokableTool {
+	t, err := utils.InferTool("FollowUpTool", "Asks the user for more information by providing a list of questions.",
+		NewFollowUpFunc(timeout))
+	if err != nil {
+		log.Fatal(err)
+	}
+	return t
+}
+
+// FollowUpStream is the streaming counterpart of FollowUp. It raises the same
+// FollowUpInfo interrupt and, once resumed, streams the user's answer.
+func FollowUpStream(ctx context.Context, input *FollowUpToolInput) (*schema.StreamReader[string], error) {
+	answer, err := FollowUp(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]string{answer}), nil
+}
+
+func GetStreamableFollowUpTool() tool.StreamableTool {
+	t, err := utils.InferStreamTool("FollowUpTool", "Asks the user for more information by providing a list of questions.", FollowUpStream)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/adk/common/store"
+)
+
+// interruptible runs fn as the only node of a graph with a checkpoint store, so that the tool
+// it calls can interrupt and be resumed as in a ToolsNode.
+type interruptible[O any] struct {
+	runner compose.Runnable[string, O]
+	// args are the arguments of the last run. A resumed graph does not get its input back,
+	// so fn is called with them again, as a ToolsNode calls a resumed tool with its arguments.
+	args string
+}
+
+func newInterruptible[O any](t *testing.T, fn func(ctx context.Context, argumentsInJSON string) (O, error)) *interruptible[O] {
+	x := &interruptible[O]{}
+	g := compose.NewGraph[string, O]()
+	if err := g.AddLambdaNode("tool", compose.InvokableLambda(func(ctx context.Context, _ string) (O, error) {
+		return fn(ctx, x.args)
+	})); err != nil {
+		t.Fatalf("add node: %v", err)
+	}
+	if err := g.AddEdge(compose.START, "tool"); err != nil {
+		t.Fatalf("add edge: %v", err)
+	}
+	if err := g.AddEdge("tool", compose.END); err != nil {
+		t.Fatalf("add edge: %v", err)
+	}
+	r, err := g.Compile(context.Background(), compose.WithCheckPointStore(store.NewInMemoryStore()))
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	x.runner = r
+	return x
+}
+
+// run starts a run under checkPointID and returns its output, or the root causes of its interrupts.
+func (x *interruptible[O]) run(checkPointID, argumentsInJSON string) (O, []*compose.InterruptCtx, error) {
+	x.args = argumentsInJSON
+	return x.invoke(context.Background(), checkPointID)
+}
+
+// resume continues the run of checkPointID, targeting the interrupts in resumeData, none if it is nil.
+func (x *interruptible[O]) resume(checkPointID string, resumeData map[string]any) (O, []*compose.InterruptCtx, error) {
+	ctx := context.Background()
+	if resumeData != nil {
+		ctx = compose.BatchResumeWithData(ctx, resumeData)
+	}
+	return x.invoke(ctx, checkPointID)
+}
+
+func (x *interruptible[O]) invoke(ctx context.Context, checkPointID string) (O, []*compose.InterruptCtx, error) {
+	out, err := x.runner.Invoke(ctx, "", compose.WithCheckPointID(checkPointID))
+	info, ok := compose.ExtractInterruptInfo(err)
+	if !ok {
+		return out, nil, err
+	}
+	var interrupts []*compose.InterruptCtx
+	for _, ic := range info.InterruptContexts {
+		if ic.IsRootCause {
+			interrupts = append(interrupts, ic)
+		}
+	}
+	return out, interrupts, nil
+}
+
+// mustInterrupt starts a run under checkPointID and returns its only interrupt.
+func (x *interruptible[O]) mustInterrupt(t *testing.T, checkPointID, argumentsInJSON string) *compose.InterruptCtx {
+	t.Helper()
+	_, interrupts, err := x.run(checkPointID, argumentsInJSON)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(interrupts) != 1 {
+		t.Fatalf("expected one interrupt, got %d", len(interrupts))
+	}
+	return interrupts[0]
+}
+
+// fakeTool echoes its arguments, as a result or as a stream of chunks, and counts its calls.
+type fakeTool struct {
+	name   string
+	chunks []string
+	calls  int
+	args   string
+	// stream is the last stream returned by StreamableRun
+	stream *schema.StreamReader[string]
+}
+
+func (f *fakeTool) Info(context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: f.name, Desc: "a fake tool"}, nil
+}
+
+func (f *fakeTool) InvokableRun(_ context.Context, argumentsInJSON string, _ ...tool.Option) (string, error) {
+	f.calls++
+	f.args = argumentsInJSON
+	return "ran with " + argumentsInJSON, nil
+}
+
+func (f *fakeTool) StreamableRun(_ context.Context, argumentsInJSON string, _ ...tool.Option) (*schema.StreamReader[string], error) {
+	f.calls++
+	f.args = argumentsInJSON
+	f.stream = schema.StreamReaderFromArray(f.chunks)
+	return f.stream, nil
+}
@@ -18,7 +18,10 @@ package tool
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
@@ -29,6 +32,11 @@ type ReviewEditInfo struct {
 	ToolName        string
 	ArgumentsInJSON string
 	ReviewResult    *ReviewEditResult
+
+	// RejectedArgumentsInJSON and ValidationErrors are set when the previous edit did not
+	// match the tool's parameters. ArgumentsInJSON still holds the original arguments.
+	RejectedArgumentsInJSON string
+	ValidationErrors        []ArgumentError
 }
 
 // ReviewEditResult is the result of the user's review.
@@ -39,11 +47,29 @@ type ReviewEditResult struct {
 	DisapproveReason      *string
 }
 
+// ReviewEditOutcome is what the wrapped tool returns to the model when the reviewer edited the arguments.
+// It is serialized as JSON so both the model and programmatic consumers can see exactly what changed.
+type ReviewEditOutcome struct {
+	Note            string           `json:"note"`
+	EditedArguments json.RawMessage  `json:"edited_arguments"`
+	Changes         []ArgumentChange `json:"changes"`
+	Result          string           `json:"result,omitempty"`
+}
+
 func (re *ReviewEditInfo) String() string {
-	return fmt.Sprintf("Tool '%s' is about to be called with the following arguments:\n`\n%s\n`\n\n"+
+	var sb strings.Builder
+	if len(re.ValidationErrors) > 0 {
+		sb.WriteString(fmt.Sprintf("The edited arguments\n`\n%s\n`\nwere rejected:\n", re.RejectedArgumentsInJSON))
+		for _, e := range re.ValidationErrors {
+			sb.WriteString(fmt.Sprintf("  - %s\n", e.String()))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("Tool '%s' is about to be called with the following arguments:\n`\n%s\n`\n\n"+
 		"Please review and either provide edited arguments in JSON format, "+
 		"reply with 'no need to edit', or reply with 'N' to disapprove the tool call.",
-		re.ToolName, re.ArgumentsInJSON)
+		re.ToolName, re.ArgumentsInJSON))
+	return sb.String()
 }
 
 func init() {
@@ -51,6 +77,8 @@ func init() {
 }
 
 // InvokableReviewEditTool is a wrapper that enforces a review-and-edit step.
+// Edited arguments are validated against the tool's parameters; an invalid edit
+// interrupts again with the validation errors in ReviewEditInfo.
 type InvokableReviewEditTool struct {
 	tool.InvokableTool
 }
@@ -67,46 +95,172 @@ func (i InvokableReviewEditTool) InvokableRun(ctx context.Context, argumentsInJS
 		return "", err
 	}
 
+	d, err := checkReviewEdit(ctx, toolInfo, argumentsInJSON)
+	if err != nil {
+		return "", err
+	}
+	if d.refusal != "" {
+		return d.refusal, nil
+	}
+
+	res, err := i.InvokableTool.InvokableRun(ctx, d.args, opts...)
+	if err != nil {
+		return "", err
+	}
+	if d.outcome == nil {
+		return res, nil
+	}
+
+	d.outcome.Result = res
+	b, err := json.Marshal(d.outcome)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// StreamableReviewEditTool is the streaming counterpart of InvokableReviewEditTool.
+// It interrupts with the same *ReviewEditInfo payload before the wrapped tool starts streaming,
+// then streams the wrapped tool's output using the reviewed arguments.
+// When the arguments were edited, the first chunk is a ReviewEditOutcome without Result,
+// as one line of JSON, followed by the tool's own chunks.
+type StreamableReviewEditTool struct {
+	tool.StreamableTool
+}
+
+func (s StreamableReviewEditTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return s.StreamableTool.Info(ctx)
+}
+
+func (s StreamableReviewEditTool) StreamableRun(ctx context.Context, argumentsInJSON string,
+	opts ...tool.Option) (*schema.StreamReader[string], error) {
+
+	toolInfo, err := s.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := checkReviewEdit(ctx, toolInfo, argumentsInJSON)
+	if err != nil {
+		return nil, err
+	}
+	if d.refusal != "" {
+		return schema.StreamReaderFromArray([]string{d.refusal}), nil
+	}
+
+	sr, err := s.StreamableTool.StreamableRun(ctx, d.args, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if d.outcome == nil {
+		return sr, nil
+	}
+
+	b, err := json.Marshal(d.outcome)
+	if err != nil {
+		sr.Close()
+		return nil, err
+	}
+	return prependToStream(string(b)+"\n", sr), nil
+}
+
+// prependToStream returns a stream that yields first and then every chunk of sr, in order.
+func prependToStream(first string, sr *schema.StreamReader[string]) *schema.StreamReader[string] {
+	out, sw := schema.Pipe[string](1)
+	go func() {
+		defer sw.Close()
+		defer sr.Close()
+
+		if closed := sw.Send(first, nil); closed {
+			return
+		}
+		for {
+			chunk, err := sr.Recv()
+			if err == io.EOF {
+				return
+			}
+			if closed := sw.Send(chunk, err); closed || err != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// reviewDecision is the interpretation of a review: either a refusal for the model,
+// or the arguments to run the tool with plus, for an edit, the outcome to report.
+type reviewDecision struct {
+	args    string
+	outcome *ReviewEditOutcome
+	refusal string
+}
+
+// checkReviewEdit interrupts on the first call and interprets the ReviewEditResult on resume.
+// Edited arguments that fail validation lead to another interrupt carrying the errors.
+func checkReviewEdit(ctx context.Context, toolInfo *schema.ToolInfo, argumentsInJSON string) (*reviewDecision, error) {
+	toolName := toolInfo.Name
+
 	wasInterrupted, _, storedArguments := tool.GetInterruptState[string](ctx)
 	if !wasInterrupted {
-		return "", tool.StatefulInterrupt(ctx, &ReviewEditInfo{
-			ToolName:        toolInfo.Name,
+		return nil, tool.StatefulInterrupt(ctx, &ReviewEditInfo{
+			ToolName:        toolName,
 			ArgumentsInJSON: argumentsInJSON,
 		}, argumentsInJSON)
 	}
 
 	isResumeTarget, hasData, data := tool.GetResumeContext[*ReviewEditInfo](ctx)
 	if !isResumeTarget {
-		return "", tool.StatefulInterrupt(ctx, &ReviewEditInfo{
-			ToolName:        toolInfo.Name,
+		return nil, tool.StatefulInterrupt(ctx, &ReviewEditInfo{
+			ToolName:        toolName,
 			ArgumentsInJSON: storedArguments,
 		}, storedArguments)
 	}
 	if !hasData || data.ReviewResult == nil {
-		return "", fmt.Errorf("tool '%s' resumed with no review data", toolInfo.Name)
+		return nil, fmt.Errorf("tool '%s' resumed with no review data", toolName)
 	}
 
 	result := data.ReviewResult
 
 	if result.Disapproved {
 		if result.DisapproveReason != nil {
-			return fmt.Sprintf("tool '%s' disapproved, reason: %s", toolInfo.Name, *result.DisapproveReason), nil
+			return &reviewDecision{refusal: fmt.Sprintf("tool '%s' disapproved, reason: %s", toolName, *result.DisapproveReason)}, nil
 		}
-		return fmt.Sprintf("tool '%s' disapproved", toolInfo.Name), nil
+		return &reviewDecision{refusal: fmt.Sprintf("tool '%s' disapproved", toolName)}, nil
 	}
 
 	if result.NoNeedToEdit {
-		return i.InvokableTool.InvokableRun(ctx, storedArguments, opts...)
+		return &reviewDecision{args: storedArguments}, nil
 	}
 
 	if result.EditedArgumentsInJSON != nil {
-		res, err := i.InvokableTool.InvokableRun(ctx, *result.EditedArgumentsInJSON, opts...)
+		edited := *result.EditedArgumentsInJSON
+
+		validationErrs, err := ValidateArguments(toolInfo, edited)
+		if err != nil {
+			return nil, err
+		}
+		if len(validationErrs) > 0 {
+			return nil, tool.StatefulInterrupt(ctx, &ReviewEditInfo{
+				ToolName:                toolName,
+				ArgumentsInJSON:         storedArguments,
+				RejectedArgumentsInJSON: edited,
+				ValidationErrors:        validationErrs,
+			}, storedArguments)
+		}
+
+		changes, err := DiffArguments(storedArguments, edited)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return fmt.Sprintf("after presenting the tool call info to the user, the user explilcitly changed tool call arguments to %s. Tool called, final result: %s",
-			*result.EditedArgumentsInJSON, res), nil
+		return &reviewDecision{
+			args: edited,
+			outcome: &ReviewEditOutcome{
+				Note:            "after presenting the tool call info to the user, the user explicitly changed the tool call arguments before the tool was called",
+				EditedArguments: json.RawMessage(edited),
+				Changes:         changes,
+			},
+		}, nil
 	}
 
-	return "", fmt.Errorf("invalid review result for tool '%s'", toolInfo.Name)
+	return nil, fmt.Errorf("invalid review result for tool '%s'", toolName)
 }
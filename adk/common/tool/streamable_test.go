@@ -0,0 +1,197 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+type stream = *schema.StreamReader[string]
+
+func readAll(t *testing.T, sr stream) []string {
+	t.Helper()
+	defer sr.Close()
+	var chunks []string
+	for {
+		chunk, err := sr.Recv()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+}
+
+func TestStreamableApprovableTool(t *testing.T) {
+	cases := []struct {
+		name   string
+		result *ApprovalResult
+		want   []string
+	}{
+		{name: "approved", result: &ApprovalResult{Approved: true}, want: []string{"a", "b", "c"}},
+		{name: "disapproved", result: &ApprovalResult{Approved: false},
+			want: []string{"tool 'book' disapproved"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := &fakeTool{name: "book", chunks: []string{"a", "b", "c"}}
+			wrapped := StreamableApprovableTool{StreamableTool: fake}
+			x := newInterruptible(t, func(ctx context.Context, args string) (stream, error) {
+				return wrapped.StreamableRun(ctx, args)
+			})
+
+			ic := x.mustInterrupt(t, "cp", `{"to":"Beijing"}`)
+			if info, ok := ic.Info.(*ApprovalInfo); !ok || info.ToolName != "book" || info.Rememberable {
+				t.Fatalf("unexpected interrupt info %#v", ic.Info)
+			}
+			if fake.calls != 0 {
+				t.Fatalf("the tool started streaming before the approval")
+			}
+
+			sr, interrupts, err := x.resume("cp", map[string]any{ic.ID: c.result})
+			if err != nil || len(interrupts) != 0 {
+				t.Fatalf("resume: %d interrupts, err %v", len(interrupts), err)
+			}
+			if c.result.Approved {
+				if sr != fake.stream || fake.args != `{"to":"Beijing"}` {
+					t.Fatalf("an approved call must return the tool's own stream")
+				}
+			} else if fake.calls != 0 {
+				t.Fatalf("a disapproved call must not run the tool")
+			}
+			if got := readAll(t, sr); !reflect.DeepEqual(got, c.want) {
+				t.Fatalf("got chunks %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestStreamableReviewEditTool(t *testing.T) {
+	t.Run("no need to edit", func(t *testing.T) {
+		fake := &fakeTool{name: "book", chunks: []string{"a", "b"}}
+		x := newInterruptible(t, func(ctx context.Context, args string) (stream, error) {
+			return StreamableReviewEditTool{StreamableTool: fake}.StreamableRun(ctx, args)
+		})
+
+		ic := x.mustInterrupt(t, "cp", `{"to":"Beijing"}`)
+		sr, _, err := x.resume("cp", map[string]any{ic.ID: &ReviewEditInfo{ReviewResult: &ReviewEditResult{NoNeedToEdit: true}}})
+		if err != nil {
+			t.Fatalf("resume: %v", err)
+		}
+		if sr != fake.stream {
+			t.Fatalf("an unedited call must return the tool's own stream")
+		}
+	})
+
+	t.Run("edited", func(t *testing.T) {
+		fake := &fakeTool{name: "book", chunks: []string{"a", "b", "c"}}
+		x := newInterruptible(t, func(ctx context.Context, args string) (stream, error) {
+			return StreamableReviewEditTool{StreamableTool: fake}.StreamableRun(ctx, args)
+		})
+
+		ic := x.mustInterrupt(t, "cp", `{"to":"Beijing"}`)
+		if info, ok := ic.Info.(*ReviewEditInfo); !ok || info.ArgumentsInJSON != `{"to":"Beijing"}` {
+			t.Fatalf("unexpected interrupt info %#v", ic.Info)
+		}
+		edited := `{"to":"Shanghai"}`
+		sr, _, err := x.resume("cp", map[string]any{ic.ID: &ReviewEditInfo{ReviewResult: &ReviewEditResult{EditedArgumentsInJSON: &edited}}})
+		if err != nil {
+			t.Fatalf("resume: %v", err)
+		}
+		if fake.args != edited {
+			t.Fatalf("the tool ran with %s, want the edited arguments", fake.args)
+		}
+
+		chunks := readAll(t, sr)
+		if len(chunks) != 4 || !reflect.DeepEqual(chunks[1:], fake.chunks) {
+			t.Fatalf("expected the outcome followed by the tool's chunks, got %q", chunks)
+		}
+		var outcome ReviewEditOutcome
+		if err := json.Unmarshal([]byte(chunks[0]), &outcome); err != nil {
+			t.Fatalf("the first chunk is not an outcome: %v", err)
+		}
+		if string(outcome.EditedArguments) != edited || len(outcome.Changes) != 1 || outcome.Result != "" {
+			t.Fatalf("unexpected outcome %s", chunks[0])
+		}
+	})
+}
+
+func TestPrependToStream(t *testing.T) {
+	t.Run("keeps the order", func(t *testing.T) {
+		src := schema.StreamReaderFromArray([]string{"b", "c", "d"})
+		if got := readAll(t, prependToStream("a", src)); !reflect.DeepEqual(got, []string{"a", "b", "c", "d"}) {
+			t.Fatalf("got chunks %q", got)
+		}
+	})
+
+	t.Run("closes the source", func(t *testing.T) {
+		src, sw := schema.Pipe[string](0)
+		closed := make(chan struct{})
+		go func() {
+			defer sw.Close()
+			for {
+				if sw.Send("chunk", nil) {
+					close(closed)
+					return
+				}
+			}
+		}()
+
+		out := prependToStream("first", src)
+		if chunk, err := out.Recv(); err != nil || chunk != "first" {
+			t.Fatalf("got %q, err %v", chunk, err)
+		}
+		// a consumer that stops early must not leave the source open
+		out.Close()
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatalf("the source stream was not closed")
+		}
+	})
+}
+
+func TestStreamableFollowUpTool(t *testing.T) {
+	followUp := GetStreamableFollowUpTool()
+	x := newInterruptible(t, func(ctx context.Context, args string) (stream, error) {
+		return followUp.StreamableRun(ctx, args)
+	})
+
+	ic := x.mustInterrupt(t, "cp", `{"questions":["which class?"]}`)
+	info, ok := ic.Info.(*FollowUpInfo)
+	if !ok || !reflect.DeepEqual(info.Questions, []string{"which class?"}) {
+		t.Fatalf("unexpected interrupt info %#v", ic.Info)
+	}
+
+	sr, interrupts, err := x.resume("cp", map[string]any{ic.ID: &FollowUpInfo{UserAnswer: "economy"}})
+	if err != nil || len(interrupts) != 0 {
+		t.Fatalf("resume: %d interrupts, err %v", len(interrupts), err)
+	}
+	if got := readAll(t, sr); !reflect.DeepEqual(got, []string{"economy"}) {
+		t.Fatalf("got chunks %q", got)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TimeoutAction is what a pending interrupt resolves to once its deadline has passed.
+type TimeoutAction string
+
+const (
+	// TimeoutApprove approves the call, or answers a follow-up with TimeoutPolicy.DefaultAnswer.
+	TimeoutApprove TimeoutAction = "approve"
+	// TimeoutReject refuses the call and reports TimeoutPolicy.Reason back to the model.
+	TimeoutReject TimeoutAction = "reject"
+	// TimeoutFail fails the run with an error wrapping ErrInterruptExpired.
+	TimeoutFail TimeoutAction = "fail"
+)
+
+// ErrInterruptExpired is wrapped by the error returned when a TimeoutFail policy fires.
+var ErrInterruptExpired = errors.New("pending interrupt expired")
+
+// TimeoutPolicy gives a pending interrupt a deadline and a default decision, so unattended
+// runs do not wait forever. The deadline is fixed when the tool first interrupts and is saved
+// with the interrupt state, so it survives restarts of a persistent checkpoint store.
+//
+// The deadline is only checked when the run is resumed; use hitl.Sweeper to resume
+// expired checkpoints. Once expired, the default decision applies even if the resume
+// carries a human answer, so a late answer cannot race with the sweeper.
+type TimeoutPolicy struct {
+	// After is how long the interrupt may stay pending. Zero disables the timeout.
+	After time.Duration
+
+	// Action defaults to TimeoutReject.
+	Action TimeoutAction
+
+	// Reason is reported to the model on TimeoutReject and included in the error on TimeoutFail.
+	Reason string
+
+	// DefaultAnswer is used by the follow-up tool on TimeoutApprove.
+	DefaultAnswer string
+}
+
+func (p *TimeoutPolicy) deadline() time.Time {
+	if p == nil || p.After <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(p.After)
+}
+
+func (p *TimeoutPolicy) action() TimeoutAction {
+	if p == nil || p.Action == "" {
+		return TimeoutReject
+	}
+	return p.Action
+}
+
+func (p *TimeoutPolicy) reason() string {
+	if p == nil || p.Reason == "" {
+		return "no decision before the deadline"
+	}
+	return p.Reason
+}
+
+func (p *TimeoutPolicy) expiredError(toolName string, deadline time.Time) error {
+	return fmt.Errorf("tool '%s' %w at %s: %s", toolName, ErrInterruptExpired, deadline.Format(time.RFC3339), p.reason())
+}
+
+func isExpired(deadline time.Time) bool {
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
+
+func formatDeadline(deadline time.Time, action TimeoutAction) string {
+	if deadline.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (expires at %s, then: %s)", deadline.Format(time.RFC3339), action)
+}
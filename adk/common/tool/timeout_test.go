@@ -0,0 +1,128 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const expireAfter = 10 * time.Millisecond
+
+func TestApprovableTool_Timeout(t *testing.T) {
+	cases := []struct {
+		action  TimeoutAction
+		want    string // expected result, "" when the run fails
+		runs    bool
+		wantErr error
+	}{
+		{action: TimeoutApprove, want: `ran with {"to":"Beijing"}`, runs: true},
+		{action: TimeoutReject, want: "tool 'book' disapproved, reason: no approver"},
+		{action: TimeoutFail, wantErr: ErrInterruptExpired},
+	}
+	for _, c := range cases {
+		t.Run(string(c.action), func(t *testing.T) {
+			fake := &fakeTool{name: "book"}
+			wrapped := InvokableApprovableTool{InvokableTool: fake,
+				Timeout: &TimeoutPolicy{After: expireAfter, Action: c.action, Reason: "no approver"}}
+			x := newInterruptible(t, func(ctx context.Context, args string) (string, error) {
+				return wrapped.InvokableRun(ctx, args)
+			})
+
+			ic := x.mustInterrupt(t, "cp", `{"to":"Beijing"}`)
+			info, ok := ic.Info.(*ApprovalInfo)
+			if !ok || info.ExpiresAt().IsZero() || info.OnTimeout != c.action {
+				t.Fatalf("unexpected interrupt info %#v", ic.Info)
+			}
+
+			// a resume before the deadline that answers nothing interrupts again
+			_, interrupts, err := x.resume("cp", nil)
+			if err != nil || len(interrupts) != 1 {
+				t.Fatalf("expected the approval to stay pending, got %d interrupts, err %v", len(interrupts), err)
+			}
+
+			time.Sleep(2 * expireAfter)
+			// once expired, the default applies even to a late human answer
+			out, interrupts, err := x.resume("cp", map[string]any{interrupts[0].ID: &ApprovalResult{Approved: c.action != TimeoutApprove}})
+			if len(interrupts) != 0 {
+				t.Fatalf("an expired approval must not interrupt again")
+			}
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) || !strings.Contains(err.Error(), "no approver") {
+					t.Fatalf("expected an error wrapping %v, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil || out != c.want {
+				t.Fatalf("got %q, err %v, want %q", out, err, c.want)
+			}
+			if ran := fake.calls == 1; ran != c.runs {
+				t.Fatalf("tool ran %d time(s), want ran=%v", fake.calls, c.runs)
+			}
+		})
+	}
+}
+
+func TestFollowUp_Timeout(t *testing.T) {
+	cases := []struct {
+		name    string
+		policy  TimeoutPolicy
+		want    string
+		wantErr error
+	}{
+		{name: "default answer", policy: TimeoutPolicy{Action: TimeoutApprove, DefaultAnswer: "economy class"}, want: "economy class"},
+		{name: "no default answer", policy: TimeoutPolicy{Action: TimeoutApprove},
+			want: "The user did not answer in time, proceed with your best assumptions."},
+		{name: "reject", policy: TimeoutPolicy{Action: TimeoutReject, Reason: "user away"},
+			want: "The user did not answer in time: user away"},
+		{name: "fail", policy: TimeoutPolicy{Action: TimeoutFail}, wantErr: ErrInterruptExpired},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			policy := c.policy
+			policy.After = expireAfter
+			followUpFunc := NewFollowUpFunc(&policy)
+			x := newInterruptible(t, func(ctx context.Context, _ string) (string, error) {
+				return followUpFunc(ctx, &FollowUpToolInput{Questions: []string{"which class?"}})
+			})
+
+			ic := x.mustInterrupt(t, "cp", "")
+			info, ok := ic.Info.(*FollowUpInfo)
+			if !ok || info.ExpiresAt().IsZero() || info.OnTimeout != policy.action() {
+				t.Fatalf("unexpected interrupt info %#v", ic.Info)
+			}
+
+			time.Sleep(2 * expireAfter)
+			out, interrupts, err := x.resume("cp", map[string]any{ic.ID: nil})
+			if len(interrupts) != 0 {
+				t.Fatalf("an expired follow-up must not interrupt again")
+			}
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("expected an error wrapping %v, got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil || out != c.want {
+				t.Fatalf("got %q, err %v, want %q", out, err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+
+	"github.com/cloudwego/eino-examples/adk/common/model"
+	tool2 "github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// NewTravelAgent returns the agent. With a positive approvalTimeout, unanswered approvals
+// expire: the flight and the hotel are then refused, the cheaper car rental is approved.
+func NewTravelAgent(approvalTimeout time.Duration) adk.Agent {
+	ctx := context.Background()
+
+	var rejectOnTimeout, approveOnTimeout *tool2.TimeoutPolicy
+	if approvalTimeout > 0 {
+		rejectOnTimeout = &tool2.TimeoutPolicy{After: approvalTimeout, Action: tool2.TimeoutReject, Reason: "no approver available"}
+		approveOnTimeout = &tool2.TimeoutPolicy{After: approvalTimeout, Action: tool2.TimeoutApprove}
+	}
+
+	type flightInput struct {
+		From          string `json:"from"`
+		To            string `json:"to"`
+		Date          string `json:"date"`
+		PassengerName string `json:"passenger_name"`
+	}
+
+	type hotelInput struct {
+		City      string `json:"city"`
+		CheckIn   string `json:"check_in"`
+		Nights    int    `json:"nights"`
+		GuestName string `json:"guest_name"`
+	}
+
+	type carInput struct {
+		City       string `json:"city"`
+		PickupDate string `json:"pickup_date"`
+		Days       int    `json:"days"`
+		DriverName string `json:"driver_name"`
+	}
+
+	bookFlight, err := utils.InferTool("BookFlight", "book a flight ticket",
+		func(ctx context.Context, input flightInput) (string, error) {
+			return fmt.Sprintf("flight %s -> %s on %s booked for %s", input.From, input.To, input.Date, input.PassengerName), nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bookHotel, err := utils.InferTool("BookHotel", "book a hotel room",
+		func(ctx context.Context, input hotelInput) (string, error) {
+			return fmt.Sprintf("hotel in %s from %s for %d night(s) booked for %s", input.City, input.CheckIn, input.Nights, input.GuestName), nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rentCar, err := utils.InferTool("RentCar", "rent a car",
+		func(ctx context.Context, input carInput) (string, error) {
+			return fmt.Sprintf("car in %s from %s for %d day(s) rented for %s", input.City, input.PickupDate, input.Days, input.DriverName), nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+		Name:        "TravelBooker",
+		Description: "An agent that books flights, hotels and rental cars",
+		Instruction: `You are a travel assistant.
+When the user asks for several bookings, call all the needed tools in a single response so they can be approved together.`,
+		Model: model.NewChatModel(),
+		ToolsConfig: adk.ToolsConfig{
+			ToolsNodeConfig: compose.ToolsNodeConfig{
+				Tools: []tool.BaseTool{
+					&tool2.InvokableApprovableTool{InvokableTool: bookFlight, Timeout: rejectOnTimeout},
+					&tool2.InvokableApprovableTool{InvokableTool: bookHotel, Timeout: rejectOnTimeout},
+					&tool2.InvokableApprovableTool{InvokableTool: rentCar, Timeout: approveOnTimeout},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chatmodel: %w", err))
+	}
+
+	return a
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
+	"github.com/cloudwego/eino-examples/adk/common/prints"
+	"github.com/cloudwego/eino-examples/adk/common/store"
+)
+
+func main() {
+	approvalTimeout := flag.Duration("approval-timeout", 0,
+		"leave the approvals unanswered and let a sweeper resolve them after this long, e.g. 10s")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	runner := adk.NewRunner(ctx, adk.RunnerConfig{
+		EnableStreaming: true,
+		Agent:           NewTravelAgent(*approvalTimeout),
+		CheckPointStore: store.NewCheckPointStore(),
+	})
+
+	const checkPointID = "batch-1"
+	query := "I'm Martin. Book me a flight from Beijing to Shanghai on 2025-12-01, " +
+		"a hotel in Shanghai for 3 nights from that day, and a rental car in Shanghai for the same 3 days."
+	iter := runner.Query(ctx, query, adk.WithCheckPointID(checkPointID))
+
+	if *approvalTimeout > 0 {
+		sweepUntilDone(ctx, runner, checkPointID, iter)
+		return
+	}
+
+	term := hitl.NewTerminal(nil)
+	for {
+		lastEvent, interrupted := processEvents(iter)
+		if !interrupted {
+			break
+		}
+
+		// every parallel tool call that needs approval is pending at the same time
+		batch := hitl.NewBatch(checkPointID, lastEvent)
+		fmt.Printf("\n%d tool call(s) are waiting for your decision\n", len(batch.Pending()))
+
+		for len(batch.Targets()) == 0 {
+			if err := term.Collect(batch); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		var err error
+		iter, err = batch.Resume(ctx, runner)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// sweepUntilDone answers nothing: a sweeper resumes the run once its approvals expire,
+// and their TimeoutPolicy decides instead.
+func sweepUntilDone(ctx context.Context, runner *adk.Runner, checkPointID string, iter *adk.AsyncIterator[*adk.AgentEvent]) {
+	lastEvent, interrupted := processEvents(iter)
+	if !interrupted {
+		return
+	}
+
+	index := hitl.NewMemoryDeadlineIndex()
+	sweeper, err := hitl.NewSweeper(&hitl.SweeperConfig{
+		Runner: runner,
+		Index:  index,
+		OnEvent: func(_ string, event *adk.AgentEvent) {
+			prints.Event(event)
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = sweeper.Track(ctx, checkPointID, lastEvent); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("\nnobody answers, the approvals resolve to their timeout action once they expire")
+
+	// Sweeper.Run would sweep in the background; here the example stops once nothing is tracked
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err = sweeper.Sweep(ctx); err != nil {
+			log.Fatal(err)
+		}
+		pending, err := index.List(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(pending) == 0 {
+			return
+		}
+	}
+}
+
+func processEvents(iter *adk.AsyncIterator[*adk.AgentEvent]) (*adk.AgentEvent, bool) {
+	var lastEvent *adk.AgentEvent
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if event.Err != nil {
+			log.Fatal(event.Err)
+		}
+
+		prints.Event(event)
+		lastEvent = event
+	}
+
+	if lastEvent == nil {
+		return nil, false
+	}
+	return lastEvent, lastEvent.Action != nil && lastEvent.Action.Interrupted != nil
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -30,12 +31,18 @@ import (
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/coze-dev/cozeloop-go"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 	"github.com/cloudwego/eino-examples/adk/common/tool"
 )
 
 func main() {
+	// -resume lets a new process continue an interrupt raised by a previous one.
+	// It only works when CHECKPOINT_STORE points to a durable store (file or redis).
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 
 	cozeloopApiToken := os.Getenv("COZELOOP_API_TOKEN")
@@ -59,42 +66,24 @@ func main() {
 	callbacks.AppendGlobalHandlers(handlers...)
 
 	a := NewTicketBookingAgent()
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(context.Background(), adk.RunnerConfig{
 		EnableStreaming: true, // you can disable streaming here
 		Agent:           a,
 
 		// provide a CheckPointStore for eino to persist the execution state of the agent for later resumption.
-		// Here we use an in-memory store for simplicity.
-		// In the real world, you can use a distributed store like Redis to persist the checkpoints.
-		CheckPointStore: store.NewInMemoryStore(),
+		// By default this is an in-memory store. Set CHECKPOINT_STORE=file or CHECKPOINT_STORE=redis
+		// to persist the checkpoints, so that the interrupt can be resumed after the process restarts.
+		CheckPointStore: checkPointStore,
 	})
-	iter := runner.Query(context.Background(), "book a ticket for Martin, to Beijing, on 2025-12-01, the phone number is 1234567. directly call tool.", adk.WithCheckPointID("1"))
-
-	var lastEvent *adk.AgentEvent
-	for {
-		event, ok := iter.Next()
-		if !ok {
-			break
-		}
-		if event.Err != nil {
-			log.Fatal(event.Err)
-		}
-
-		prints.Event(event)
 
-		lastEvent = event
-	}
-
-	if lastEvent == nil {
-		log.Fatal("last event is nil")
-	}
-	if lastEvent.Action == nil || lastEvent.Action.Interrupted == nil {
-		log.Fatal("last event is not an interrupt event")
+	iter, err := hitl.QueryOrResume(ctx, runner, "1",
+		"book a ticket for Martin, to Beijing, on 2025-12-01, the phone number is 1234567. directly call tool.", *resume)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// this interruptID is crucial 'locator' for Eino to know where the interrupt happens,
-	// so when resuming later, you have to provide this same `interruptID` along with the approval result back to Eino
-	interruptID := lastEvent.Action.Interrupted.InterruptContexts[0].ID
+	interruptID := waitInterrupt(iter)
+	hitl.PrintResumeHint(checkPointStore)
 
 	var apResult *tool.ApprovalResult
 	for {
@@ -119,11 +108,11 @@ func main() {
 		fmt.Println("invalid input, please input Y or N")
 	}
 
-	// here we directly resumes right in the same instance where the original `Runner.Query` happened.
-	// In the real world, the original `Runner.Run/Query` and the subsequent `Runner.ResumeWithParams`
-	// can happen in different processes or machines, as long as you use the same `CheckPointID`,
-	// and you provided a distributed `CheckPointStore` when creating the `Runner` instance.
-	iter, err := runner.ResumeWithParams(context.Background(), "1", &adk.ResumeParams{
+	// without -resume we resume right in the same instance where the original `Runner.Query` happened.
+	// The original `Runner.Run/Query` and the subsequent `Runner.ResumeWithParams`
+	// can also happen in different processes or machines, as long as you use the same `CheckPointID`,
+	// and you provided a durable `CheckPointStore` when creating the `Runner` instance.
+	iter, err = runner.ResumeWithParams(context.Background(), "1", &adk.ResumeParams{
 		Targets: map[string]any{
 			interruptID: apResult,
 		},
@@ -144,3 +133,32 @@ func main() {
 		prints.Event(event)
 	}
 }
+
+// waitInterrupt prints the events of a run and returns the ID of the interrupt it stops at.
+func waitInterrupt(iter *adk.AsyncIterator[*adk.AgentEvent]) string {
+	var lastEvent *adk.AgentEvent
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if event.Err != nil {
+			log.Fatal(event.Err)
+		}
+
+		prints.Event(event)
+
+		lastEvent = event
+	}
+
+	if lastEvent == nil {
+		log.Fatal("last event is nil")
+	}
+	if lastEvent.Action == nil || lastEvent.Action.Interrupted == nil {
+		log.Fatal("last event is not an interrupt event")
+	}
+
+	// this interruptID is crucial 'locator' for Eino to know where the interrupt happens,
+	// so when resuming later, you have to provide this same `interruptID` along with the approval result back to Eino
+	return lastEvent.Action.Interrupted.InterruptContexts[0].ID
+}
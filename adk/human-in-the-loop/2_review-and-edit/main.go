@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -26,20 +27,29 @@ import (
 
 	"github.com/cloudwego/eino/adk"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 	"github.com/cloudwego/eino-examples/adk/common/tool"
 )
 
 func main() {
+	// -resume continues the run interrupted by a previous process, see adk/common/hitl
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 	a := NewTicketAgent()
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		Agent:           a,
-		CheckPointStore: store.NewInMemoryStore(),
+		CheckPointStore: checkPointStore,
 	})
 
-	iter := runner.Query(ctx, "book a ticket for Martin, to Beijing, on 2025-12-01, the phone number is 1234567. directly call tool.", adk.WithCheckPointID("1"))
+	iter, err := hitl.QueryOrResume(ctx, runner, "1", "book a ticket for Martin, to Beijing, on 2025-12-01, the phone number is 1234567. directly call tool.", *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for {
 		var lastEvent *adk.AgentEvent
@@ -74,6 +84,7 @@ func main() {
 		// Handle the review-and-edit interrupt
 		interruptCtx := lastEvent.Action.Interrupted.InterruptContexts[0]
 		reInfo := interruptCtx.Info.(*tool.ReviewEditInfo)
+		hitl.PrintResumeHint(checkPointStore)
 
 		scanner := bufio.NewScanner(os.Stdin)
 		fmt.Print("\nYour input: ")
@@ -98,7 +109,6 @@ func main() {
 		}
 		reInfo.ReviewResult = result
 
-		var err error
 		iter, err = runner.ResumeWithParams(ctx, "1", &adk.ResumeParams{
 			Targets: map[string]any{
 				interruptCtx.ID: reInfo,
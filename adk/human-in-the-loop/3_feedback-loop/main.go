@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -26,19 +27,28 @@ import (
 
 	"github.com/cloudwego/eino/adk"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 )
 
 func main() {
+	// -resume continues the run interrupted by a previous process, see adk/common/hitl
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 	a := NewWriterAgent()
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		EnableStreaming: true, // you can disable streaming here
 		Agent:           a,
-		CheckPointStore: store.NewInMemoryStore(),
+		CheckPointStore: checkPointStore,
 	})
-	iter := runner.Query(ctx, "write a short poem about potato, in under 20 words", adk.WithCheckPointID("1"))
+	iter, err := hitl.QueryOrResume(ctx, runner, "1", "write a short poem about potato, in under 20 words", *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for {
 		var lastEvent *adk.AgentEvent
@@ -70,6 +80,7 @@ func main() {
 
 		reInfo := lastEvent.Action.Interrupted.InterruptContexts[0].Info.(*FeedbackInfo)
 		interruptID := lastEvent.Action.Interrupted.InterruptContexts[0].ID
+		hitl.PrintResumeHint(checkPointStore)
 
 		for {
 			scanner := bufio.NewScanner(os.Stdin)
@@ -86,7 +97,6 @@ func main() {
 			}
 		}
 
-		var err error
 		iter, err = runner.ResumeWithParams(ctx, "1", &adk.ResumeParams{
 			Targets: map[string]any{
 				interruptID: reInfo,
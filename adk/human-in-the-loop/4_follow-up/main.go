@@ -19,27 +19,37 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/cloudwego/eino/adk"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 	"github.com/cloudwego/eino-examples/adk/common/tool"
 )
 
 func main() {
+	// -resume continues the run interrupted by a previous process, see adk/common/hitl
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 	a := NewItineraryAgent()
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		Agent:           a,
-		CheckPointStore: store.NewInMemoryStore(),
+		CheckPointStore: checkPointStore,
 	})
 
 	// Start with a vague request that will force the agent to ask for more information.
-	iter := runner.Query(ctx, "Plan a 3-day trip to New York City.", adk.WithCheckPointID("1"))
+	iter, err := hitl.QueryOrResume(ctx, runner, "1", "Plan a 3-day trip to New York City.", *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for {
 		var lastEvent *adk.AgentEvent
@@ -75,6 +85,7 @@ func main() {
 		// Handle the follow-up interrupt
 		interruptCtx := lastEvent.Action.Interrupted.InterruptContexts[0]
 		fuInfo := interruptCtx.Info.(*tool.FollowUpInfo)
+		hitl.PrintResumeHint(checkPointStore)
 
 		scanner := bufio.NewScanner(os.Stdin)
 		fmt.Print("\nYour answer: ")
@@ -84,7 +95,6 @@ func main() {
 
 		fuInfo.UserAnswer = nInput
 
-		var err error
 		iter, err = runner.ResumeWithParams(ctx, "1", &adk.ResumeParams{
 			Targets: map[string]any{
 				interruptCtx.ID: fuInfo,
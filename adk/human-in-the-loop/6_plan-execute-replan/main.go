@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -26,12 +27,17 @@ import (
 
 	"github.com/cloudwego/eino/adk"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 	"github.com/cloudwego/eino-examples/adk/common/tool"
 )
 
 func main() {
+	// -resume continues the run interrupted by a previous process, see adk/common/hitl
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 
 	agent, err := NewTravelPlanningAgent(ctx)
@@ -39,10 +45,11 @@ func main() {
 		log.Fatalf("failed to create travel planning agent: %v", err)
 	}
 
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		EnableStreaming: true,
 		Agent:           agent,
-		CheckPointStore: store.NewInMemoryStore(),
+		CheckPointStore: checkPointStore,
 	})
 
 	query := `Plan a 3-day trip to Tokyo starting from New York on 2025-10-15. 
@@ -54,7 +61,10 @@ Today is 2025-09-01.`
 	fmt.Println("========================================")
 	fmt.Println()
 
-	iter := runner.Query(ctx, query, adk.WithCheckPointID("travel-plan-1"))
+	iter, err := hitl.QueryOrResume(ctx, runner, "travel-plan-1", query, *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for {
 		lastEvent, interrupted := processEvents(iter)
@@ -65,6 +75,7 @@ Today is 2025-09-01.`
 		interruptCtx := lastEvent.Action.Interrupted.InterruptContexts[0]
 		interruptID := interruptCtx.ID
 		reInfo := interruptCtx.Info.(*tool.ReviewEditInfo)
+		hitl.PrintResumeHint(checkPointStore)
 
 		fmt.Println("\n========================================")
 		fmt.Println("REVIEW REQUIRED")
@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -26,12 +27,17 @@ import (
 
 	"github.com/cloudwego/eino/adk"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 	"github.com/cloudwego/eino-examples/adk/common/tool"
 )
 
 func main() {
+	// -resume continues the run interrupted by a previous process, see adk/common/hitl
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 
 	agent, err := NewDataAnalysisDeepAgent(ctx, newRateLimitedModel())
@@ -39,10 +45,11 @@ func main() {
 		log.Fatalf("failed to create deep agent: %v", err)
 	}
 
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		EnableStreaming: true,
 		Agent:           agent,
-		CheckPointStore: store.NewInMemoryStore(),
+		CheckPointStore: checkPointStore,
 	})
 
 	query := "Analyze the market trends and provide investment recommendations."
@@ -52,7 +59,10 @@ func main() {
 	fmt.Println("========================================")
 	fmt.Println()
 
-	iter := runner.Query(ctx, query, adk.WithCheckPointID("deep-analysis-1"))
+	iter, err := hitl.QueryOrResume(ctx, runner, "deep-analysis-1", query, *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for {
 		lastEvent, interrupted := processEvents(iter)
@@ -63,6 +73,7 @@ func main() {
 		interruptCtx := lastEvent.Action.Interrupted.InterruptContexts[0]
 		interruptID := interruptCtx.ID
 		followUpInfo := interruptCtx.Info.(*tool.FollowUpInfo)
+		hitl.PrintResumeHint(checkPointStore)
 
 		fmt.Println("\n========================================")
 		fmt.Println("CLARIFICATION NEEDED")
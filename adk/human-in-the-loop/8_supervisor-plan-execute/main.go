@@ -19,6 +19,7 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -26,12 +27,17 @@ import (
 
 	"github.com/cloudwego/eino/adk"
 
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
 	"github.com/cloudwego/eino-examples/adk/common/store"
 	"github.com/cloudwego/eino-examples/adk/common/tool"
 )
 
 func main() {
+	// -resume continues the run interrupted by a previous process, see adk/common/hitl
+	resume := hitl.ResumeFlag()
+	flag.Parse()
+
 	ctx := context.Background()
 
 	sv, err := buildProjectManagerSupervisor(ctx)
@@ -39,10 +45,11 @@ func main() {
 		log.Fatalf("build project manager supervisor failed: %v", err)
 	}
 
+	checkPointStore := store.NewCheckPointStore()
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		EnableStreaming: true,
 		Agent:           sv,
-		CheckPointStore: store.NewInMemoryStore(),
+		CheckPointStore: checkPointStore,
 	})
 
 	query := `Set up a new project called "Customer Portal" for the engineering department. 
@@ -54,7 +61,10 @@ and assigning a team of Alice, Bob, and Charlie starting from 2025-02-01.`
 	fmt.Println("========================================")
 	fmt.Println()
 
-	iter := runner.Query(ctx, query, adk.WithCheckPointID("project-setup-1"))
+	iter, err := hitl.QueryOrResume(ctx, runner, "project-setup-1", query, *resume)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for {
 		lastEvent, interrupted := processEvents(iter)
@@ -64,6 +74,7 @@ and assigning a team of Alice, Bob, and Charlie starting from 2025-02-01.`
 
 		interruptCtx := lastEvent.Action.Interrupted.InterruptContexts[0]
 		interruptID := interruptCtx.ID
+		hitl.PrintResumeHint(checkPointStore)
 
 		fmt.Println("\n========================================")
 		fmt.Println("APPROVAL REQUIRED")
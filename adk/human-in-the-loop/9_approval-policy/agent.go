@@ -0,0 +1,107 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+
+	"github.com/cloudwego/eino-examples/adk/common/model"
+	tool2 "github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// newPolicy allows small transfers, asks for large ones and refuses anything to an unknown account.
+func newPolicy() *tool2.ApprovalPolicy {
+	return &tool2.ApprovalPolicy{
+		Rules: []tool2.ApprovalRule{
+			{
+				Name:   "unknown-destination",
+				Tools:  []string{"transfer_funds"},
+				When:   []tool2.ArgCondition{{Path: "$.to_account", Op: tool2.OpNotIn, Value: []any{"checking", "savings"}}},
+				Action: tool2.ApprovalActionDeny,
+				Reason: "transfers are only allowed between the user's own accounts",
+			},
+			{
+				Name:   "large-transfer",
+				Tools:  []string{"transfer_funds"},
+				When:   []tool2.ArgCondition{{Path: "$.amount", Op: tool2.OpGt, Value: 1000}},
+				Action: tool2.ApprovalActionAsk,
+			},
+			{
+				Name:   "small-transfer",
+				Tools:  []string{"transfer_funds"},
+				Action: tool2.ApprovalActionAllow,
+			},
+		},
+		Default: tool2.ApprovalActionAllow,
+	}
+}
+
+func NewBankingAgent() adk.Agent {
+	ctx := context.Background()
+
+	type transferReq struct {
+		FromAccount string  `json:"from_account" jsonschema_description:"Source account ID"`
+		ToAccount   string  `json:"to_account" jsonschema_description:"Destination account ID"`
+		Amount      float64 `json:"amount" jsonschema_description:"Amount to transfer in USD"`
+	}
+
+	transferTool, err := utils.InferTool("transfer_funds", "Transfer funds between accounts.",
+		func(ctx context.Context, req *transferReq) (string, error) {
+			return fmt.Sprintf("transferred %.2f USD from %s to %s", req.Amount, req.FromAccount, req.ToAccount), nil
+		})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	gate, err := tool2.NewApprovalGate(&tool2.ApprovalGateConfig{
+		Policy: newPolicy(),
+		// every decision, including the ones the policy takes on its own, is appended to stderr as JSON
+		Audit: tool2.NewJSONLAuditLogger(os.Stderr),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	a, err := adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
+		Name:        "BankingAgent",
+		Description: "An agent that moves money between accounts",
+		Instruction: `You are a banking assistant.
+Use the "transfer_funds" tool once for each transfer the user asks for, you may call it several times in sequence.`,
+		Model: model.NewChatModel(),
+		ToolsConfig: adk.ToolsConfig{
+			ToolsNodeConfig: compose.ToolsNodeConfig{
+				Tools: []tool.BaseTool{transferTool},
+				// the gate is a plain ToolMiddleware, so it covers every tool of this node
+				// without wrapping them one by one.
+				ToolCallMiddlewares: []compose.ToolMiddleware{gate.Middleware()},
+			},
+		},
+	})
+	if err != nil {
+		log.Fatal(fmt.Errorf("failed to create chatmodel: %w", err))
+	}
+
+	return a
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/common/prints"
+	"github.com/cloudwego/eino-examples/adk/common/store"
+	"github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+func main() {
+	ctx := context.Background()
+
+	runner := adk.NewRunner(ctx, adk.RunnerConfig{
+		EnableStreaming: true,
+		Agent:           NewBankingAgent(),
+		CheckPointStore: store.NewCheckPointStore(),
+	})
+
+	query := "Transfer $200 from checking to savings, then $5000 from checking to savings, " +
+		"then $3000 from savings to checking, and finally $100 from checking to an account called 'offshore-7'."
+	iter := runner.Query(ctx, query, adk.WithCheckPointID("policy-1"))
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		lastEvent, interrupted := processEvents(iter)
+		if !interrupted {
+			break
+		}
+
+		interruptID := lastEvent.Action.Interrupted.InterruptContexts[0].ID
+
+		var apResult *tool.ApprovalResult
+		for {
+			fmt.Print("Approve? Y = yes, N = no, A = yes and don't ask again for large transfers: ")
+			scanner.Scan()
+			fmt.Println()
+			switch strings.ToUpper(strings.TrimSpace(scanner.Text())) {
+			case "Y":
+				apResult = &tool.ApprovalResult{Approved: true}
+			case "A":
+				apResult = &tool.ApprovalResult{Approved: true, RememberForSession: true}
+			case "N":
+				fmt.Print("Please provide a reason for denial: ")
+				scanner.Scan()
+				reason := scanner.Text()
+				fmt.Println()
+				apResult = &tool.ApprovalResult{Approved: false, DisapproveReason: &reason}
+			default:
+				fmt.Println("invalid input, please input Y, N or A")
+				continue
+			}
+			break
+		}
+
+		var err error
+		iter, err = runner.ResumeWithParams(ctx, "policy-1", &adk.ResumeParams{
+			Targets: map[string]any{
+				interruptID: apResult,
+			},
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func processEvents(iter *adk.AsyncIterator[*adk.AgentEvent]) (*adk.AgentEvent, bool) {
+	var lastEvent *adk.AgentEvent
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if event.Err != nil {
+			log.Fatal(event.Err)
+		}
+
+		prints.Event(event)
+		lastEvent = event
+	}
+
+	if lastEvent == nil {
+		return nil, false
+	}
+	return lastEvent, lastEvent.Action != nil && lastEvent.Action.Interrupted != nil
+}
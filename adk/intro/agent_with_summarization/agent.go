@@ -19,11 +19,14 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/cloudwego/eino/adk"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
 
 	"github.com/cloudwego/eino-examples/adk/common/model"
 	"github.com/cloudwego/eino-examples/adk/common/prints"
@@ -84,10 +87,24 @@ func main() {
 }
 
 func newAgent(ctx context.Context) (adk.Agent, error) {
+	repeatTool := NewRepeatSectionsTool()
+	repeatInfo, err := repeatTool.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	sumMW, err := summarization.New(ctx, &summarization.Config{
 		Model:                      model.NewChatModel(),
 		MaxTokensBeforeSummary:     summaryMaxTokensBefore,
 		MaxTokensForRecentMessages: summaryMaxTokensRecent,
+		// count with the tokenizer of the model in use, falls back to cl100k_base for unknown models
+		// and for families whose tokenizer cannot be loaded
+		Counter: summarization.NewTokenCounter(&summarization.CounterConfig{Model: chatModelName()}),
+		Tools:   []*schema.ToolInfo{repeatInfo},
+		OnCompaction: func(ctx context.Context, e *summarization.CompactionEvent) {
+			log.Printf("[%s] compacted history: %d -> %d tokens, %d -> %d messages, %d evicted",
+				e.Strategy, e.TokensBefore, e.TokensAfter, e.MessagesBefore, e.MessagesAfter, e.EvictedMessages)
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -105,7 +122,7 @@ Each tool call should indicate the call number. After 20 tool calls, produce a f
 		ToolsConfig: adk.ToolsConfig{
 			ToolsNodeConfig: compose.ToolsNodeConfig{
 				Tools: []tool.BaseTool{
-					NewRepeatSectionsTool(),
+					repeatTool,
 				},
 			},
 		},
@@ -116,3 +133,10 @@ Each tool call should indicate the call number. After 20 tool calls, produce a f
 	}
 	return a, nil
 }
+
+func chatModelName() string {
+	if strings.ToLower(os.Getenv("MODEL_TYPE")) == "ark" {
+		return os.Getenv("ARK_MODEL")
+	}
+	return os.Getenv("OPENAI_MODEL")
+}
@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+// CounterConfig configures a TokenCounter created by NewTokenCounter.
+// Optional: everything, the zero value counts like the models of the cl100k_base family.
+type CounterConfig struct {
+	// Tokenizer counts the text of the messages.
+	// Optional. If nil, the tokenizer of the family of Model is used, see FamilyForModel.
+	Tokenizer Tokenizer
+
+	// Model is the name of the chat model, selecting the tokenizer family.
+	// Optional. If empty, FamilyCL100K is used.
+	Model string
+
+	// MessageOverhead is added to every message for its role and the delimiters of the chat format.
+	// Uses 4 when <= 0.
+	MessageOverhead int
+
+	// ImageTokens is counted for every image, except low detail input images which count 85.
+	// Uses 765 when <= 0, the cost of a 512x512 image at high detail for OpenAI models.
+	ImageTokens int
+
+	// FileTokens is counted for every audio, video or file part given by URL. Parts given inline
+	// count one token per 4 bytes of data instead.
+	// Uses 1024 when <= 0.
+	FileTokens int
+}
+
+const lowDetailImageTokens = 85
+
+// NewTokenCounter creates a TokenCounter counting the text, tool calls and media parts of messages.
+// The tokenizer is resolved once, on the first call, and shared through the tokenizer registry;
+// when it cannot be loaded, counting falls back to cl100k_base instead of failing, and the counter
+// keeps the fallback rather than trying to load the tokenizer again on every call.
+func NewTokenCounter(cfg *CounterConfig) TokenCounter {
+	c := &counter{messageOverhead: 4, imageTokens: 765, fileTokens: 1024}
+	if cfg == nil {
+		cfg = &CounterConfig{}
+	}
+	c.tokenizer = cfg.Tokenizer
+	c.family = FamilyCL100K
+	if cfg.Model != "" {
+		c.family = FamilyForModel(cfg.Model)
+	}
+	if cfg.MessageOverhead > 0 {
+		c.messageOverhead = int64(cfg.MessageOverhead)
+	}
+	if cfg.ImageTokens > 0 {
+		c.imageTokens = int64(cfg.ImageTokens)
+	}
+	if cfg.FileTokens > 0 {
+		c.fileTokens = int64(cfg.FileTokens)
+	}
+	return c.count
+}
+
+type counter struct {
+	// tokenizer is resolved by resolveOnce when not configured
+	tokenizer   Tokenizer
+	family      string
+	resolveOnce sync.Once
+
+	messageOverhead int64
+	imageTokens     int64
+	fileTokens      int64
+}
+
+func (c *counter) count(_ context.Context, msgs []adk.Message) ([]int64, error) {
+	c.resolveOnce.Do(func() {
+		if c.tokenizer == nil {
+			c.tokenizer = tokenizerWithFallback(c.family)
+		}
+	})
+	tk := c.tokenizer
+
+	tokenNum := make([]int64, len(msgs))
+	for i, m := range msgs {
+		if m == nil {
+			continue
+		}
+		tokenNum[i] = c.messageOverhead + int64(tk.CountTokens(messageText(m))) + c.mediaTokens(m)
+	}
+	return tokenNum, nil
+}
+
+// fallbackWarned records the families whose load failure was already logged.
+var fallbackWarned sync.Map
+
+// tokenizerWithFallback returns the tokenizer of a family. When it cannot be loaded, for example
+// LLAMA_TOKENIZER_FILE is not set or the BPE ranks cannot be downloaded, it falls back to cl100k_base,
+// and then to an estimate of 4 characters per token, so that counting never fails an agent run.
+func tokenizerWithFallback(family string) Tokenizer {
+	tk, err := TokenizerForFamily(family)
+	if err == nil {
+		return tk
+	}
+	if family != FamilyCL100K {
+		if tk, err2 := TokenizerForFamily(FamilyCL100K); err2 == nil {
+			warnFallback(family, FamilyCL100K, err)
+			return tk
+		}
+	}
+	warnFallback(family, "4 characters per token", err)
+	return approxTokenizer{}
+}
+
+func warnFallback(family, fallback string, err error) {
+	if _, warned := fallbackWarned.LoadOrStore(family, true); !warned {
+		log.Printf("[summarization] %v, counting tokens with %s instead", err, fallback)
+	}
+}
+
+// approxTokenizer estimates 4 characters per token, the last resort when no BPE can be loaded.
+type approxTokenizer struct{}
+
+func (approxTokenizer) CountTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// messageText returns the text of a message as seen by the model: content, text parts and tool calls.
+func messageText(m *schema.Message) string {
+	var sb strings.Builder
+	write := func(s string) {
+		if s != "" {
+			sb.WriteString(s)
+			sb.WriteString("\n")
+		}
+	}
+
+	write(m.Name)
+	write(m.Content)
+	for _, part := range m.UserInputMultiContent {
+		if part.Type == schema.ChatMessagePartTypeText {
+			write(part.Text)
+		}
+	}
+	for _, part := range m.AssistantGenMultiContent {
+		if part.Type == schema.ChatMessagePartTypeText {
+			write(part.Text)
+		}
+	}
+	for _, tc := range m.ToolCalls {
+		write(tc.Function.Name)
+		write(tc.Function.Arguments)
+	}
+	return sb.String()
+}
+
+func (c *counter) mediaTokens(m *schema.Message) int64 {
+	var total int64
+	for _, part := range m.UserInputMultiContent {
+		switch {
+		case part.Image != nil:
+			if part.Image.Detail == schema.ImageURLDetailLow {
+				total += lowDetailImageTokens
+			} else {
+				total += c.imageTokens
+			}
+		case part.Audio != nil:
+			total += c.inlineTokens(part.Audio.Base64Data)
+		case part.Video != nil:
+			total += c.inlineTokens(part.Video.Base64Data)
+		case part.File != nil:
+			total += c.inlineTokens(part.File.Base64Data)
+		}
+	}
+	for _, part := range m.AssistantGenMultiContent {
+		switch {
+		case part.Image != nil:
+			total += c.imageTokens
+		case part.Audio != nil:
+			total += c.inlineTokens(part.Audio.Base64Data)
+		case part.Video != nil:
+			total += c.inlineTokens(part.Video.Base64Data)
+		}
+	}
+	return total
+}
+
+func (c *counter) inlineTokens(base64Data *string) int64 {
+	if base64Data == nil || *base64Data == "" {
+		return c.fileTokens
+	}
+	// 4 base64 characters hold 3 bytes
+	return int64(len(*base64Data)) * 3 / 4 / 4
+}
+
+// toolsMessage renders tool definitions the way they are sent to the model, to count their tokens.
+func toolsMessage(tools []*schema.ToolInfo) (*schema.Message, error) {
+	type toolDef struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Parameters  any    `json:"parameters,omitempty"`
+	}
+	defs := make([]toolDef, 0, len(tools))
+	for _, t := range tools {
+		if t == nil {
+			continue
+		}
+		params, err := t.ParamsOneOf.ToJSONSchema()
+		if err != nil {
+			return nil, err
+		}
+		def := toolDef{Name: t.Name, Description: t.Desc}
+		if params != nil {
+			def.Parameters = params
+		}
+		defs = append(defs, def)
+	}
+	data, err := json.Marshal(defs)
+	if err != nil {
+		return nil, err
+	}
+	return schema.SystemMessage(string(data)), nil
+}
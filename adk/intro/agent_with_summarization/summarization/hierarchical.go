@@ -0,0 +1,167 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+const summaryLevelKey = "_agent_middleware_summary_level"
+
+// HierarchicalConfig configures a HierarchicalStrategy.
+type HierarchicalConfig struct {
+	// Model used to generate the summaries.
+	// Required.
+	Model model.BaseChatModel
+
+	// Fanout is the number of summaries of one level merged into a summary of the next level.
+	// Optional. Defaults to 4, must be at least 2.
+	Fanout int
+
+	// MaxLevels is the number of summary levels. Summaries of the top level are merged into a single
+	// summary of the same level, so the history holds at most MaxLevels*(Fanout-1)+1 summaries.
+	// Optional. Defaults to 3.
+	MaxLevels int
+
+	// ChunkPrompt is the system prompt summarizing the evicted messages.
+	// Optional. If empty, PromptOfChunkSummary is used.
+	ChunkPrompt string
+
+	// MergePrompt is the system prompt merging summaries.
+	// Optional. If empty, PromptOfMergeSummary is used.
+	MergePrompt string
+}
+
+// HierarchicalStrategy summarizes each batch of evicted messages into a level-0 summary,
+// and merges every Fanout summaries of a level into one summary of the next level.
+// Recent history thus stays detailed while older history gets coarser, and each compaction
+// only summarizes the evicted messages plus, from time to time, a few summaries.
+type HierarchicalStrategy struct {
+	chunk     compose.Runnable[map[string]any, *schema.Message]
+	merge     compose.Runnable[map[string]any, *schema.Message]
+	fanout    int
+	maxLevels int
+}
+
+func NewHierarchicalStrategy(ctx context.Context, cfg *HierarchicalConfig) (*HierarchicalStrategy, error) {
+	if cfg == nil || cfg.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	h := &HierarchicalStrategy{fanout: 4, maxLevels: 3}
+	if cfg.Fanout != 0 {
+		if cfg.Fanout < 2 {
+			return nil, fmt.Errorf("fanout must be at least 2, got %d", cfg.Fanout)
+		}
+		h.fanout = cfg.Fanout
+	}
+	if cfg.MaxLevels > 0 {
+		h.maxLevels = cfg.MaxLevels
+	}
+
+	chunkPrompt := cfg.ChunkPrompt
+	if chunkPrompt == "" {
+		chunkPrompt = PromptOfChunkSummary
+	}
+	mergePrompt := cfg.MergePrompt
+	if mergePrompt == "" {
+		mergePrompt = PromptOfMergeSummary
+	}
+
+	var err error
+	h.chunk, err = newSummarizer(ctx, cfg.Model, chunkPrompt, "summarize 'messages': ", "ChunkSummarizer")
+	if err != nil {
+		return nil, err
+	}
+	h.merge, err = newSummarizer(ctx, cfg.Model, mergePrompt, "merge 'summaries': ", "MergeSummarizer")
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (h *HierarchicalStrategy) Name() string {
+	return "hierarchical"
+}
+
+func (h *HierarchicalStrategy) Compact(ctx context.Context, in *CompactionInput) ([]*schema.Message, error) {
+	userMessages := joinMessages(in.UserMessages)
+
+	msg, err := h.chunk.Invoke(ctx, map[string]any{
+		"user_messages": userMessages,
+		"messages":      joinMessages(in.Evicted),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarize failed, err=%w", err)
+	}
+
+	// summaries are ordered oldest first, by non-increasing level,
+	// so the summaries of the lowest level are always at the end
+	summaries := make([]*schema.Message, 0, len(in.Summaries)+1)
+	summaries = append(summaries, in.Summaries...)
+	summaries = append(summaries, newLeveledSummary(msg.Content, 0))
+
+	for level := 0; level < h.maxLevels; level++ {
+		start := len(summaries)
+		for start > 0 && summaryLevel(summaries[start-1]) == level {
+			start--
+		}
+		if len(summaries)-start < h.fanout {
+			break
+		}
+
+		merged, err := h.merge.Invoke(ctx, map[string]any{
+			"user_messages": userMessages,
+			"summaries":     joinMessages(summaries[start:]),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("merge summaries failed, err=%w", err)
+		}
+		next := level + 1
+		if next >= h.maxLevels {
+			next = level
+		}
+		summaries = append(summaries[:start], newLeveledSummary(merged.Content, next))
+	}
+	return summaries, nil
+}
+
+func newLeveledSummary(content string, level int) *schema.Message {
+	msg := NewSummaryMessage(content)
+	msg.Extra[summaryLevelKey] = level
+	return msg
+}
+
+// summaryLevel returns the level of a summary of HierarchicalStrategy, or -1 for summaries
+// left by other strategies, which are never merged.
+func summaryLevel(msg *schema.Message) int {
+	// numbers come back as float64 when the history went through JSON
+	switch v := msg.Extra[summaryLevelKey].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return -1
+	}
+}
@@ -75,3 +75,59 @@ allowing the agent to continue reasoning seamlessly without re-accessing the raw
 {recent_messages}
 </recent_messages>
 </messages>`
+
+// PromptOfChunkSummary is the default prompt of HierarchicalStrategy for summarizing evicted messages.
+const PromptOfChunkSummary = `<role>
+Conversation Summarization Assistant for Multi-turn LLM Agent
+</role>
+
+<primary_objective>
+Summarize a contiguous portion of the conversation history into a concise and accurate summary,
+preserving the reasoning, actions, outcomes and lessons learned, so that the agent can continue
+without re-accessing the raw messages.
+</primary_objective>
+
+<instructions>
+1. You will receive two tagged sections:
+   - The **user_messages tag** — contains the persistent user instructions and goals (for reference only, do not summarize).
+   - The **messages tag** — contains the conversation messages to be summarized.
+2. Respond **only** with the summary, without extra headers, XML tags or meta explanations.
+</instructions>
+
+<messages>
+<user_messages>
+{user_messages}
+</user_messages>
+
+<messages>
+{messages}
+</messages>
+</messages>`
+
+// PromptOfMergeSummary is the default prompt of HierarchicalStrategy for merging summaries into a higher-level one.
+const PromptOfMergeSummary = `<role>
+Conversation Summarization Assistant for Multi-turn LLM Agent
+</role>
+
+<primary_objective>
+Merge consecutive summaries of a conversation, oldest first, into one higher-level summary.
+Keep the decisions, results, open issues and lessons that still matter, and drop the details that
+later summaries superseded.
+</primary_objective>
+
+<instructions>
+1. You will receive two tagged sections:
+   - The **user_messages tag** — contains the persistent user instructions and goals (for reference only, do not summarize).
+   - The **summaries tag** — contains the summaries to merge, oldest first.
+2. Respond **only** with the merged summary, without extra headers, XML tags or meta explanations.
+</instructions>
+
+<messages>
+<user_messages>
+{user_messages}
+</user_messages>
+
+<summaries>
+{summaries}
+</summaries>
+</messages>`
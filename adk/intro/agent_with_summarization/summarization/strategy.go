@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Strategy decides what replaces the messages evicted from the history.
+type Strategy interface {
+	// Name identifies the strategy in CompactionEvent.
+	Name() string
+	// Compact returns the summaries that replace in.Summaries and in.Evicted, oldest first.
+	// Each of them should be created with NewSummaryMessage so that the next compaction finds it.
+	Compact(ctx context.Context, in *CompactionInput) ([]*schema.Message, error)
+}
+
+// CompactionInput is the history seen by a Strategy, split the way the middleware keeps it.
+type CompactionInput struct {
+	// SystemPrompt and UserMessages are the leading system and user messages, always kept.
+	SystemPrompt []*schema.Message
+	UserMessages []*schema.Message
+	// Summaries are the summary messages left by previous compactions.
+	Summaries []*schema.Message
+	// Evicted are the messages leaving the history, oldest first.
+	Evicted []*schema.Message
+	// Recent are the messages kept after the summaries.
+	Recent []*schema.Message
+}
+
+func joinMessages(msgs []*schema.Message) string {
+	var sb strings.Builder
+	for _, m := range msgs {
+		sb.WriteString(renderMsg(m))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func newSummarizer(ctx context.Context, cm model.BaseChatModel, systemPrompt, userPrompt, name string) (compose.Runnable[map[string]any, *schema.Message], error) {
+	tpl := prompt.FromMessages(schema.FString,
+		schema.SystemMessage(systemPrompt),
+		schema.UserMessage(userPrompt))
+
+	summarizer, err := compose.NewChain[map[string]any, *schema.Message]().
+		AppendChatTemplate(tpl).
+		AppendChatModel(cm).
+		Compile(ctx, compose.WithGraphName(name))
+	if err != nil {
+		return nil, fmt.Errorf("compile summarizer failed, err=%w", err)
+	}
+	return summarizer, nil
+}
+
+// RollingConfig configures a RollingStrategy.
+type RollingConfig struct {
+	// Model used to generate the summary.
+	// Required.
+	Model model.BaseChatModel
+
+	// SystemPrompt is the system prompt for the summarizer.
+	// Optional. If empty, PromptOfSummary is used.
+	SystemPrompt string
+}
+
+// RollingStrategy keeps a single summary up to date: each compaction folds only the newly
+// evicted messages into the previous summary, instead of summarizing the whole history again.
+// The summarizer chain is: ChatTemplate(SystemPrompt) -> ChatModel(Model).
+type RollingStrategy struct {
+	summarizer compose.Runnable[map[string]any, *schema.Message]
+}
+
+func NewRollingStrategy(ctx context.Context, cfg *RollingConfig) (*RollingStrategy, error) {
+	if cfg == nil || cfg.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	systemPrompt := cfg.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = PromptOfSummary
+	}
+	summarizer, err := newSummarizer(ctx, cfg.Model, systemPrompt, "summarize 'older_messages': ", "Summarizer")
+	if err != nil {
+		return nil, err
+	}
+	return &RollingStrategy{summarizer: summarizer}, nil
+}
+
+func (r *RollingStrategy) Name() string {
+	return "rolling"
+}
+
+func (r *RollingStrategy) Compact(ctx context.Context, in *CompactionInput) ([]*schema.Message, error) {
+	msg, err := r.summarizer.Invoke(ctx, map[string]any{
+		"system_prompt":    joinMessages(in.SystemPrompt),
+		"user_messages":    joinMessages(in.UserMessages),
+		"previous_summary": joinMessages(in.Summaries),
+		"older_messages":   joinMessages(in.Evicted),
+		"recent_messages":  joinMessages(in.Recent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarize failed, err=%w", err)
+	}
+	return []*schema.Message{NewSummaryMessage(msg.Content)}, nil
+}
+
+// TruncationConfig configures a TruncationStrategy.
+type TruncationConfig struct {
+	// Notice replaces the previous summaries, telling the model that older messages were dropped.
+	// Optional. If empty, the previous summaries are kept as they are.
+	Notice string
+}
+
+// TruncationStrategy drops the evicted messages without calling any model.
+type TruncationStrategy struct {
+	notice string
+}
+
+func NewTruncationStrategy(cfg *TruncationConfig) *TruncationStrategy {
+	t := &TruncationStrategy{}
+	if cfg != nil {
+		t.notice = cfg.Notice
+	}
+	return t
+}
+
+func (t *TruncationStrategy) Name() string {
+	return "truncation"
+}
+
+func (t *TruncationStrategy) Compact(_ context.Context, in *CompactionInput) ([]*schema.Message, error) {
+	if t.notice == "" {
+		return in.Summaries, nil
+	}
+	return []*schema.Message{NewSummaryMessage(t.notice)}, nil
+}
@@ -20,21 +20,19 @@ import (
 	"context"
 	"fmt"
 	"strings"
-
-	"github.com/pkoukk/tiktoken-go"
+	"sync"
 
 	"github.com/cloudwego/eino/adk"
 	"github.com/cloudwego/eino/components/model"
-	"github.com/cloudwego/eino/components/prompt"
-	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
 
 type TokenCounter func(ctx context.Context, msgs []adk.Message) (tokenNum []int64, err error)
 
 // Config defines parameters for the conversation summarization middleware.
-// It controls when summarization is triggered and how much recent context is retained.
-// Required: Model. Optional: SystemPrompt, Counter, and token budgets.
+// It controls when summarization is triggered, how much recent context is retained
+// and how the older part of the history is replaced.
+// Required: Model, unless Strategy is set. Optional: everything else.
 type Config struct {
 	// MaxTokensBeforeSummary is the max token threshold to trigger summarization based on total context
 	// (system prompt + history). Uses DefaultMaxTokensBeforeSummary when <= 0.
@@ -44,32 +42,68 @@ type Config struct {
 	// Uses DefaultMaxTokensForRecentMessages when <= 0.
 	MaxTokensForRecentMessages int
 
-	// Counter custom token counter.
-	// Optional
+	// Counter custom token counter, see NewTokenCounter for counting with the tokenizer of a model.
+	// Optional. Defaults to NewTokenCounter(nil).
 	Counter TokenCounter
 
-	// Model used to generate the summary. Must be provided.
-	// Required.
+	// Tools are the definitions of the tools given to the model, counted towards MaxTokensBeforeSummary.
+	// ChatModelAgentState only carries the messages, so the middleware cannot see them otherwise.
+	// Optional.
+	Tools []*schema.ToolInfo
+
+	// Strategy replaces the evicted messages, see RollingStrategy, HierarchicalStrategy and TruncationStrategy.
+	// Optional. If nil, a RollingStrategy is built from Model and SystemPrompt.
+	Strategy Strategy
+
+	// Model used to generate the summary by the default strategy.
+	// Required when Strategy is nil.
 	Model model.BaseChatModel
 
-	// SystemPrompt is the system prompt for the summarizer.
+	// SystemPrompt is the system prompt for the default strategy.
 	// Optional. If empty, PromptOfSummary is used.
 	SystemPrompt string
+
+	// KeepPinned keeps the messages marked with Pin out of eviction: they stay in the history
+	// verbatim, right after the summaries. A pinned tool call keeps its tool results and vice versa.
+	// Optional.
+	KeepPinned bool
+
+	// OnCompaction is called after every compaction, e.g. to log or export metrics.
+	// Optional.
+	OnCompaction func(ctx context.Context, event *CompactionEvent)
+}
+
+// CompactionEvent reports one compaction of the history.
+type CompactionEvent struct {
+	// Strategy is the Name of the strategy that compacted the history.
+	Strategy string
+
+	TokensBefore   int64
+	TokensAfter    int64
+	MessagesBefore int
+	MessagesAfter  int
+
+	// EvictedMessages and EvictedTokens measure the messages that left the history,
+	// not counting the summaries they were folded into.
+	EvictedMessages int
+	EvictedTokens   int64
+
+	// PinnedMessages is the number of older messages kept because they are pinned.
+	PinnedMessages int
+
+	// SummaryMessages is the number of summary messages in the compacted history.
+	SummaryMessages int
 }
 
-// New creates an AgentMiddleware that compacts long conversation history
-// into a single summary message when the token threshold is exceeded.
-// The summarizer chain is: ChatTemplate(SystemPrompt) -> ChatModel(Model).
+// New creates an AgentMiddleware that compacts long conversation history when the token
+// threshold is exceeded: the newest messages are kept within MaxTokensForRecentMessages,
+// and the Strategy replaces the older ones, e.g. with a summary.
 // It applies defaults for token budgets and allows a custom Counter.
 func New(ctx context.Context, cfg *Config) (adk.AgentMiddleware, error) {
 	if cfg == nil {
 		return adk.AgentMiddleware{}, fmt.Errorf("config is nil")
 	}
 
-	systemPrompt := cfg.SystemPrompt
-	if systemPrompt == "" {
-		systemPrompt = PromptOfSummary
-	}
 	maxBefore := DefaultMaxTokensBeforeSummary
 	if cfg.MaxTokensBeforeSummary > 0 {
 		maxBefore = cfg.MaxTokensBeforeSummary
@@ -79,23 +113,26 @@ func New(ctx context.Context, cfg *Config) (adk.AgentMiddleware, error) {
 		maxRecent = cfg.MaxTokensForRecentMessages
 	}
 
-	tpl := prompt.FromMessages(schema.FString,
-		schema.SystemMessage(systemPrompt),
-		schema.UserMessage("summarize 'older_messages': "))
-
-	summarizer, err := compose.NewChain[map[string]any, *schema.Message]().
-		AppendChatTemplate(tpl).
-		AppendChatModel(cfg.Model).
-		Compile(ctx, compose.WithGraphName("Summarizer"))
-	if err != nil {
-		return adk.AgentMiddleware{}, fmt.Errorf("compile summarizer failed, err=%w", err)
+	strategy := cfg.Strategy
+	if strategy == nil {
+		var err error
+		strategy, err = NewRollingStrategy(ctx, &RollingConfig{
+			Model:        cfg.Model,
+			SystemPrompt: cfg.SystemPrompt,
+		})
+		if err != nil {
+			return adk.AgentMiddleware{}, err
+		}
 	}
 
 	sm := &summaryMiddleware{
-		counter:    defaultCounterToken,
-		maxBefore:  maxBefore,
-		maxRecent:  maxRecent,
-		summarizer: summarizer,
+		counter:      NewTokenCounter(nil),
+		tools:        cfg.Tools,
+		maxBefore:    maxBefore,
+		maxRecent:    maxRecent,
+		strategy:     strategy,
+		keepPinned:   cfg.KeepPinned,
+		onCompaction: cfg.OnCompaction,
 	}
 	if cfg.Counter != nil {
 		sm.counter = cfg.Counter
@@ -105,54 +142,110 @@ func New(ctx context.Context, cfg *Config) (adk.AgentMiddleware, error) {
 
 const summaryMessageFlag = "_agent_middleware_summary_message"
 
+// PinnedExtraKey is the Extra key marking a message as pinned, see Pin.
+const PinnedExtraKey = "_agent_middleware_pinned_message"
+
+// Pin marks msg so that it is never evicted by a middleware created with KeepPinned, and returns it.
+func Pin(msg *schema.Message) *schema.Message {
+	if msg.Extra == nil {
+		msg.Extra = map[string]any{}
+	}
+	msg.Extra[PinnedExtraKey] = true
+	return msg
+}
+
+func IsPinned(msg *schema.Message) bool {
+	if msg == nil {
+		return false
+	}
+	pinned, _ := msg.Extra[PinnedExtraKey].(bool)
+	return pinned
+}
+
+// NewSummaryMessage creates a message recognized as a summary by later compactions.
+// Strategies use it for every message they return.
+func NewSummaryMessage(content string) *schema.Message {
+	msg := schema.AssistantMessage(content, nil)
+	msg.Extra = map[string]any{
+		summaryMessageFlag: true,
+	}
+	return msg
+}
+
+func IsSummary(msg *schema.Message) bool {
+	if msg == nil || msg.Role != schema.Assistant {
+		return false
+	}
+	_, ok := msg.Extra[summaryMessageFlag]
+	return ok
+}
+
 type summaryMiddleware struct {
 	counter   TokenCounter
 	maxBefore int
 	maxRecent int
 
-	summarizer compose.Runnable[map[string]any, *schema.Message]
+	tools       []*schema.ToolInfo
+	toolsMu     sync.Mutex
+	toolsTokens *int64
+
+	strategy     Strategy
+	keepPinned   bool
+	onCompaction func(ctx context.Context, event *CompactionEvent)
 }
 
-func (s *summaryMiddleware) BeforeModel(ctx context.Context, state *adk.ChatModelAgentState) (err error) {
-	if state == nil || len(state.Messages) == 0 {
-		return nil
-	}
+// block is a unit of eviction: a single message, or an assistant tool call with its tool results.
+type block struct {
+	msgs   []*schema.Message
+	tokens int64
+}
 
-	messages := state.Messages
-	msgsToken, err := s.counter(ctx, messages)
-	if err != nil {
-		return fmt.Errorf("count token failed, err=%w", err)
+func (b block) pinned() bool {
+	for _, m := range b.msgs {
+		if IsPinned(m) {
+			return true
+		}
 	}
-	if len(messages) != len(msgsToken) {
-		return fmt.Errorf("token count mismatch, msgNum=%d, tokenCountNum=%d", len(messages), len(msgsToken))
+	return false
+}
+
+func flatten(bs []block) []*schema.Message {
+	var out []*schema.Message
+	for _, b := range bs {
+		out = append(out, b.msgs...)
 	}
+	return out
+}
 
+func sumTokens(bs []block) int64 {
 	var total int64
-	for _, t := range msgsToken {
-		total += t
-	}
-	// Trigger summarization only when exceeding threshold
-	if total <= int64(s.maxBefore) {
-		return nil
+	for _, b := range bs {
+		total += b.tokens
 	}
+	return total
+}
 
-	// Build blocks with user-messages, summary-message, tool-call pairings
-	type block struct {
-		msgs   []*schema.Message
-		tokens int64
-	}
+// history is the layout the middleware maintains:
+// system prompt, leading user messages, summaries, then everything else as blocks.
+type history struct {
+	system    block
+	users     block
+	summaries block
+	body      []block
+}
+
+func splitHistory(messages []*schema.Message, msgsToken []int64) *history {
+	h := &history{}
 	idx := 0
 
-	systemBlock := block{}
 	if idx < len(messages) {
 		m := messages[idx]
 		if m != nil && m.Role == schema.System {
-			systemBlock.msgs = append(systemBlock.msgs, m)
-			systemBlock.tokens += msgsToken[idx]
+			h.system.msgs = append(h.system.msgs, m)
+			h.system.tokens += msgsToken[idx]
 			idx++
 		}
 	}
-	userBlock := block{}
 	for idx < len(messages) {
 		m := messages[idx]
 		if m == nil {
@@ -162,23 +255,16 @@ func (s *summaryMiddleware) BeforeModel(ctx context.Context, state *adk.ChatMode
 		if m.Role != schema.User {
 			break
 		}
-		userBlock.msgs = append(userBlock.msgs, m)
-		userBlock.tokens += msgsToken[idx]
+		h.users.msgs = append(h.users.msgs, m)
+		h.users.tokens += msgsToken[idx]
 		idx++
 	}
-	summaryBlock := block{}
-	if idx < len(messages) {
-		m := messages[idx]
-		if m != nil && m.Role == schema.Assistant {
-			if _, ok := m.Extra[summaryMessageFlag]; ok {
-				summaryBlock.msgs = append(summaryBlock.msgs, m)
-				summaryBlock.tokens += msgsToken[idx]
-				idx++
-			}
-		}
+	for idx < len(messages) && IsSummary(messages[idx]) {
+		h.summaries.msgs = append(h.summaries.msgs, messages[idx])
+		h.summaries.tokens += msgsToken[idx]
+		idx++
 	}
 
-	toolBlocks := make([]block, 0)
 	for i := idx; i < len(messages); i++ {
 		m := messages[i]
 		if m == nil {
@@ -198,84 +284,152 @@ func (s *summaryMiddleware) BeforeModel(ctx context.Context, state *adk.ChatMode
 					break
 				}
 				// Match by ToolCallID when available; if empty, include but keep boundary
-				if nm.ToolCallID == "" {
-					b.msgs = append(b.msgs, nm)
-					b.tokens += msgsToken[j]
-				} else {
+				if nm.ToolCallID != "" {
 					if _, ok := callIDs[nm.ToolCallID]; !ok {
 						// Tool message not belonging to this assistant call -> end pairing
 						break
 					}
-					b.msgs = append(b.msgs, nm)
-					b.tokens += msgsToken[j]
 				}
+				b.msgs = append(b.msgs, nm)
+				b.tokens += msgsToken[j]
 				j++
 			}
-			toolBlocks = append(toolBlocks, b)
+			h.body = append(h.body, b)
 			i = j - 1
 			continue
 		}
-		toolBlocks = append(toolBlocks, block{msgs: []*schema.Message{m}, tokens: msgsToken[i]})
+		h.body = append(h.body, block{msgs: []*schema.Message{m}, tokens: msgsToken[i]})
 	}
+	return h
+}
 
-	// Split into recent and older within token budget, from newest to oldest
-	var recentBlocks []block
-	var olderBlocks []block
+// splitRecent keeps the newest blocks within maxRecent tokens, and always the newest one,
+// so that the model still sees what it has to respond to.
+func (h *history) splitRecent(maxRecent int64) (older, recent []block) {
 	var recentTokens int64
-	for i := len(toolBlocks) - 1; i >= 0; i-- {
-		b := toolBlocks[i]
-		if recentTokens+b.tokens > int64(s.maxRecent) {
-			olderBlocks = append([]block{b}, olderBlocks...)
-			continue
+	i := len(h.body) - 1
+	for ; i >= 0; i-- {
+		b := h.body[i]
+		if i < len(h.body)-1 && recentTokens+b.tokens > maxRecent {
+			break
 		}
-		recentBlocks = append([]block{b}, recentBlocks...)
 		recentTokens += b.tokens
 	}
+	return h.body[:i+1], h.body[i+1:]
+}
 
-	joinBlocks := func(bs []block) string {
-		var sb strings.Builder
-		for _, b := range bs {
-			for _, m := range b.msgs {
-				sb.WriteString(renderMsg(m))
-				sb.WriteString("\n")
-			}
-		}
-		return sb.String()
+func (s *summaryMiddleware) BeforeModel(ctx context.Context, state *adk.ChatModelAgentState) (err error) {
+	if state == nil || len(state.Messages) == 0 {
+		return nil
 	}
 
-	olderText := joinBlocks(olderBlocks)
-	recentText := joinBlocks(recentBlocks)
+	messages := state.Messages
+	msgsToken, err := s.counter(ctx, messages)
+	if err != nil {
+		return fmt.Errorf("count token failed, err=%w", err)
+	}
+	if len(messages) != len(msgsToken) {
+		return fmt.Errorf("token count mismatch, msgNum=%d, tokenCountNum=%d", len(messages), len(msgsToken))
+	}
 
-	msg, err := s.summarizer.Invoke(ctx, map[string]any{
-		"system_prompt":    joinBlocks([]block{systemBlock}),
-		"user_messages":    joinBlocks([]block{userBlock}),
-		"previous_summary": joinBlocks([]block{summaryBlock}),
-		"older_messages":   olderText,
-		"recent_messages":  recentText,
-	})
+	total, err := s.countTools(ctx)
 	if err != nil {
-		return fmt.Errorf("summarize failed, err=%w", err)
+		return err
+	}
+	for _, t := range msgsToken {
+		total += t
+	}
+	// Trigger summarization only when exceeding threshold
+	if total <= int64(s.maxBefore) {
+		return nil
 	}
 
-	summaryMsg := schema.AssistantMessage(msg.Content, nil)
-	msg.Name = "summary"
-	summaryMsg.Extra = map[string]any{
-		summaryMessageFlag: true,
+	h := splitHistory(messages, msgsToken)
+	older, recent := h.splitRecent(int64(s.maxRecent))
+
+	var evicted, pinned []block
+	for _, b := range older {
+		if s.keepPinned && b.pinned() {
+			pinned = append(pinned, b)
+			continue
+		}
+		evicted = append(evicted, b)
+	}
+	if len(evicted) == 0 {
+		return nil
 	}
 
-	// Build new state: prepend summary message, keep recent messages
-	newMessages := make([]*schema.Message, 0, len(messages))
-	newMessages = append(newMessages, systemBlock.msgs...)
-	newMessages = append(newMessages, userBlock.msgs...)
-	newMessages = append(newMessages, summaryMsg)
-	for _, b := range recentBlocks {
-		newMessages = append(newMessages, b.msgs...)
+	summaries, err := s.strategy.Compact(ctx, &CompactionInput{
+		SystemPrompt: h.system.msgs,
+		UserMessages: h.users.msgs,
+		Summaries:    h.summaries.msgs,
+		Evicted:      flatten(evicted),
+		Recent:       flatten(recent),
+	})
+	if err != nil {
+		return fmt.Errorf("compact with strategy %s failed, err=%w", s.strategy.Name(), err)
 	}
 
+	// Build new state: system prompt and user messages, summaries, pinned and recent messages
+	newMessages := make([]*schema.Message, 0, len(messages))
+	newMessages = append(newMessages, h.system.msgs...)
+	newMessages = append(newMessages, h.users.msgs...)
+	newMessages = append(newMessages, summaries...)
+	newMessages = append(newMessages, flatten(pinned)...)
+	newMessages = append(newMessages, flatten(recent)...)
 	state.Messages = newMessages
+
+	if s.onCompaction == nil {
+		return nil
+	}
+	summaryTokens, err := s.counter(ctx, summaries)
+	if err != nil {
+		return fmt.Errorf("count token failed, err=%w", err)
+	}
+	after := total - h.summaries.tokens - sumTokens(evicted)
+	for _, t := range summaryTokens {
+		after += t
+	}
+	s.onCompaction(ctx, &CompactionEvent{
+		Strategy:        s.strategy.Name(),
+		TokensBefore:    total,
+		TokensAfter:     after,
+		MessagesBefore:  len(messages),
+		MessagesAfter:   len(newMessages),
+		EvictedMessages: len(flatten(evicted)),
+		EvictedTokens:   sumTokens(evicted),
+		PinnedMessages:  len(flatten(pinned)),
+		SummaryMessages: len(summaries),
+	})
 	return nil
 }
 
+// countTools counts the tool definitions once, they do not change between model calls.
+func (s *summaryMiddleware) countTools(ctx context.Context) (int64, error) {
+	if len(s.tools) == 0 {
+		return 0, nil
+	}
+	s.toolsMu.Lock()
+	defer s.toolsMu.Unlock()
+	if s.toolsTokens != nil {
+		return *s.toolsTokens, nil
+	}
+
+	msg, err := toolsMessage(s.tools)
+	if err != nil {
+		return 0, fmt.Errorf("render tools failed, err=%w", err)
+	}
+	tokens, err := s.counter(ctx, []adk.Message{msg})
+	if err != nil {
+		return 0, fmt.Errorf("count token failed, err=%w", err)
+	}
+	if len(tokens) != 1 {
+		return 0, fmt.Errorf("token count mismatch, msgNum=1, tokenCountNum=%d", len(tokens))
+	}
+	s.toolsTokens = &tokens[0]
+	return tokens[0], nil
+}
+
 // Render messages into strings
 func renderMsg(m *schema.Message) string {
 	if m == nil {
@@ -327,77 +481,3 @@ func renderMsg(m *schema.Message) string {
 	}
 	return sb.String()
 }
-
-func defaultCounterToken(ctx context.Context, msgs []adk.Message) (tokenNum []int64, err error) {
-	encoding := "cl100k_base"
-	tkt, err := tiktoken.GetEncoding(encoding)
-	if err != nil {
-		return nil, fmt.Errorf("get encoding failed, encoding=%v, err=%w", encoding, err)
-	}
-	tokenNum = make([]int64, len(msgs))
-
-	for i, m := range msgs {
-		if m == nil {
-			tokenNum[i] = 0
-			continue
-		}
-
-		var sb strings.Builder
-
-		// Message role contributes to chat tokenization overhead; include it as text.
-		if m.Role != "" {
-			sb.WriteString(string(m.Role))
-			sb.WriteString("\n")
-		}
-
-		// Core text content
-		if m.Content != "" {
-			sb.WriteString(m.Content)
-			sb.WriteString("\n")
-		}
-
-		// Reasoning content if present
-		// Reasoning Content is not used by model
-		// if m.ReasoningContent != "" {
-		//     sb.WriteString(m.ReasoningContent)
-		//     sb.WriteString("\n")
-		// }
-
-		// Multi modal input/output text parts
-		for _, part := range m.UserInputMultiContent {
-			if part.Type == schema.ChatMessagePartTypeText && part.Text != "" {
-				sb.WriteString(part.Text)
-				sb.WriteString("\n")
-			}
-		}
-		for _, part := range m.AssistantGenMultiContent {
-			if part.Type == schema.ChatMessagePartTypeText && part.Text != "" {
-				sb.WriteString(part.Text)
-				sb.WriteString("\n")
-			}
-		}
-
-		// Tool call textual context (name + arguments)
-		for _, tc := range m.ToolCalls {
-			if tc.Function.Name != "" {
-				sb.WriteString(tc.Function.Name)
-				sb.WriteString("\n")
-			}
-			if tc.Function.Arguments != "" {
-				sb.WriteString(tc.Function.Arguments)
-				sb.WriteString("\n")
-			}
-		}
-
-		text := sb.String()
-		if text == "" {
-			tokenNum[i] = 0
-			continue
-		}
-
-		tokens := tkt.Encode(text, nil, nil)
-		tokenNum[i] = int64(len(tokens))
-	}
-
-	return tokenNum, nil
-}
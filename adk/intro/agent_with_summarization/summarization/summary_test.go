@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+// countChars counts one token per byte of content, which keeps budgets easy to reason about.
+func countChars(_ context.Context, msgs []adk.Message) ([]int64, error) {
+	out := make([]int64, len(msgs))
+	for i, m := range msgs {
+		out[i] = int64(len(m.Content))
+	}
+	return out, nil
+}
+
+func newTestMiddleware(t *testing.T, keepPinned bool, events *[]*CompactionEvent) adk.AgentMiddleware {
+	mw, err := New(context.Background(), &Config{
+		MaxTokensBeforeSummary:     30,
+		MaxTokensForRecentMessages: 10,
+		Counter:                    countChars,
+		Strategy:                   NewTruncationStrategy(&TruncationConfig{Notice: "older messages were dropped"}),
+		KeepPinned:                 keepPinned,
+		OnCompaction: func(_ context.Context, e *CompactionEvent) {
+			*events = append(*events, e)
+		},
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	return mw
+}
+
+func contents(msgs []*schema.Message) []string {
+	out := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, m.Content)
+	}
+	return out
+}
+
+func TestBeforeModel_Truncation(t *testing.T) {
+	var events []*CompactionEvent
+	mw := newTestMiddleware(t, false, &events)
+
+	state := &adk.ChatModelAgentState{Messages: []*schema.Message{
+		schema.SystemMessage("sys"),
+		schema.UserMessage("task"),
+		schema.AssistantMessage("0123456789", nil),
+		schema.AssistantMessage("abcdefghij", []schema.ToolCall{{ID: "c1"}}),
+		schema.ToolMessage("done", "c1"),
+		schema.AssistantMessage("final", nil),
+	}}
+	if err := mw.BeforeChatModel(context.Background(), state); err != nil {
+		t.Fatalf("before model: %v", err)
+	}
+
+	got := contents(state.Messages)
+	want := []string{"sys", "task", "older messages were dropped", "final"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected history: %q", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected history: %q", got)
+		}
+	}
+	if !IsSummary(state.Messages[2]) {
+		t.Fatalf("the notice should be a summary message")
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected one compaction event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Strategy != "truncation" || e.TokensBefore != 36 || e.TokensAfter != 39 ||
+		e.MessagesBefore != 6 || e.MessagesAfter != 4 || e.EvictedMessages != 3 || e.EvictedTokens != 24 {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	// under the threshold, nothing happens
+	events = nil
+	if err := mw.BeforeChatModel(context.Background(), state); err != nil {
+		t.Fatalf("before model: %v", err)
+	}
+	if len(events) != 0 || len(state.Messages) != 4 {
+		t.Fatalf("history under the threshold must not be compacted")
+	}
+}
+
+func TestBeforeModel_KeepPinned(t *testing.T) {
+	var events []*CompactionEvent
+	mw := newTestMiddleware(t, true, &events)
+
+	state := &adk.ChatModelAgentState{Messages: []*schema.Message{
+		schema.UserMessage("task"),
+		Pin(schema.AssistantMessage("pinned-fact", nil)),
+		schema.AssistantMessage("0123456789", nil),
+		schema.AssistantMessage("abcdefghij", nil),
+		schema.AssistantMessage("final", nil),
+	}}
+	if err := mw.BeforeChatModel(context.Background(), state); err != nil {
+		t.Fatalf("before model: %v", err)
+	}
+
+	got := contents(state.Messages)
+	want := []string{"task", "older messages were dropped", "pinned-fact", "final"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected history: %q", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected history: %q", got)
+		}
+	}
+	if len(events) != 1 || events[0].PinnedMessages != 1 || events[0].EvictedMessages != 2 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
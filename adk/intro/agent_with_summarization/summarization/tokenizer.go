@@ -0,0 +1,298 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// Tokenizer counts the tokens of a text for one model family.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// Built-in tokenizer families.
+const (
+	FamilyO200K  = "o200k"
+	FamilyCL100K = "cl100k"
+	FamilyLlama  = "llama"
+	FamilyQwen   = "qwen"
+)
+
+type family struct {
+	name     string
+	prefixes []string
+	load     func() (Tokenizer, error)
+}
+
+// registry maps model names to tokenizer families, and caches the loaded tokenizers:
+// building a BPE from its ranks takes far longer than counting a whole conversation.
+type registry struct {
+	mu         sync.Mutex
+	families   []*family
+	tokenizers map[string]Tokenizer
+}
+
+var tokenizers = &registry{tokenizers: map[string]Tokenizer{}}
+
+func init() {
+	RegisterTokenizer(FamilyO200K, []string{"gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4", "chatgpt-4o"},
+		func() (Tokenizer, error) { return loadTiktoken(tiktoken.MODEL_O200K_BASE) })
+	RegisterTokenizer(FamilyCL100K, []string{"gpt-4", "gpt-3.5", "text-embedding-3", "text-embedding-ada"},
+		func() (Tokenizer, error) { return loadTiktoken(tiktoken.MODEL_CL100K_BASE) })
+
+	// Llama and Qwen publish their tokenizers with the weights, they are read from local files
+	RegisterTokenizer(FamilyLlama, []string{"llama"}, func() (Tokenizer, error) {
+		return loadTokenizerFileFromEnv("LLAMA_TOKENIZER_FILE")
+	})
+	RegisterTokenizer(FamilyQwen, []string{"qwen"}, func() (Tokenizer, error) {
+		return loadTokenizerFileFromEnv("QWEN_TOKENIZER_FILE")
+	})
+}
+
+// RegisterTokenizer registers a tokenizer family, used by the models whose name starts with one of
+// prefixes, case-insensitively. When several prefixes match, the longest wins. Registering an
+// existing family replaces it. load is called once, the first time a model of the family is counted.
+func RegisterTokenizer(name string, prefixes []string, load func() (Tokenizer, error)) {
+	lowered := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		lowered = append(lowered, strings.ToLower(p))
+	}
+
+	r := tokenizers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tokenizers, name)
+	f := &family{name: name, prefixes: lowered, load: load}
+	for i, existing := range r.families {
+		if existing.name == name {
+			r.families[i] = f
+			return
+		}
+	}
+	r.families = append(r.families, f)
+}
+
+// RegisterTokenizerFile registers a family whose tokenizer is read from a local file, see LoadTokenizerFile.
+func RegisterTokenizerFile(name string, prefixes []string, path string) {
+	RegisterTokenizer(name, prefixes, func() (Tokenizer, error) {
+		return LoadTokenizerFile(path)
+	})
+}
+
+// FamilyForModel returns the tokenizer family of a model, or FamilyCL100K when no prefix matches.
+// The provider part of names like "openai/gpt-4o" is ignored.
+func FamilyForModel(model string) string {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	r := tokenizers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	best, bestLen := FamilyCL100K, 0
+	for _, f := range r.families {
+		for _, p := range f.prefixes {
+			if len(p) > bestLen && strings.HasPrefix(name, p) {
+				best, bestLen = f.name, len(p)
+			}
+		}
+	}
+	return best
+}
+
+// TokenizerForFamily returns the cached tokenizer of a family, loading it on first use.
+// A failed load is not cached, the next call tries again.
+func TokenizerForFamily(name string) (Tokenizer, error) {
+	r := tokenizers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tk, ok := r.tokenizers[name]; ok {
+		return tk, nil
+	}
+	for _, f := range r.families {
+		if f.name != name {
+			continue
+		}
+		// loading under the lock keeps concurrent first calls from loading the same ranks twice
+		tk, err := f.load()
+		if err != nil {
+			return nil, fmt.Errorf("load tokenizer of family %s failed, err=%w", name, err)
+		}
+		r.tokenizers[name] = tk
+		return tk, nil
+	}
+	return nil, fmt.Errorf("unknown tokenizer family %s", name)
+}
+
+// TokenizerForModel returns the cached tokenizer of the family of model.
+func TokenizerForModel(model string) (Tokenizer, error) {
+	return TokenizerForFamily(FamilyForModel(model))
+}
+
+type bpeTokenizer struct {
+	tkt *tiktoken.Tiktoken
+}
+
+func (b *bpeTokenizer) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	// special tokens in the text are counted as plain text, instead of panicking
+	return len(b.tkt.EncodeOrdinary(text))
+}
+
+func loadTiktoken(encoding string) (Tokenizer, error) {
+	tkt, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &bpeTokenizer{tkt: tkt}, nil
+}
+
+// llamaPattern is the pre-tokenization pattern of Llama 3, the same as cl100k_base.
+const llamaPattern = `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+`
+
+func loadTokenizerFileFromEnv(env string) (Tokenizer, error) {
+	path := os.Getenv(env)
+	if path == "" {
+		return nil, fmt.Errorf("%s is not set, it should point to the tokenizer.json or tokenizer.model of the model", env)
+	}
+	return LoadTokenizerFile(path)
+}
+
+// LoadTokenizerFile loads a byte-level BPE tokenizer from a local file, either:
+//   - a Hugging Face tokenizer.json with a byte-level BPE model, as shipped with Qwen2 and Llama 3;
+//   - a tiktoken rank file ("<base64 token> <rank>" per line), as the tokenizer.model of Llama 3
+//     or the qwen.tiktoken of Qwen.
+//
+// SentencePiece models, like the tokenizer.model of Llama 2, are not supported.
+func LoadTokenizerFile(path string) (Tokenizer, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return loadHFTokenizer(path)
+	}
+
+	ranks, err := tiktoken.NewDefaultBpeLoader().LoadTiktokenBpe(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tokenizer file %s failed, err=%w", path, err)
+	}
+	return newBPETokenizer(filepath.Base(path), ranks, llamaPattern)
+}
+
+func newBPETokenizer(name string, ranks map[string]int, pattern string) (Tokenizer, error) {
+	bpe, err := tiktoken.NewCoreBPE(ranks, map[string]int{}, pattern)
+	if err != nil {
+		return nil, err
+	}
+	enc := &tiktoken.Encoding{Name: name, PatStr: pattern, MergeableRanks: ranks, SpecialTokens: map[string]int{}}
+	return &bpeTokenizer{tkt: tiktoken.NewTiktoken(bpe, enc, map[string]any{})}, nil
+}
+
+type hfTokenizer struct {
+	Model struct {
+		Type  string         `json:"type"`
+		Vocab map[string]int `json:"vocab"`
+	} `json:"model"`
+	PreTokenizer json.RawMessage `json:"pre_tokenizer"`
+}
+
+// loadHFTokenizer converts the vocabulary of a byte-level BPE tokenizer.json into tiktoken ranks.
+// Byte-level BPE vocabularies are ordered by merge, so token IDs work as ranks.
+func loadHFTokenizer(path string) (Tokenizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tokenizer file %s failed, err=%w", path, err)
+	}
+	var hf hfTokenizer
+	if err = json.Unmarshal(data, &hf); err != nil {
+		return nil, fmt.Errorf("decode tokenizer file %s failed, err=%w", path, err)
+	}
+	if hf.Model.Type != "BPE" || len(hf.Model.Vocab) == 0 {
+		return nil, fmt.Errorf("tokenizer file %s has no BPE model", path)
+	}
+
+	decode := byteLevelDecoder()
+	ranks := make(map[string]int, len(hf.Model.Vocab))
+	for token, id := range hf.Model.Vocab {
+		raw := make([]byte, 0, len(token))
+		for _, r := range token {
+			b, ok := decode[r]
+			if !ok {
+				return nil, fmt.Errorf("tokenizer file %s is not a byte-level BPE, token %q", path, token)
+			}
+			raw = append(raw, b)
+		}
+		ranks[string(raw)] = id
+	}
+
+	pattern := findSplitPattern(hf.PreTokenizer)
+	if pattern == "" {
+		pattern = llamaPattern
+	}
+	return newBPETokenizer(filepath.Base(path), ranks, pattern)
+}
+
+// byteLevelDecoder inverts the byte-to-unicode mapping of GPT-2 byte-level BPE,
+// which maps each byte to a printable rune so that vocabularies can be stored as text.
+func byteLevelDecoder() map[rune]byte {
+	decode := make(map[rune]byte, 256)
+	n := 0
+	for b := 0; b < 256; b++ {
+		printable := (b >= '!' && b <= '~') || (b >= 0xA1 && b <= 0xAC) || (b >= 0xAE && b <= 0xFF)
+		if printable {
+			decode[rune(b)] = byte(b)
+			continue
+		}
+		decode[rune(256+n)] = byte(b)
+		n++
+	}
+	return decode
+}
+
+// findSplitPattern returns the first regex of a Split pre-tokenizer, looking into Sequence pre-tokenizers.
+func findSplitPattern(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var node struct {
+		Type    string `json:"type"`
+		Pattern struct {
+			Regex string `json:"Regex"`
+		} `json:"pattern"`
+		PreTokenizers []json.RawMessage `json:"pretokenizers"`
+	}
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return ""
+	}
+	if node.Type == "Split" && node.Pattern.Regex != "" {
+		return node.Pattern.Regex
+	}
+	for _, child := range node.PreTokenizers {
+		if p := findSplitPattern(child); p != "" {
+			return p
+		}
+	}
+	return ""
+}
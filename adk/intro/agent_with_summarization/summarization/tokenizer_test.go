@@ -0,0 +1,120 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package summarization
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestLoadTokenizerFile_HuggingFace(t *testing.T) {
+	vocab := map[string]int{}
+	for r, b := range byteLevelDecoder() {
+		vocab[string(r)] = int(b)
+	}
+	vocab["ab"] = 256
+	vocab["Ġab"] = 257 // "Ġ" is the byte-level rune of the space
+
+	data, err := json.Marshal(map[string]any{
+		"model": map[string]any{"type": "BPE", "vocab": vocab},
+		"pre_tokenizer": map[string]any{"type": "Sequence", "pretokenizers": []any{
+			map[string]any{"type": "Split", "pattern": map[string]any{"Regex": `\s?\p{L}+|\s+`}},
+			map[string]any{"type": "ByteLevel"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "tokenizer.json")
+	if err = os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	tk, err := LoadTokenizerFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	// "ab" + " ab" + " c" as "Ġ", "c"
+	if n := tk.CountTokens("ab ab c"); n != 4 {
+		t.Fatalf("expected 4 tokens, got %d", n)
+	}
+}
+
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestNewTokenCounter(t *testing.T) {
+	counter := NewTokenCounter(&CounterConfig{Tokenizer: wordTokenizer{}, MessageOverhead: 1, ImageTokens: 100})
+
+	image := &schema.Message{Role: schema.User, UserInputMultiContent: []schema.MessageInputPart{
+		{Type: schema.ChatMessagePartTypeText, Text: "what is this"},
+		{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{}},
+		{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{Detail: schema.ImageURLDetailLow}},
+	}}
+	call := schema.AssistantMessage("", []schema.ToolCall{{ID: "c1", Function: schema.FunctionCall{Name: "search", Arguments: `{"q": "go"}`}}})
+
+	got, err := counter(context.Background(), []adk.Message{schema.UserMessage("hello world"), image, call})
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	want := []int64{1 + 2, 1 + 3 + 100 + lowDetailImageTokens, 1 + 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected counts %v, want %v", got, want)
+		}
+	}
+}
+
+func TestFamilyForModel(t *testing.T) {
+	cases := map[string]string{
+		"gpt-4o-mini":                FamilyO200K,
+		"openai/gpt-4.1":             FamilyO200K,
+		"gpt-4-turbo":                FamilyCL100K,
+		"meta-llama/Llama-3.1-8B":    FamilyLlama,
+		"Qwen/Qwen2.5-72B-Instruct":  FamilyQwen,
+		"some-model-nobody-heard-of": FamilyCL100K,
+	}
+	for model, want := range cases {
+		if got := FamilyForModel(model); got != want {
+			t.Fatalf("FamilyForModel(%q) = %s, want %s", model, got, want)
+		}
+	}
+}
+
+func TestNewTokenCounter_FallbackWithoutTokenizerFile(t *testing.T) {
+	t.Setenv("LLAMA_TOKENIZER_FILE", "")
+
+	// falls back to cl100k_base, or to the estimate when its ranks cannot be downloaded
+	counter := NewTokenCounter(&CounterConfig{Model: "llama-3.1-8b", MessageOverhead: 1})
+	got, err := counter(context.Background(), []adk.Message{schema.UserMessage("hello world, how are you")})
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if got[0] <= 1 {
+		t.Fatalf("expected the text to be counted, got %v", got)
+	}
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package events holds the handling of agent events shared by the endpoints of the service.
+package events
+
+import (
+	"github.com/cloudwego/eino/adk"
+)
+
+// Drain consumes the rest of iter once nobody reads it anymore, closing the message
+// stream of every event, so that the goroutines of the agent producing them can exit.
+// The run must have been cancelled first, otherwise draining waits for the agent to finish.
+func Drain(iter *adk.AsyncIterator[*adk.AgentEvent]) {
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			return
+		}
+		CloseStream(event)
+	}
+}
+
+// CloseStream closes the message stream of event, if any, when it is not read.
+func CloseStream(event *adk.AgentEvent) {
+	if event.Output != nil && event.Output.MessageOutput != nil && event.Output.MessageOutput.MessageStream != nil {
+		event.Output.MessageOutput.MessageStream.Close()
+	}
+}
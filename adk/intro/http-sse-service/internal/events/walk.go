@@ -0,0 +1,100 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package events
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Handler receives the parts of an agent event, in the order Walk visits them. A non-nil error
+// returned by a method stops the walk and is returned by Walk.
+type Handler interface {
+	// OnError receives the error of the event, or the error a message stream failed with.
+	OnError(event *adk.AgentEvent, err error) error
+	// OnMessage receives a message output that is not streamed.
+	OnMessage(event *adk.AgentEvent, msg *schema.Message) error
+	// OnChunk receives every chunk of a message stream, then OnMessageEnd is called once it ended.
+	OnChunk(event *adk.AgentEvent, chunk *schema.Message) error
+	OnMessageEnd(event *adk.AgentEvent) error
+	OnTransfer(event *adk.AgentEvent, destAgentName string) error
+	// OnInterrupted receives all the pending interrupts of the run at once.
+	OnInterrupted(event *adk.AgentEvent, contexts []*adk.InterruptCtx) error
+	OnExit(event *adk.AgentEvent) error
+}
+
+// Walk passes event to h: its error, if any, or its message or message stream, then its action.
+// A message stream is read to its end and closed.
+func Walk(event *adk.AgentEvent, h Handler) error {
+	if event.Err != nil {
+		return h.OnError(event, event.Err)
+	}
+
+	if event.Output != nil && event.Output.MessageOutput != nil {
+		if err := walkMessage(event, h); err != nil {
+			return err
+		}
+	}
+
+	action := event.Action
+	if action == nil {
+		return nil
+	}
+	if action.TransferToAgent != nil {
+		return h.OnTransfer(event, action.TransferToAgent.DestAgentName)
+	}
+	if action.Interrupted != nil {
+		if err := h.OnInterrupted(event, action.Interrupted.InterruptContexts); err != nil {
+			return err
+		}
+	}
+	if action.Exit {
+		return h.OnExit(event)
+	}
+	return nil
+}
+
+func walkMessage(event *adk.AgentEvent, h Handler) error {
+	mo := event.Output.MessageOutput
+
+	if msg := mo.Message; msg != nil {
+		return h.OnMessage(event, msg)
+	}
+
+	stream := mo.MessageStream
+	if stream == nil {
+		return nil
+	}
+	// closes the stream early if the handler stops in the middle of it
+	defer stream.Close()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return h.OnMessageEnd(event)
+		}
+		if err != nil {
+			return h.OnError(event, fmt.Errorf("stream error: %w", err))
+		}
+		if err = h.OnChunk(event, chunk); err != nil {
+			return err
+		}
+	}
+}
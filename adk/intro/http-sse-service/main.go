@@ -19,20 +19,25 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
 	"log"
+	"strings"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/google/uuid"
 	"github.com/hertz-contrib/sse"
 
 	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/cloudwego/eino-examples/adk/common/model"
+	"github.com/cloudwego/eino-examples/adk/common/store"
+	"github.com/cloudwego/eino-examples/adk/intro/agent_with_summarization/summarization"
+	"github.com/cloudwego/eino-examples/adk/intro/http-sse-service/internal/events"
+	"github.com/cloudwego/eino-examples/adk/intro/http-sse-service/openaicompat"
 )
 
 type SSEEvent struct {
@@ -43,41 +48,107 @@ type SSEEvent struct {
 	ToolCalls  []schema.ToolCall `json:"tool_calls,omitempty"`
 	ActionType string            `json:"action_type,omitempty"`
 	Error      string            `json:"error,omitempty"`
+
+	// CheckPointID and Interrupts are set on "interrupted" actions; they are what POST /resume needs.
+	CheckPointID string             `json:"checkpoint_id,omitempty"`
+	Interrupts   []InterruptPayload `json:"interrupts,omitempty"`
 }
 
+// summarization thresholds for session history, kept low so that the compaction is easy to observe
+const (
+	summaryMaxTokensBefore = 8 * 1024
+	summaryMaxTokensRecent = 2 * 1024
+)
+
 func main() {
 	ctx := context.Background()
 
+	sumMW, err := summarization.New(ctx, &summarization.Config{
+		Model:                      model.NewChatModel(),
+		MaxTokensBeforeSummary:     summaryMaxTokensBefore,
+		MaxTokensForRecentMessages: summaryMaxTokensRecent,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create summarization middleware: %v", err)
+	}
+
 	agent, err := createAgent(ctx)
 	if err != nil {
 		log.Fatalf("Failed to create agent: %v", err)
 	}
 
+	// use CHECKPOINT_STORE=file or redis to keep interrupted runs resumable across restarts
 	runner := adk.NewRunner(ctx, adk.RunnerConfig{
 		EnableStreaming: true,
 		Agent:           agent,
+		CheckPointStore: store.NewCheckPointStore(),
 	})
+	runs := newRunRegistry()
+	// use SESSION_STORE=file to keep conversations across restarts,
+	// their history is summarized when it is loaded for a new turn
+	sessions := &sessionManager{store: newSessionStore(), compact: sumMW}
+
+	// the same runner behind an OpenAI-compatible Chat Completions API
+	oai, err := openaicompat.New(&openaicompat.Config{
+		Agents:       map[string]*adk.Runner{"sse-agent": runner},
+		DefaultModel: "sse-agent",
+		DescribeInterrupts: func(contexts []*adk.InterruptCtx) any {
+			return interruptPayloads(contexts)
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create OpenAI-compatible server: %v", err)
+	}
 
-	h := server.Default(server.WithHostPorts(":8080"))
+	// cancel the request context when the client disconnects, so that runs stop with it
+	h := server.Default(server.WithHostPorts(":8080"), server.WithSenseClientDisconnection(true))
 
 	h.GET("/chat", func(ctx context.Context, c *app.RequestContext) {
-		handleChat(ctx, c, runner)
+		handleChat(ctx, c, runner, runs, sessions)
+	})
+	h.POST("/resume", func(ctx context.Context, c *app.RequestContext) {
+		handleResume(ctx, c, runner, runs, sessions)
+	})
+	h.DELETE("/runs/:id", func(ctx context.Context, c *app.RequestContext) {
+		handleCancelRun(ctx, c, runs)
+	})
+	h.GET("/sessions", func(ctx context.Context, c *app.RequestContext) {
+		handleListSessions(ctx, c, sessions)
 	})
+	h.GET("/sessions/:id", func(ctx context.Context, c *app.RequestContext) {
+		handleGetSession(ctx, c, sessions)
+	})
+	h.DELETE("/sessions/:id", func(ctx context.Context, c *app.RequestContext) {
+		handleDeleteSession(ctx, c, sessions, runs)
+	})
+
+	oai.BindRoutes(h.Group("/v1"))
 
 	log.Println("Server starting on http://localhost:8080")
 	log.Println("Try: curl -N 'http://localhost:8080/chat?query=tell me a short story'")
+	log.Println("Or a multi-turn session: curl -N 'http://localhost:8080/chat?session_id=demo&query=my name is Bob'")
 	h.Spin()
 }
 
 func createAgent(ctx context.Context) (adk.Agent, error) {
+	tools, err := createTools()
+	if err != nil {
+		return nil, err
+	}
+
 	// add sub-agents if you want to.
 	// for demonstration purpose we use a simple ChatModelAgent
 	return adk.NewChatModelAgent(ctx, &adk.ChatModelAgentConfig{
 		Name:        "SSEAgent",
 		Description: "An agent that responds via Server-Sent Events",
-		Instruction: `You are a helpful assistant. Provide clear and concise responses to user queries.`,
-		Model:       model.NewChatModel(),
-		// add tools if you want to
+		Instruction: `You are a helpful assistant. Provide clear and concise responses to user queries.
+You can send emails and create calendar events for the user. If a request lacks information, ask the user with FollowUpTool.`,
+		Model: model.NewChatModel(),
+		ToolsConfig: adk.ToolsConfig{
+			ToolsNodeConfig: compose.ToolsNodeConfig{
+				Tools: tools,
+			},
+		},
 	})
 }
 
@@ -85,7 +156,7 @@ func formatRunPath(runPath []adk.RunStep) string {
 	return fmt.Sprintf("%v", runPath)
 }
 
-func handleChat(ctx context.Context, c *app.RequestContext, runner *adk.Runner) {
+func handleChat(ctx context.Context, c *app.RequestContext, runner *adk.Runner, runs *runRegistry, sessions *sessionManager) {
 	query := c.Query("query")
 	if query == "" {
 		c.JSON(consts.StatusBadRequest, map[string]string{
@@ -94,204 +165,256 @@ func handleChat(ctx context.Context, c *app.RequestContext, runner *adk.Runner)
 		return
 	}
 
+	// every run is checkpointed so that an interrupt can be resumed through POST /resume
+	checkPointID := c.Query("checkpoint_id")
+	if checkPointID == "" {
+		checkPointID = uuid.New().String()
+	}
+	// the run stops when the client disconnects or when DELETE /runs/{checkpoint_id} cancels it
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if !runs.acquire(checkPointID, cancel) {
+		c.JSON(consts.StatusConflict, map[string]string{
+			"error": fmt.Sprintf("checkpoint '%s' is already running", checkPointID),
+		})
+		return
+	}
+	defer runs.release(checkPointID)
+
 	log.Printf("Received query: %s", query)
 
-	iter := runner.Query(ctx, query)
+	// without a session every query is a fresh conversation
+	sessionID := c.Query("session_id")
+	if sessionID == "" {
+		iter := runner.Query(runCtx, query, adk.WithCheckPointID(checkPointID))
+		s := sse.NewStream(c)
+		defer flushSSE(c)
+		streamEvents(runCtx, cancel, s, checkPointID, iter, nil)
+		return
+	}
+
+	if err := checkSessionID(sessionID); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if !runs.acquire(sessionLockKey(sessionID), nil) {
+		c.JSON(consts.StatusConflict, map[string]string{
+			"error": fmt.Sprintf("session '%s' is already running", sessionID),
+		})
+		return
+	}
+	defer runs.release(sessionLockKey(sessionID))
+
+	session, err := sessions.load(ctx, sessionID)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{
+			"error": fmt.Sprintf("failed to load session: %v", err),
+		})
+		return
+	}
+
+	userMsg := schema.UserMessage(query)
+	messages := append(append([]adk.Message{}, session.Messages...), userMsg)
+	iter := runner.Run(runCtx, messages, adk.WithCheckPointID(checkPointID))
 
 	s := sse.NewStream(c)
-	defer func(c *app.RequestContext) {
-		_ = c.Flush()
-	}(c)
+	defer flushSSE(c)
+	rec := &transcript{}
+	// a failed or cancelled turn is left out of the session, the client may ask again
+	if !streamEvents(runCtx, cancel, s, checkPointID, iter, rec) {
+		return
+	}
+	if err = sessions.appendTurn(ctx, session, append([]*schema.Message{userMsg}, rec.messages...)...); err != nil {
+		reportSaveError(s, err)
+	}
+}
 
+func flushSSE(c *app.RequestContext) {
+	_ = c.Flush()
+}
+
+// reportSaveError ends the stream with an error event when the turn could not be added to its session.
+func reportSaveError(s *sse.Stream, err error) {
+	log.Printf("%v", err)
+	_ = sendSSEEvent(s, SSEEvent{Type: "error", Error: err.Error()})
+}
+
+// streamEvents forwards every event of iter to the client through s. When rec is not nil,
+// it also records the final answers so that they can be added to a session. It reports whether
+// the run completed or was interrupted, rather than failed or was cancelled.
+//
+// ctx is the context of the run and cancel stops it. If the run is cancelled, or the client
+// can no longer be written to, the run is stopped and iter drained, so that the agent does not
+// keep calling the model and tools for nobody, and no goroutine is left blocked on a stream.
+func streamEvents(ctx context.Context, cancel context.CancelFunc, s *sse.Stream, checkPointID string,
+	iter *adk.AsyncIterator[*adk.AgentEvent], rec *transcript) bool {
+	w := &sseWriter{s: s, checkPointID: checkPointID, rec: rec}
 	for {
 		event, ok := iter.Next()
 		if !ok {
-			break
+			return !w.failed
 		}
 
-		if err := processAgentEvent(ctx, s, event); err != nil {
-			log.Printf("Error processing event: %v", err)
-			break
-		}
-	}
-}
-
-func processAgentEvent(ctx context.Context, s *sse.Stream, event *adk.AgentEvent) error {
-	if event.Err != nil {
-		return sendSSEEvent(s, SSEEvent{
-			Type:      "error",
-			AgentName: event.AgentName,
-			RunPath:   formatRunPath(event.RunPath),
-			Error:     event.Err.Error(),
-		})
-	}
-
-	if event.Output != nil && event.Output.MessageOutput != nil {
-		if err := handleMessageOutput(ctx, s, event); err != nil {
-			return err
+		if ctx.Err() != nil {
+			events.CloseStream(event)
+			log.Printf("Run %s cancelled: %v", checkPointID, context.Cause(ctx))
+			// reaches the client if the run was cancelled through DELETE /runs/{id}
+			_ = sendSSEEvent(s, SSEEvent{
+				Type:         "action",
+				AgentName:    event.AgentName,
+				RunPath:      formatRunPath(event.RunPath),
+				ActionType:   "cancelled",
+				Content:      "Agent execution cancelled",
+				CheckPointID: checkPointID,
+			})
+			events.Drain(iter)
+			return false
 		}
-	}
 
-	if event.Action != nil {
-		if err := handleAction(s, event); err != nil {
-			return err
+		if err := events.Walk(event, w); err != nil {
+			log.Printf("Error processing event, stopping run %s: %v", checkPointID, err)
+			events.CloseStream(event)
+			cancel()
+			events.Drain(iter)
+			return false
 		}
 	}
-
-	return nil
 }
 
-func handleMessageOutput(ctx context.Context, s *sse.Stream, event *adk.AgentEvent) error {
-	msgOutput := event.Output.MessageOutput
-
-	if msg := msgOutput.Message; msg != nil {
-		return handleRegularMessage(s, event, msg)
-	}
+// sseWriter maps the events of a run onto the SSEEvents sent to the client. When rec is not nil,
+// it also records the final answers so that they can be added to a session.
+type sseWriter struct {
+	s            *sse.Stream
+	checkPointID string
+	rec          *transcript
+	// failed is set once the run reported an error
+	failed bool
+
+	// the message stream being read
+	content   strings.Builder
+	toolCalls map[int][]*schema.Message
+}
 
-	if stream := msgOutput.MessageStream; stream != nil {
-		return handleStreamingMessage(ctx, s, event, stream)
-	}
+func (w *sseWriter) send(event *adk.AgentEvent, e SSEEvent) error {
+	e.AgentName = event.AgentName
+	e.RunPath = formatRunPath(event.RunPath)
+	return sendSSEEvent(w.s, e)
+}
 
-	return nil
+func (w *sseWriter) OnError(event *adk.AgentEvent, err error) error {
+	w.failed = true
+	w.content.Reset()
+	w.toolCalls = nil
+	return w.send(event, SSEEvent{Type: "error", Error: err.Error()})
 }
 
-func handleRegularMessage(s *sse.Stream, event *adk.AgentEvent, msg *schema.Message) error {
+func (w *sseWriter) OnMessage(event *adk.AgentEvent, msg *schema.Message) error {
+	w.rec.record(msg)
+
 	eventType := "message"
 	if msg.Role == schema.Tool {
 		eventType = "tool_result"
 	}
-
-	sseEvent := SSEEvent{
-		Type:      eventType,
-		AgentName: event.AgentName,
-		RunPath:   formatRunPath(event.RunPath),
-		Content:   msg.Content,
-	}
-
+	sseEvent := SSEEvent{Type: eventType, Content: msg.Content}
 	if len(msg.ToolCalls) > 0 {
 		sseEvent.ToolCalls = msg.ToolCalls
 	}
-
-	return sendSSEEvent(s, sseEvent)
+	return w.send(event, sseEvent)
 }
 
-func handleStreamingMessage(ctx context.Context, s *sse.Stream, event *adk.AgentEvent, stream *schema.StreamReader[*schema.Message]) error {
-	toolCallsMap := make(map[int][]*schema.Message)
-
-	for {
-		chunk, err := stream.Recv()
-		if errors.Is(err, io.EOF) {
-			break
+func (w *sseWriter) OnChunk(event *adk.AgentEvent, chunk *schema.Message) error {
+	if chunk.Content != "" {
+		w.content.WriteString(chunk.Content)
+		eventType := "stream_chunk"
+		if chunk.Role == schema.Tool {
+			eventType = "tool_result_chunk"
 		}
-		if err != nil {
-			return sendSSEEvent(s, SSEEvent{
-				Type:      "error",
-				AgentName: event.AgentName,
-				RunPath:   formatRunPath(event.RunPath),
-				Error:     fmt.Sprintf("stream error: %v", err),
-			})
+		if err := w.send(event, SSEEvent{Type: eventType, Content: chunk.Content}); err != nil {
+			return err
 		}
+	}
 
-		if chunk.Content != "" {
-			eventType := "stream_chunk"
-			if chunk.Role == schema.Tool {
-				eventType = "tool_result_chunk"
-			}
-
-			if err := sendSSEEvent(s, SSEEvent{
-				Type:      eventType,
-				AgentName: event.AgentName,
-				RunPath:   formatRunPath(event.RunPath),
-				Content:   chunk.Content,
-			}); err != nil {
-				return err
-			}
+	// tool calls are sent once complete, at the end of the stream
+	for _, tc := range chunk.ToolCalls {
+		if tc.Index == nil {
+			continue
 		}
-
-		if len(chunk.ToolCalls) > 0 {
-			for _, tc := range chunk.ToolCalls {
-				if tc.Index != nil {
-					toolCallsMap[*tc.Index] = append(toolCallsMap[*tc.Index], &schema.Message{
-						Role: chunk.Role,
-						ToolCalls: []schema.ToolCall{
-							{
-								ID:    tc.ID,
-								Type:  tc.Type,
-								Index: tc.Index,
-								Function: schema.FunctionCall{
-									Name:      tc.Function.Name,
-									Arguments: tc.Function.Arguments,
-								},
-							},
-						},
-					})
-				}
-			}
+		if w.toolCalls == nil {
+			w.toolCalls = make(map[int][]*schema.Message)
 		}
+		w.toolCalls[*tc.Index] = append(w.toolCalls[*tc.Index], &schema.Message{
+			Role: chunk.Role,
+			ToolCalls: []schema.ToolCall{
+				{
+					ID:    tc.ID,
+					Type:  tc.Type,
+					Index: tc.Index,
+					Function: schema.FunctionCall{
+						Name:      tc.Function.Name,
+						Arguments: tc.Function.Arguments,
+					},
+				},
+			},
+		})
+	}
+	return nil
+}
+
+func (w *sseWriter) OnMessageEnd(event *adk.AgentEvent) error {
+	toolCalls := w.toolCalls
+	content := w.content.String()
+	w.content.Reset()
+	w.toolCalls = nil
+
+	if len(toolCalls) == 0 {
+		w.rec.record(&schema.Message{Role: event.Output.MessageOutput.Role, Content: content})
 	}
 
-	for _, msgs := range toolCallsMap {
+	for _, msgs := range toolCalls {
 		concatenatedMsg, err := schema.ConcatMessages(msgs)
 		if err != nil {
 			return err
 		}
-
-		if err := sendSSEEvent(s, SSEEvent{
-			Type:      "tool_calls",
-			AgentName: event.AgentName,
-			RunPath:   formatRunPath(event.RunPath),
-			ToolCalls: concatenatedMsg.ToolCalls,
-		}); err != nil {
+		if err = w.send(event, SSEEvent{Type: "tool_calls", ToolCalls: concatenatedMsg.ToolCalls}); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
-func handleAction(s *sse.Stream, event *adk.AgentEvent) error {
-	action := event.Action
-
-	if action.TransferToAgent != nil {
-		return sendSSEEvent(s, SSEEvent{
-			Type:       "action",
-			AgentName:  event.AgentName,
-			RunPath:    formatRunPath(event.RunPath),
-			ActionType: "transfer",
-			Content:    fmt.Sprintf("Transfer to agent: %s", action.TransferToAgent.DestAgentName),
-		})
-	}
+func (w *sseWriter) OnTransfer(event *adk.AgentEvent, destAgentName string) error {
+	return w.send(event, SSEEvent{
+		Type:       "action",
+		ActionType: "transfer",
+		Content:    fmt.Sprintf("Transfer to agent: %s", destAgentName),
+	})
+}
 
-	if action.Interrupted != nil {
-		for _, ic := range action.Interrupted.InterruptContexts {
-			content := fmt.Sprintf("%v", ic.Info)
-			if stringer, ok := ic.Info.(fmt.Stringer); ok {
-				content = stringer.String()
-			}
-
-			if err := sendSSEEvent(s, SSEEvent{
-				Type:       "action",
-				AgentName:  event.AgentName,
-				RunPath:    formatRunPath(event.RunPath),
-				ActionType: "interrupted",
-				Content:    content,
-			}); err != nil {
-				return err
-			}
-		}
+func (w *sseWriter) OnInterrupted(event *adk.AgentEvent, contexts []*adk.InterruptCtx) error {
+	// all pending interrupts go in one event, so the client can answer them in one POST /resume
+	interrupts := interruptPayloads(contexts)
+	messages := make([]string, 0, len(interrupts))
+	for _, p := range interrupts {
+		messages = append(messages, p.Message)
 	}
 
-	if action.Exit {
-		return sendSSEEvent(s, SSEEvent{
-			Type:       "action",
-			AgentName:  event.AgentName,
-			RunPath:    formatRunPath(event.RunPath),
-			ActionType: "exit",
-			Content:    "Agent execution completed",
-		})
-	}
+	return w.send(event, SSEEvent{
+		Type:         "action",
+		ActionType:   "interrupted",
+		Content:      strings.Join(messages, "\n\n"),
+		CheckPointID: w.checkPointID,
+		Interrupts:   interrupts,
+	})
+}
 
-	return nil
+func (w *sseWriter) OnExit(event *adk.AgentEvent) error {
+	return w.send(event, SSEEvent{
+		Type:       "action",
+		ActionType: "exit",
+		Content:    "Agent execution completed",
+	})
 }
 
 func sendSSEEvent(s *sse.Stream, event SSEEvent) error {
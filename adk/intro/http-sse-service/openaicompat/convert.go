@@ -0,0 +1,343 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openaicompat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+// toADKMessages converts the request messages into the agent input.
+func toADKMessages(msgs []Message) ([]adk.Message, error) {
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("messages must not be empty")
+	}
+	out := make([]adk.Message, 0, len(msgs))
+	for i, m := range msgs {
+		content := string(m.Content)
+		var msg *schema.Message
+		switch m.Role {
+		case "system", "developer":
+			msg = schema.SystemMessage(content)
+		case "user":
+			msg = schema.UserMessage(content)
+		case "assistant":
+			toolCalls := make([]schema.ToolCall, 0, len(m.ToolCalls))
+			for _, tc := range m.ToolCalls {
+				toolCalls = append(toolCalls, schema.ToolCall{
+					ID:   tc.ID,
+					Type: tc.Type,
+					Function: schema.FunctionCall{
+						Name:      tc.Function.Name,
+						Arguments: tc.Function.Arguments,
+					},
+				})
+			}
+			msg = schema.AssistantMessage(content, toolCalls)
+		case "tool":
+			if m.ToolCallID == "" {
+				return nil, fmt.Errorf("messages[%d]: tool message needs tool_call_id", i)
+			}
+			msg = schema.ToolMessage(content, m.ToolCallID)
+		default:
+			return nil, fmt.Errorf("messages[%d]: unsupported role '%s'", i, m.Role)
+		}
+		msg.Name = m.Name
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+// converter turns agent events into chat completion chunks, passed to emit in order.
+// It is an events.Handler: agent events are walked as for the SSE endpoints of the service.
+type converter struct {
+	id      string
+	model   string
+	created int64
+
+	checkPointID       string
+	describeInterrupts func([]*adk.InterruptCtx) any
+	includeUsage       bool
+	emit               func(*ChatCompletionChunk) error
+
+	roleSent bool
+	// the tool calls of the current assistant reply, reported once it ends. The agent may call
+	// the model several times in one run, each reply numbering its tool calls from 0, so indexes
+	// are shifted by toolCallBase to stay unique within the response
+	toolCalls    []ToolCall
+	toolCallBase int
+	// separates the text of consecutive assistant replies
+	contentSent   bool
+	needSeparator bool
+	usage         Usage
+	hasUsage      bool
+	// the chunks of the tool result being streamed
+	toolChunks []*schema.Message
+}
+
+func (cv *converter) chunk(delta Delta, finishReason *string) *ChatCompletionChunk {
+	if !cv.roleSent && finishReason == nil {
+		delta.Role = string(schema.Assistant)
+		cv.roleSent = true
+	}
+	return &ChatCompletionChunk{
+		ID:      cv.id,
+		Object:  "chat.completion.chunk",
+		Created: cv.created,
+		Model:   cv.model,
+		Choices: []ChunkChoice{{Delta: delta, FinishReason: finishReason}},
+	}
+}
+
+func (cv *converter) agentEvent(event *adk.AgentEvent, ae *AgentEvent) error {
+	ae.AgentName = event.AgentName
+	ae.RunPath = fmt.Sprintf("%v", event.RunPath)
+	return cv.emit(&ChatCompletionChunk{
+		ID:         cv.id,
+		Object:     "chat.completion.chunk",
+		Created:    cv.created,
+		Model:      cv.model,
+		Choices:    []ChunkChoice{},
+		AgentEvent: ae,
+	})
+}
+
+// OnError fails the response: an event carrying an error, or a failed message stream.
+func (cv *converter) OnError(_ *adk.AgentEvent, err error) error {
+	return err
+}
+
+func (cv *converter) OnMessage(event *adk.AgentEvent, msg *schema.Message) error {
+	if event.Output.MessageOutput.Role == schema.Tool {
+		return cv.toolResult(event, msg)
+	}
+	if err := cv.handleAssistantChunk(msg); err != nil {
+		return err
+	}
+	return cv.endAssistantMessage(event)
+}
+
+func (cv *converter) OnChunk(event *adk.AgentEvent, chunk *schema.Message) error {
+	if event.Output.MessageOutput.Role == schema.Tool {
+		cv.toolChunks = append(cv.toolChunks, chunk)
+		return nil
+	}
+	return cv.handleAssistantChunk(chunk)
+}
+
+func (cv *converter) OnMessageEnd(event *adk.AgentEvent) error {
+	if event.Output.MessageOutput.Role != schema.Tool {
+		return cv.endAssistantMessage(event)
+	}
+	chunks := cv.toolChunks
+	cv.toolChunks = nil
+	msg, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		return err
+	}
+	return cv.toolResult(event, msg)
+}
+
+func (cv *converter) toolResult(event *adk.AgentEvent, msg *schema.Message) error {
+	return cv.agentEvent(event, &AgentEvent{
+		Type:       AgentEventToolResult,
+		ToolName:   event.Output.MessageOutput.ToolName,
+		ToolCallID: msg.ToolCallID,
+		Content:    msg.Content,
+	})
+}
+
+func (cv *converter) handleAssistantChunk(msg *schema.Message) error {
+	if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		// providers report the usage of a reply once, on its last chunk
+		u := msg.ResponseMeta.Usage
+		cv.usage.PromptTokens += u.PromptTokens
+		cv.usage.CompletionTokens += u.CompletionTokens
+		cv.usage.TotalTokens += u.TotalTokens
+		cv.hasUsage = true
+	}
+
+	delta := Delta{}
+	if msg.Content != "" {
+		delta.Content = msg.Content
+		if cv.needSeparator && cv.contentSent {
+			delta.Content = "\n\n" + delta.Content
+		}
+		cv.needSeparator = false
+		cv.contentSent = true
+	}
+	for i, tc := range msg.ToolCalls {
+		idx := i
+		if tc.Index != nil {
+			idx = *tc.Index
+		}
+		cv.toolCalls = mergeToolCall(cv.toolCalls, idx, ToolCall{
+			ID:   tc.ID,
+			Type: tc.Type,
+			Function: FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			},
+		})
+	}
+
+	if delta.Content == "" {
+		return nil
+	}
+	return cv.emit(cv.chunk(delta, nil))
+}
+
+// endAssistantMessage reports the tool calls of the reply that ended. The server has already
+// run them, or is about to, so they are an agent_event rather than tool_calls deltas, which
+// an OpenAI client would run itself, and which would leave the calls without results
+// if the client sent the conversation back.
+func (cv *converter) endAssistantMessage(event *adk.AgentEvent) error {
+	cv.needSeparator = true
+	if len(cv.toolCalls) == 0 {
+		return nil
+	}
+	calls := cv.toolCalls
+	cv.toolCalls = nil
+	for i := range calls {
+		idx := cv.toolCallBase + i
+		calls[i].Index = &idx
+		if calls[i].Type == "" {
+			calls[i].Type = "function"
+		}
+	}
+	cv.toolCallBase += len(calls)
+	return cv.agentEvent(event, &AgentEvent{
+		Type:      AgentEventToolCalls,
+		ToolCalls: calls,
+	})
+}
+
+func (cv *converter) OnTransfer(event *adk.AgentEvent, destAgentName string) error {
+	return cv.agentEvent(event, &AgentEvent{
+		Type:          AgentEventTransfer,
+		DestAgentName: destAgentName,
+	})
+}
+
+func (cv *converter) OnInterrupted(event *adk.AgentEvent, contexts []*adk.InterruptCtx) error {
+	return cv.agentEvent(event, &AgentEvent{
+		Type:         AgentEventInterrupted,
+		CheckPointID: cv.checkPointID,
+		Interrupts:   cv.describeInterrupts(contexts),
+	})
+}
+
+// OnExit adds nothing to the response, which ends with the run.
+func (cv *converter) OnExit(*adk.AgentEvent) error {
+	return nil
+}
+
+// finish emits the closing chunk and, if asked for, the usage chunk.
+func (cv *converter) finish() error {
+	stop := "stop"
+	if err := cv.emit(cv.chunk(Delta{}, &stop)); err != nil {
+		return err
+	}
+	if !cv.includeUsage || !cv.hasUsage {
+		return nil
+	}
+	usage := cv.usage
+	return cv.emit(&ChatCompletionChunk{
+		ID:      cv.id,
+		Object:  "chat.completion.chunk",
+		Created: cv.created,
+		Model:   cv.model,
+		Choices: []ChunkChoice{},
+		Usage:   &usage,
+	})
+}
+
+// aggregator folds the chunks of a response into the ChatCompletion returned for stream=false,
+// so that both modes always describe the same run. Tool calls deltas, which the converter
+// leaves for the calls the client must run, are folded into message.tool_calls.
+type aggregator struct {
+	completion ChatCompletion
+	content    strings.Builder
+	toolCalls  []ToolCall
+}
+
+func (a *aggregator) add(chunk *ChatCompletionChunk) error {
+	c := &a.completion
+	c.ID, c.Created, c.Model = chunk.ID, chunk.Created, chunk.Model
+	if chunk.Usage != nil {
+		c.Usage = chunk.Usage
+	}
+	if chunk.AgentEvent != nil {
+		c.AgentEvents = append(c.AgentEvents, chunk.AgentEvent)
+	}
+
+	for _, choice := range chunk.Choices {
+		a.content.WriteString(choice.Delta.Content)
+		for _, tc := range choice.Delta.ToolCalls {
+			a.addToolCall(tc)
+		}
+		if choice.FinishReason != nil {
+			c.Choices = []Choice{{FinishReason: *choice.FinishReason}}
+		}
+	}
+	return nil
+}
+
+func (a *aggregator) addToolCall(delta ToolCall) {
+	idx := len(a.toolCalls)
+	if delta.Index != nil {
+		idx = *delta.Index
+	}
+	a.toolCalls = mergeToolCall(a.toolCalls, idx, delta)
+}
+
+// mergeToolCall adds the delta of the tool call at idx to calls: the arguments are
+// streamed in pieces, the other fields are only set on some of the deltas.
+func mergeToolCall(calls []ToolCall, idx int, delta ToolCall) []ToolCall {
+	for len(calls) <= idx {
+		calls = append(calls, ToolCall{})
+	}
+	tc := &calls[idx]
+	if delta.ID != "" {
+		tc.ID = delta.ID
+	}
+	if delta.Type != "" {
+		tc.Type = delta.Type
+	}
+	if delta.Function.Name != "" {
+		tc.Function.Name = delta.Function.Name
+	}
+	tc.Function.Arguments += delta.Function.Arguments
+	return calls
+}
+
+func (a *aggregator) result() *ChatCompletion {
+	c := a.completion
+	c.Object = "chat.completion"
+	if len(c.Choices) == 0 {
+		c.Choices = []Choice{{FinishReason: "stop"}}
+	}
+	c.Choices[0].Message = Message{
+		Role:      string(schema.Assistant),
+		Content:   MessageContent(a.content.String()),
+		ToolCalls: a.toolCalls,
+	}
+	return &c
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openaicompat
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/adk/intro/http-sse-service/internal/events"
+)
+
+func toolCall(index int, id, name, arguments string) schema.ToolCall {
+	return schema.ToolCall{Index: &index, ID: id, Function: schema.FunctionCall{Name: name, Arguments: arguments}}
+}
+
+func withUsage(msg *schema.Message, prompt, completion int) *schema.Message {
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{
+		PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}}
+	return msg
+}
+
+// messageEvent returns an event with the chunks as a stream, or concatenated into one message.
+func messageEvent(t *testing.T, streamed bool, role schema.RoleType, toolName string, chunks ...*schema.Message) *adk.AgentEvent {
+	if streamed {
+		return adk.EventFromMessage(nil, schema.StreamReaderFromArray(chunks), role, toolName)
+	}
+	msg, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		t.Fatalf("concat messages: %v", err)
+	}
+	return adk.EventFromMessage(msg, nil, role, toolName)
+}
+
+// runEvents is a run of three model replies: text with two tool calls whose arguments are
+// streamed in pieces, one more tool call, and the final answer.
+func runEvents(t *testing.T, streamed bool) []*adk.AgentEvent {
+	return []*adk.AgentEvent{
+		messageEvent(t, streamed, schema.Assistant, "",
+			schema.AssistantMessage("Let me ", nil),
+			schema.AssistantMessage("check.", nil),
+			schema.AssistantMessage("", []schema.ToolCall{toolCall(0, "call_1", "search", `{"q":`)}),
+			schema.AssistantMessage("", []schema.ToolCall{toolCall(0, "", "", `"a"}`)}),
+			withUsage(schema.AssistantMessage("", []schema.ToolCall{toolCall(1, "call_2", "search", `{"q":"b"}`)}), 10, 5),
+		),
+		messageEvent(t, streamed, schema.Tool, "search",
+			schema.ToolMessage("result ", "call_1"), schema.ToolMessage("a", "call_1")),
+		messageEvent(t, streamed, schema.Tool, "search", schema.ToolMessage("result b", "call_2")),
+		messageEvent(t, streamed, schema.Assistant, "",
+			withUsage(schema.AssistantMessage("", []schema.ToolCall{toolCall(0, "call_3", "lookup", `{}`)}), 20, 3)),
+		messageEvent(t, streamed, schema.Tool, "lookup", schema.ToolMessage("found", "call_3")),
+		messageEvent(t, streamed, schema.Assistant, "",
+			schema.AssistantMessage("Done", nil), withUsage(schema.AssistantMessage(".", nil), 30, 2)),
+	}
+}
+
+func newTestConverter(includeUsage bool, emit func(*ChatCompletionChunk) error) *converter {
+	return &converter{
+		id:           "chatcmpl-test",
+		model:        "test-agent",
+		created:      1,
+		includeUsage: includeUsage,
+		emit:         emit,
+	}
+}
+
+func convert(t *testing.T, cv *converter, evs []*adk.AgentEvent) {
+	for _, event := range evs {
+		if err := events.Walk(event, cv); err != nil {
+			t.Fatalf("walk: %v", err)
+		}
+	}
+	if err := cv.finish(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+}
+
+// complete returns the response of a stream=false request.
+func complete(t *testing.T, evs []*adk.AgentEvent) *ChatCompletion {
+	agg := &aggregator{}
+	convert(t, newTestConverter(true, agg.add), evs)
+	return agg.result()
+}
+
+func stream(t *testing.T, evs []*adk.AgentEvent, includeUsage bool) []*ChatCompletionChunk {
+	var chunks []*ChatCompletionChunk
+	convert(t, newTestConverter(includeUsage, func(c *ChatCompletionChunk) error {
+		chunks = append(chunks, c)
+		return nil
+	}), evs)
+	return chunks
+}
+
+func TestConverter_StreamMatchesNonStream(t *testing.T) {
+	want := complete(t, runEvents(t, false))
+	if got := complete(t, runEvents(t, true)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("a streaming model changed the completion:\n%+v\nwant\n%+v", got, want)
+	}
+
+	// what a client reading the stream=true response sees
+	var content strings.Builder
+	var agentEvents []*AgentEvent
+	var usage *Usage
+	finishReason := ""
+	for _, c := range stream(t, runEvents(t, true), true) {
+		for _, choice := range c.Choices {
+			content.WriteString(choice.Delta.Content)
+			if len(choice.Delta.ToolCalls) > 0 {
+				t.Fatalf("the agent's tool calls must not be sent as tool_calls deltas: %+v", choice.Delta)
+			}
+			if choice.FinishReason != nil {
+				finishReason = *choice.FinishReason
+			}
+		}
+		if c.AgentEvent != nil {
+			agentEvents = append(agentEvents, c.AgentEvent)
+		}
+		if c.Usage != nil {
+			usage = c.Usage
+		}
+	}
+
+	msg := want.Choices[0].Message
+	if content.String() != "Let me check.\n\nDone." || string(msg.Content) != content.String() {
+		t.Fatalf("content: streamed %q, completion %q", content.String(), msg.Content)
+	}
+	if len(msg.ToolCalls) != 0 {
+		t.Fatalf("the agent's tool calls must not be in message.tool_calls: %+v", msg.ToolCalls)
+	}
+	if finishReason != "stop" || want.Choices[0].FinishReason != "stop" {
+		t.Fatalf("finish reason: streamed %q, completion %q", finishReason, want.Choices[0].FinishReason)
+	}
+	if !reflect.DeepEqual(agentEvents, want.AgentEvents) {
+		t.Fatalf("agent events differ:\n%s\nwant\n%s", mustJSON(t, agentEvents), mustJSON(t, want.AgentEvents))
+	}
+	if !reflect.DeepEqual(usage, want.Usage) {
+		t.Fatalf("usage: streamed %+v, completion %+v", usage, want.Usage)
+	}
+}
+
+func TestConverter_ToolCallIndexes(t *testing.T) {
+	var types []string
+	var calls []ToolCall
+	for _, ae := range complete(t, runEvents(t, true)).AgentEvents {
+		types = append(types, ae.Type)
+		calls = append(calls, ae.ToolCalls...)
+	}
+	wantTypes := []string{AgentEventToolCalls, AgentEventToolResult, AgentEventToolResult,
+		AgentEventToolCalls, AgentEventToolResult}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("agent event types %v, want %v", types, wantTypes)
+	}
+
+	want := []struct{ id, name, arguments string }{
+		{"call_1", "search", `{"q":"a"}`},
+		{"call_2", "search", `{"q":"b"}`},
+		// the second reply numbers its call from 0 again
+		{"call_3", "lookup", `{}`},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("got %d tool calls, want %d: %s", len(calls), len(want), mustJSON(t, calls))
+	}
+	for i, w := range want {
+		tc := calls[i]
+		if tc.Index == nil || *tc.Index != i || tc.ID != w.id || tc.Type != "function" ||
+			tc.Function.Name != w.name || tc.Function.Arguments != w.arguments {
+			t.Fatalf("tool call %d: got %s, want index %d %+v", i, mustJSON(t, tc), i, w)
+		}
+	}
+}
+
+func TestConverter_Usage(t *testing.T) {
+	want := &Usage{PromptTokens: 60, CompletionTokens: 10, TotalTokens: 70}
+	if got := complete(t, runEvents(t, true)).Usage; !reflect.DeepEqual(got, want) {
+		t.Fatalf("completion usage %+v, want %+v", got, want)
+	}
+
+	chunks := stream(t, runEvents(t, false), true)
+	last := chunks[len(chunks)-1]
+	if !reflect.DeepEqual(last.Usage, want) || len(last.Choices) != 0 {
+		t.Fatalf("the stream should end with a usage chunk, got %s", mustJSON(t, last))
+	}
+
+	for _, c := range stream(t, runEvents(t, false), false) {
+		if c.Usage != nil {
+			t.Fatalf("usage sent without stream_options.include_usage: %s", mustJSON(t, c))
+		}
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package openaicompat serves adk.Runners behind an OpenAI-compatible Chat Completions API,
+// so that existing OpenAI SDK clients can talk to any agent: the "model" of a request
+// selects the runner, and the agent's events are mapped onto chat.completion.chunk frames.
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/google/uuid"
+	"github.com/hertz-contrib/sse"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/intro/http-sse-service/internal/events"
+)
+
+// Config configures a Server.
+type Config struct {
+	// Agents maps the model names clients ask for to the runners serving them.
+	// Required, at least one.
+	Agents map[string]*adk.Runner
+
+	// DefaultModel serves requests that name no model. Optional.
+	DefaultModel string
+
+	// DescribeInterrupts renders the interrupts of an interrupted run for the client.
+	// Optional. Defaults to the ID and the description of every interrupt.
+	DescribeInterrupts func(contexts []*adk.InterruptCtx) any
+}
+
+// Server handles Chat Completions requests. Each request is a new run of the agent over the
+// messages of the request, checkpointed under a fresh ID reported when the run is interrupted.
+type Server struct {
+	agents             map[string]*adk.Runner
+	defaultModel       string
+	describeInterrupts func([]*adk.InterruptCtx) any
+	created            int64
+}
+
+func New(config *Config) (*Server, error) {
+	if config == nil || len(config.Agents) == 0 {
+		return nil, fmt.Errorf("at least one agent is required")
+	}
+	if config.DefaultModel != "" && config.Agents[config.DefaultModel] == nil {
+		return nil, fmt.Errorf("default model '%s' is not one of the agents", config.DefaultModel)
+	}
+	s := &Server{
+		agents:             config.Agents,
+		defaultModel:       config.DefaultModel,
+		describeInterrupts: config.DescribeInterrupts,
+		created:            time.Now().Unix(),
+	}
+	if s.describeInterrupts == nil {
+		s.describeInterrupts = defaultDescribeInterrupts
+	}
+	return s, nil
+}
+
+// BindRoutes registers POST /chat/completions and GET /models, usually on a "/v1" group.
+func (s *Server) BindRoutes(r *route.RouterGroup) {
+	r.POST("/chat/completions", s.HandleChatCompletions)
+	r.GET("/models", s.HandleModels)
+}
+
+// HandleModels lists the agents as models.
+func (s *Server) HandleModels(ctx context.Context, c *app.RequestContext) {
+	names := make([]string, 0, len(s.agents))
+	for name := range s.agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := ModelList{Object: "list", Data: make([]ModelInfo, 0, len(names))}
+	for _, name := range names {
+		list.Data = append(list.Data, ModelInfo{ID: name, Object: "model", Created: s.created, OwnedBy: "eino"})
+	}
+	c.JSON(consts.StatusOK, list)
+}
+
+// HandleChatCompletions runs the requested agent and answers with a chat completion,
+// or a stream of chunks ended by "data: [DONE]" when the request sets stream.
+func (s *Server) HandleChatCompletions(ctx context.Context, c *app.RequestContext) {
+	var req ChatCompletionRequest
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil {
+		writeError(c, consts.StatusBadRequest, "invalid_request_error", "", "invalid request body: "+err.Error())
+		return
+	}
+
+	modelName := req.Model
+	if modelName == "" {
+		modelName = s.defaultModel
+	}
+	runner := s.agents[modelName]
+	if runner == nil {
+		writeError(c, consts.StatusNotFound, "invalid_request_error", "model_not_found",
+			fmt.Sprintf("the model '%s' does not exist", req.Model))
+		return
+	}
+	if len(req.Tools) > 0 && string(req.Tools) != "null" && string(req.Tools) != "[]" {
+		writeError(c, consts.StatusBadRequest, "invalid_request_error", "",
+			"client-side tools are not supported, the agent runs its own tools")
+		return
+	}
+	messages, err := toADKMessages(req.Messages)
+	if err != nil {
+		writeError(c, consts.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+
+	checkPointID := uuid.New().String()
+	cv := &converter{
+		id:                 "chatcmpl-" + uuid.New().String(),
+		model:              modelName,
+		created:            time.Now().Unix(),
+		checkPointID:       checkPointID,
+		describeInterrupts: s.describeInterrupts,
+	}
+
+	// the run stops with the request, e.g. when the client disconnects
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	log.Printf("[openai] %s: running %d message(s), stream=%v", modelName, len(messages), req.Stream)
+	iter := runner.Run(ctx, messages, adk.WithCheckPointID(checkPointID))
+
+	if req.Stream {
+		cv.includeUsage = req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+		streamCompletion(c, cv, iter, cancel)
+		return
+	}
+
+	agg := &aggregator{}
+	cv.includeUsage = true
+	cv.emit = agg.add
+	if err = runConverter(cv, iter, cancel); err != nil {
+		writeError(c, consts.StatusInternalServerError, "server_error", "", err.Error())
+		return
+	}
+	c.JSON(consts.StatusOK, agg.result())
+}
+
+// runConverter feeds every event of iter to cv. If an event fails or cannot be delivered,
+// the run is cancelled and iter drained, so that the agent stops and its streams are closed.
+func runConverter(cv *converter, iter *adk.AsyncIterator[*adk.AgentEvent], cancel context.CancelFunc) error {
+	for {
+		event, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err := events.Walk(event, cv); err != nil {
+			events.CloseStream(event)
+			cancel()
+			events.Drain(iter)
+			return err
+		}
+	}
+	return cv.finish()
+}
+
+func streamCompletion(c *app.RequestContext, cv *converter, iter *adk.AsyncIterator[*adk.AgentEvent], cancel context.CancelFunc) {
+	s := sse.NewStream(c)
+	defer func(c *app.RequestContext) {
+		_ = c.Flush()
+	}(c)
+
+	cv.emit = func(chunk *ChatCompletionChunk) error {
+		return publishJSON(s, chunk)
+	}
+	if err := runConverter(cv, iter, cancel); err != nil {
+		log.Printf("[openai] stream %s failed: %v", cv.id, err)
+		// like the OpenAI API, a failed stream ends with an error frame and no [DONE]
+		_ = publishJSON(s, &ErrorResponse{Error: ErrorDetail{Message: err.Error(), Type: "server_error"}})
+		return
+	}
+	if err := s.Publish(&sse.Event{Data: []byte("[DONE]")}); err != nil {
+		log.Printf("[openai] stream %s: %v", cv.id, err)
+	}
+}
+
+func publishJSON(s *sse.Stream, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SSE event: %w", err)
+	}
+	return s.Publish(&sse.Event{Data: data})
+}
+
+func writeError(c *app.RequestContext, status int, typ, code, message string) {
+	c.JSON(status, &ErrorResponse{Error: ErrorDetail{Message: message, Type: typ, Code: code}})
+}
+
+type interruptDescription struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func defaultDescribeInterrupts(contexts []*adk.InterruptCtx) any {
+	out := make([]interruptDescription, 0, len(contexts))
+	for _, ic := range contexts {
+		d := interruptDescription{ID: ic.ID, Message: fmt.Sprintf("%v", ic.Info)}
+		if s, ok := ic.Info.(fmt.Stringer); ok {
+			d.Message = s.String()
+		}
+		out = append(out, d)
+	}
+	return out
+}
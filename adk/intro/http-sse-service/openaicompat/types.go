@@ -0,0 +1,206 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openaicompat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChatCompletionRequest is the subset of the Chat Completions request that makes sense for an agent.
+// Sampling parameters are accepted and ignored: the agent owns its model configuration.
+type ChatCompletionRequest struct {
+	Model         string         `json:"model"`
+	Messages      []Message      `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	// Tools is only read to reject requests with client-side tools, the agent runs its own tools.
+	Tools json.RawMessage `json:"tools,omitempty"`
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+type Message struct {
+	Role       string         `json:"role"`
+	Content    MessageContent `json:"content"`
+	Name       string         `json:"name,omitempty"`
+	ToolCalls  []ToolCall     `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// MessageContent is the text of a message. It decodes from a string, null, or an array of
+// content parts of which only text parts are supported, and always encodes as a string.
+type MessageContent string
+
+func (mc *MessageContent) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*mc = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*mc = MessageContent(s)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of content parts: %w", err)
+	}
+	var sb strings.Builder
+	for _, p := range parts {
+		if p.Type != "text" {
+			return fmt.Errorf("content part type '%s' is not supported", p.Type)
+		}
+		sb.WriteString(p.Text)
+	}
+	*mc = MessageContent(sb.String())
+	return nil
+}
+
+type ToolCall struct {
+	// Index is only set in streamed deltas.
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatCompletion is the response of a request with stream=false.
+type ChatCompletion struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   *Usage   `json:"usage,omitempty"`
+
+	// AgentEvents is an extension listing what happened besides the answer, see AgentEvent.
+	AgentEvents []*AgentEvent `json:"agent_events,omitempty"`
+}
+
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason string  `json:"finish_reason"`
+}
+
+// ChatCompletionChunk is one "data:" frame of a streamed response.
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []ChunkChoice `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+
+	// AgentEvent is an extension set on chunks that carry no delta, see AgentEvent.
+	AgentEvent *AgentEvent `json:"agent_event,omitempty"`
+}
+
+type ChunkChoice struct {
+	Index        int     `json:"index"`
+	Delta        Delta   `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+type Delta struct {
+	Role      string     `json:"role,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// Agent event types reported in AgentEvent.Type.
+const (
+	AgentEventToolCalls   = "tool_calls"
+	AgentEventToolResult  = "tool_result"
+	AgentEventTransfer    = "transfer"
+	AgentEventInterrupted = "interrupted"
+)
+
+// AgentEvent describes an agent event that has no Chat Completions equivalent. The tool calls
+// of the agent are reported here rather than as tool_calls, because the server runs them:
+// a client must neither run them nor send them back. Their results come back here too.
+// Clients that do not know the field simply ignore it.
+type AgentEvent struct {
+	Type      string `json:"type"`
+	AgentName string `json:"agent_name,omitempty"`
+	RunPath   string `json:"run_path,omitempty"`
+
+	// ToolCalls is set for the tool calls of an assistant reply. Their Index is unique
+	// within the response.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// ToolName, ToolCallID and Content are set for tool results.
+	ToolName   string `json:"tool_name,omitempty"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	Content    string `json:"content,omitempty"`
+
+	// DestAgentName is set for transfers.
+	DestAgentName string `json:"dest_agent_name,omitempty"`
+
+	// CheckPointID and Interrupts are set when the run is interrupted.
+	CheckPointID string `json:"checkpoint_id,omitempty"`
+	Interrupts   any    `json:"interrupts,omitempty"`
+}
+
+// ErrorResponse is the body of failed requests, and the frame sent when a stream fails.
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
+
+// ModelList is the response of GET /models.
+type ModelList struct {
+	Object string      `json:"object"`
+	Data   []ModelInfo `json:"data"`
+}
+
+type ModelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/hertz-contrib/sse"
+
+	"github.com/cloudwego/eino/adk"
+
+	"github.com/cloudwego/eino-examples/adk/common/hitl"
+	commontool "github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// Interrupt kinds reported in InterruptPayload.Kind.
+const (
+	InterruptKindApproval   = "approval"
+	InterruptKindReviewEdit = "review_edit"
+	InterruptKindFollowUp   = "follow_up"
+	InterruptKindUnknown    = "unknown"
+)
+
+// InterruptPayload describes one pending interrupt to the client. The client answers it
+// by sending a ResumeDecision with the same ID and a field matching Kind.
+type InterruptPayload struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+
+	ToolName  string `json:"tool_name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+
+	// ValidationErrors are set when a previous review edit was rejected.
+	ValidationErrors []commontool.ArgumentError `json:"validation_errors,omitempty"`
+
+	Questions []string `json:"questions,omitempty"`
+
+	// Deadline is set when the interrupt resolves to a default decision on its own.
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// ResumeRequest is the body of POST /resume.
+type ResumeRequest struct {
+	CheckPointID string           `json:"checkpoint_id"`
+	Decisions    []ResumeDecision `json:"decisions"`
+
+	// SessionID is the session the interrupted turn belongs to, if any.
+	// The answers of the resumed run are added to its history.
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// ResumeDecision answers one interrupt. Exactly one of Approval, ReviewEdit and FollowUp must be set.
+// Interrupts of the checkpoint without a decision stay pending and are reported again.
+type ResumeDecision struct {
+	InterruptID string              `json:"interrupt_id"`
+	Approval    *ApprovalDecision   `json:"approval,omitempty"`
+	ReviewEdit  *ReviewEditDecision `json:"review_edit,omitempty"`
+	FollowUp    *FollowUpDecision   `json:"follow_up,omitempty"`
+}
+
+type ApprovalDecision struct {
+	Approved           bool   `json:"approved"`
+	Reason             string `json:"reason,omitempty"`
+	RememberForSession bool   `json:"remember_for_session,omitempty"`
+}
+
+type ReviewEditDecision struct {
+	// EditedArguments is the new arguments object. It may also be a string holding the JSON.
+	EditedArguments json.RawMessage `json:"edited_arguments,omitempty"`
+	NoNeedToEdit    bool            `json:"no_need_to_edit,omitempty"`
+	Disapproved     bool            `json:"disapproved,omitempty"`
+	Reason          string          `json:"reason,omitempty"`
+}
+
+type FollowUpDecision struct {
+	Answer string `json:"answer"`
+}
+
+func interruptPayloads(contexts []*adk.InterruptCtx) []InterruptPayload {
+	payloads := make([]InterruptPayload, 0, len(contexts))
+	for _, ic := range contexts {
+		p := InterruptPayload{ID: ic.ID, Kind: InterruptKindUnknown, Message: fmt.Sprintf("%v", ic.Info)}
+		if stringer, ok := ic.Info.(fmt.Stringer); ok {
+			p.Message = stringer.String()
+		}
+		if e, ok := ic.Info.(hitl.Expiring); ok {
+			if deadline := e.ExpiresAt(); !deadline.IsZero() {
+				p.Deadline = &deadline
+			}
+		}
+
+		switch info := ic.Info.(type) {
+		case *commontool.ApprovalInfo:
+			p.Kind = InterruptKindApproval
+			p.ToolName = info.ToolName
+			p.Arguments = info.ArgumentsInJSON
+		case *commontool.ReviewEditInfo:
+			p.Kind = InterruptKindReviewEdit
+			p.ToolName = info.ToolName
+			p.Arguments = info.ArgumentsInJSON
+			p.ValidationErrors = info.ValidationErrors
+		case *commontool.FollowUpInfo:
+			p.Kind = InterruptKindFollowUp
+			p.Questions = info.Questions
+		}
+		payloads = append(payloads, p)
+	}
+	return payloads
+}
+
+// resumeData converts a decision into the resume data expected by the interrupted tool.
+func (d *ResumeDecision) resumeData() (any, error) {
+	set := 0
+	for _, ok := range []bool{d.Approval != nil, d.ReviewEdit != nil, d.FollowUp != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("decision for interrupt '%s' must set exactly one of approval, review_edit and follow_up", d.InterruptID)
+	}
+
+	switch {
+	case d.Approval != nil:
+		result := &commontool.ApprovalResult{
+			Approved:           d.Approval.Approved,
+			RememberForSession: d.Approval.RememberForSession,
+		}
+		if !d.Approval.Approved && d.Approval.Reason != "" {
+			reason := d.Approval.Reason
+			result.DisapproveReason = &reason
+		}
+		return result, nil
+
+	case d.ReviewEdit != nil:
+		result := &commontool.ReviewEditResult{
+			NoNeedToEdit: d.ReviewEdit.NoNeedToEdit,
+			Disapproved:  d.ReviewEdit.Disapproved,
+		}
+		if d.ReviewEdit.Disapproved && d.ReviewEdit.Reason != "" {
+			reason := d.ReviewEdit.Reason
+			result.DisapproveReason = &reason
+		}
+		if len(d.ReviewEdit.EditedArguments) > 0 {
+			edited := string(d.ReviewEdit.EditedArguments)
+			var s string
+			if err := json.Unmarshal(d.ReviewEdit.EditedArguments, &s); err == nil {
+				edited = s
+			}
+			result.EditedArgumentsInJSON = &edited
+		}
+		if !result.NoNeedToEdit && !result.Disapproved && result.EditedArgumentsInJSON == nil {
+			return nil, fmt.Errorf("review_edit decision for interrupt '%s' needs edited_arguments, no_need_to_edit or disapproved", d.InterruptID)
+		}
+		return &commontool.ReviewEditInfo{ReviewResult: result}, nil
+
+	default:
+		if strings.TrimSpace(d.FollowUp.Answer) == "" {
+			return nil, fmt.Errorf("follow_up decision for interrupt '%s' needs an answer", d.InterruptID)
+		}
+		return &commontool.FollowUpInfo{UserAnswer: d.FollowUp.Answer}, nil
+	}
+}
+
+func handleResume(ctx context.Context, c *app.RequestContext, runner *adk.Runner, runs *runRegistry, sessions *sessionManager) {
+	var req ResumeRequest
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if req.CheckPointID == "" || len(req.Decisions) == 0 {
+		c.JSON(consts.StatusBadRequest, map[string]string{"error": "checkpoint_id and decisions are required"})
+		return
+	}
+
+	targets := make(map[string]any, len(req.Decisions))
+	for i := range req.Decisions {
+		d := &req.Decisions[i]
+		if d.InterruptID == "" {
+			c.JSON(consts.StatusBadRequest, map[string]string{"error": fmt.Sprintf("decision %d has no interrupt_id", i)})
+			return
+		}
+		if _, dup := targets[d.InterruptID]; dup {
+			c.JSON(consts.StatusBadRequest, map[string]string{"error": fmt.Sprintf("interrupt '%s' is decided twice", d.InterruptID)})
+			return
+		}
+		data, err := d.resumeData()
+		if err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		targets[d.InterruptID] = data
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if !runs.acquire(req.CheckPointID, cancel) {
+		c.JSON(consts.StatusConflict, map[string]string{"error": fmt.Sprintf("checkpoint '%s' is already running", req.CheckPointID)})
+		return
+	}
+	defer runs.release(req.CheckPointID)
+
+	var session *Session
+	if req.SessionID != "" {
+		if err := checkSessionID(req.SessionID); err != nil {
+			c.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if !runs.acquire(sessionLockKey(req.SessionID), nil) {
+			c.JSON(consts.StatusConflict, map[string]string{"error": fmt.Sprintf("session '%s' is already running", req.SessionID)})
+			return
+		}
+		defer runs.release(sessionLockKey(req.SessionID))
+
+		var err error
+		if session, err = sessions.load(ctx, req.SessionID); err != nil {
+			c.JSON(consts.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("failed to load session: %v", err)})
+			return
+		}
+	}
+
+	log.Printf("Resuming checkpoint %s with %d decision(s)", req.CheckPointID, len(targets))
+
+	iter, err := hitl.ResumeAll(runCtx, runner, req.CheckPointID, targets)
+	if err != nil {
+		c.JSON(consts.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+
+	s := sse.NewStream(c)
+	defer flushSSE(c)
+	if session == nil {
+		streamEvents(runCtx, cancel, s, req.CheckPointID, iter, nil)
+		return
+	}
+	rec := &transcript{}
+	if !streamEvents(runCtx, cancel, s, req.CheckPointID, iter, rec) {
+		return
+	}
+	if err = sessions.appendTurn(ctx, session, rec.messages...); err != nil {
+		reportSaveError(s, err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// runRegistry makes sure a checkpoint is driven by at most one request at a time,
+// since two concurrent resumes of the same checkpoint would overwrite each other's progress.
+// It also holds the cancel function of every running run, so that it can be stopped from
+// another request.
+type runRegistry struct {
+	mu      sync.Mutex
+	running map[string]context.CancelFunc
+}
+
+func newRunRegistry() *runRegistry {
+	return &runRegistry{running: make(map[string]context.CancelFunc)}
+}
+
+// acquire marks key as running. cancel stops the run, it may be nil for keys that are only locks.
+func (r *runRegistry) acquire(key string, cancel context.CancelFunc) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.running[key]; ok {
+		return false
+	}
+	if cancel == nil {
+		cancel = func() {}
+	}
+	r.running[key] = cancel
+	return true
+}
+
+func (r *runRegistry) release(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, key)
+}
+
+// cancel stops the run registered under key. It reports false if nothing is running under key.
+// The run's request releases the key once the agent has stopped.
+func (r *runRegistry) cancel(key string) bool {
+	r.mu.Lock()
+	cancel, ok := r.running[key]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+func handleCancelRun(ctx context.Context, c *app.RequestContext, runs *runRegistry) {
+	id := c.Param("id")
+	if !runs.cancel(id) {
+		c.JSON(consts.StatusNotFound, map[string]string{"error": fmt.Sprintf("run '%s' is not running", id)})
+		return
+	}
+	log.Printf("Cancelled run %s", id)
+	c.JSON(consts.StatusOK, map[string]string{"status": "cancelled"})
+}
@@ -0,0 +1,368 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+
+	"github.com/cloudwego/eino/adk"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Session is the history of one multi-turn conversation. It only keeps user messages and
+// final assistant answers: tool calls of a turn are not replayed, so a turn left interrupted
+// never leaves a tool call without its result in the history.
+type Session struct {
+	ID        string            `json:"id"`
+	Messages  []*schema.Message `json:"messages"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// SessionStore persists sessions. Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Get returns the session, or false if it does not exist.
+	Get(ctx context.Context, id string) (*Session, bool, error)
+	// Save creates or replaces the session.
+	Save(ctx context.Context, session *Session) error
+	// List returns the IDs of all sessions, sorted.
+	List(ctx context.Context) ([]string, error)
+	// Delete removes the session, it is not an error if it does not exist.
+	Delete(ctx context.Context, id string) error
+}
+
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+func checkSessionID(id string) error {
+	if !sessionIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid session id %q, expected 1-128 letters, digits, '-' or '_'", id)
+	}
+	return nil
+}
+
+// newSessionStore creates a SessionStore according to environment variables:
+//   - SESSION_STORE: "memory" (default) or "file".
+//   - SESSION_DIR: directory used by the file store, defaults to "./data/sessions".
+func newSessionStore() SessionStore {
+	switch storeType := strings.ToLower(os.Getenv("SESSION_STORE")); storeType {
+	case "", "memory":
+		return newMemorySessionStore()
+	case "file":
+		dir := os.Getenv("SESSION_DIR")
+		if dir == "" {
+			dir = "./data/sessions"
+		}
+		s, err := newFileSessionStore(dir)
+		if err != nil {
+			log.Fatalf("create file session store failed: %v", err)
+		}
+		return s
+	default:
+		log.Fatalf("unknown SESSION_STORE %q, expected memory or file", storeType)
+		return nil
+	}
+}
+
+type memorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string][]byte
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{sessions: make(map[string][]byte)}
+}
+
+// sessions are kept encoded, so that callers never share message pointers with the store
+func (m *memorySessionStore) Get(_ context.Context, id string) (*Session, bool, error) {
+	m.mu.RLock()
+	data, ok := m.sessions[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, false, nil
+	}
+	s := &Session{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, false, err
+	}
+	return s, true, nil
+}
+
+func (m *memorySessionStore) Save(_ context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = data
+	return nil
+}
+
+func (m *memorySessionStore) List(_ context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.sessions))
+	for id := range m.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (m *memorySessionStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+const sessionFileExt = ".json"
+
+// fileSessionStore keeps one JSON file per session, replaced atomically on every Save.
+type fileSessionStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+func newFileSessionStore(dir string) (*fileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create session dir: %w", err)
+	}
+	return &fileSessionStore{dir: dir}, nil
+}
+
+func (f *fileSessionStore) path(id string) string {
+	return filepath.Join(f.dir, id+sessionFileExt)
+}
+
+func (f *fileSessionStore) Get(_ context.Context, id string) (*Session, bool, error) {
+	if err := checkSessionID(id); err != nil {
+		return nil, false, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	data, err := os.ReadFile(f.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	s := &Session{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, false, fmt.Errorf("decode session '%s': %w", id, err)
+	}
+	return s, true, nil
+}
+
+func (f *fileSessionStore) Save(_ context.Context, session *Session) error {
+	if err := checkSessionID(session.ID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		// no-op once the rename succeeded
+		_ = os.Remove(tmpName)
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, f.path(session.ID))
+}
+
+func (f *fileSessionStore) List(_ context.Context) ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, sessionFileExt) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, sessionFileExt))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (f *fileSessionStore) Delete(_ context.Context, id string) error {
+	if err := checkSessionID(id); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := os.Remove(f.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// sessionManager loads and saves sessions around agent runs and keeps their history short
+// by running the summarization middleware over it before each turn. It is the only place
+// the history is summarized: the agent itself runs without the middleware.
+type sessionManager struct {
+	store   SessionStore
+	compact adk.AgentMiddleware
+}
+
+// load returns the session, creating an empty one if it does not exist yet,
+// with its history compacted if it grew past the summarization threshold.
+func (m *sessionManager) load(ctx context.Context, id string) (*Session, error) {
+	session, ok, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		now := time.Now()
+		return &Session{ID: id, CreatedAt: now, UpdatedAt: now}, nil
+	}
+
+	if m.compact.BeforeChatModel == nil || len(session.Messages) == 0 {
+		return session, nil
+	}
+	state := &adk.ChatModelAgentState{Messages: session.Messages}
+	if err = m.compact.BeforeChatModel(ctx, state); err != nil {
+		// an oversized history still works until the model's context is exceeded, so keep going
+		log.Printf("Compacting session %s failed: %v", id, err)
+		return session, nil
+	}
+	if len(state.Messages) != len(session.Messages) {
+		log.Printf("Compacted session %s from %d to %d messages", id, len(session.Messages), len(state.Messages))
+		session.Messages = state.Messages
+		if err = m.store.Save(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+	return session, nil
+}
+
+// appendTurn adds the messages of a finished or interrupted turn and saves the session.
+func (m *sessionManager) appendTurn(ctx context.Context, session *Session, msgs ...*schema.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	session.Messages = append(session.Messages, msgs...)
+	session.UpdatedAt = time.Now()
+	if err := m.store.Save(ctx, session); err != nil {
+		return fmt.Errorf("failed to save session '%s': %w", session.ID, err)
+	}
+	return nil
+}
+
+// transcript records the final assistant answers of a run, i.e. the assistant messages
+// without tool calls, to be appended to the session once the run stops.
+type transcript struct {
+	messages []*schema.Message
+}
+
+func (t *transcript) record(msg *schema.Message) {
+	if t == nil || msg == nil || msg.Role != schema.Assistant || len(msg.ToolCalls) > 0 || msg.Content == "" {
+		return
+	}
+	t.messages = append(t.messages, schema.AssistantMessage(msg.Content, nil))
+}
+
+// sessionLockKey keeps session keys apart from checkpoint IDs in the shared runRegistry.
+func sessionLockKey(id string) string {
+	return "session/" + id
+}
+
+func handleListSessions(ctx context.Context, c *app.RequestContext, sessions *sessionManager) {
+	ids, err := sessions.store.List(ctx)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]any{"ids": ids})
+}
+
+func handleGetSession(ctx context.Context, c *app.RequestContext, sessions *sessionManager) {
+	id := c.Param("id")
+	if err := checkSessionID(id); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	session, ok, err := sessions.store.Get(ctx, id)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(consts.StatusNotFound, map[string]string{"error": "session not found"})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]any{"session": session})
+}
+
+func handleDeleteSession(ctx context.Context, c *app.RequestContext, sessions *sessionManager, runs *runRegistry) {
+	id := c.Param("id")
+	if err := checkSessionID(id); err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// deleting while a turn runs would let the turn write the session back when it ends
+	if !runs.acquire(sessionLockKey(id), nil) {
+		c.JSON(consts.StatusConflict, map[string]string{"error": fmt.Sprintf("session '%s' is running", id)})
+		return
+	}
+	defer runs.release(sessionLockKey(id))
+
+	if err := sessions.store.Delete(ctx, id); err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+
+	commontool "github.com/cloudwego/eino-examples/adk/common/tool"
+)
+
+// createTools returns one tool per kind of interrupt the service can carry to a client:
+// an approval, a review-and-edit and a follow-up question.
+func createTools() ([]tool.BaseTool, error) {
+	type emailInput struct {
+		To      string `json:"to" jsonschema:"description=recipient email address"`
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+	}
+	sendEmail, err := utils.InferTool("send_email", "Send an email on behalf of the user.",
+		func(ctx context.Context, input emailInput) (string, error) {
+			return fmt.Sprintf("email '%s' sent to %s", input.Subject, input.To), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	type eventInput struct {
+		Title     string   `json:"title"`
+		Start     string   `json:"start" jsonschema:"description=start time in RFC3339"`
+		Minutes   int      `json:"minutes" jsonschema:"description=duration in minutes"`
+		Attendees []string `json:"attendees,omitempty"`
+	}
+	createEvent, err := utils.InferTool("create_calendar_event", "Create an event in the user's calendar.",
+		func(ctx context.Context, input eventInput) (string, error) {
+			return fmt.Sprintf("event '%s' created at %s for %d minutes", input.Title, input.Start, input.Minutes), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return []tool.BaseTool{
+		&commontool.InvokableApprovableTool{InvokableTool: sendEmail},
+		&commontool.InvokableReviewEditTool{InvokableTool: createEvent},
+		commontool.GetFollowUpTool(),
+	}, nil
+}
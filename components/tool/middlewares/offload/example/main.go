@@ -0,0 +1,97 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// This example shows how to configure the offload middleware on a ToolsNode
+// to replace a large tool result with a preview, and read the rest back with
+// the read_artifact tool.
+// Run: go run ./components/tool/middlewares/offload/example
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+
+	"github.com/cloudwego/eino-examples/components/tool/middlewares/offload"
+)
+
+type WebSearch struct {
+	Query string `json:"query"`
+}
+
+func main() {
+	ctx := context.Background()
+	// 1. Create a mock "web_search" tool returning a large result.
+	searcher, _ := utils.InferTool("web_search", "search the web", func(ctx context.Context, in *WebSearch) (string, error) {
+		var sb strings.Builder
+		for i := 1; i <= 200; i++ {
+			sb.WriteString(fmt.Sprintf("%d. result about %s\n", i, in.Query))
+		}
+		return sb.String(), nil
+	})
+
+	// 2. Create the artifact store, the middleware and the read_artifact tool sharing it.
+	// Use offload.NewDirStore to keep the artifacts on disk instead.
+	store := offload.NewMemoryStore(100)
+	mw, err := offload.New(&offload.Config{Store: store, MaxResultChars: 2000, PreviewChars: 300})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	readTool, err := offload.NewReadArtifactTool(store, 0)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	// 3. Create a compose.ToolNode with both tools and inject the middleware.
+	tn, _ := compose.NewToolNode(ctx, &compose.ToolsNodeConfig{
+		Tools:               []tool.BaseTool{searcher, readTool},
+		ToolCallMiddlewares: []compose.ToolMiddleware{mw},
+	})
+
+	// 4. Simulate a tool call: the result is replaced by a preview and an artifact ID.
+	outs, err := tn.Invoke(ctx, schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "1", Function: schema.FunctionCall{Name: "web_search", Arguments: `{"query":"eino"}`}},
+	}))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println("web_search:", outs[0].Content)
+
+	// 5. Simulate the agent reading the next page, as suggested by the preview.
+	start := strings.Index(outs[0].Content, "art_")
+	if start < 0 {
+		return
+	}
+	id := outs[0].Content[start : start+36]
+	outs, err = tn.Invoke(ctx, schema.AssistantMessage("", []schema.ToolCall{
+		{ID: "2", Function: schema.FunctionCall{Name: offload.ReadArtifactToolName,
+			Arguments: fmt.Sprintf(`{"id":%q,"offset":300,"limit":500}`, id)}},
+	}))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println("read_artifact:", outs[0].Content)
+}
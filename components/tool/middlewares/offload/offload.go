@@ -0,0 +1,249 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package offload provides a ToolMiddleware for Eino's ToolsNode that keeps
+// large tool results out of the context: a result over the threshold is stored
+// in a Store and replaced by a preview plus a handle, and the agent reads the
+// rest on demand with the read_artifact tool.
+//
+// Usage:
+//
+//	store := offload.NewMemoryStore(0)
+//	mw, _ := offload.New(&offload.Config{Store: store})
+//	readTool, _ := offload.NewReadArtifactTool(store, 0)
+//	conf := &compose.ToolsNodeConfig{
+//	  Tools: []tool.BaseTool{yourTool, readTool},
+//	  ToolCallMiddlewares: []compose.ToolMiddleware{mw},
+//	}
+//
+// Behavior:
+//   - Results up to MaxResultChars are returned unchanged.
+//   - Streamed results are passed through up to PreviewChars characters. The
+//     following chunks are held until the stream ends, and passed on, or until
+//     it exceeds MaxResultChars: the whole result is then offloaded, and the
+//     stream ends with the handle instead of the rest of the result.
+//   - Results of the read_artifact tool itself are never offloaded.
+package offload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	DefaultMaxResultChars = 8000
+	DefaultPreviewChars   = 1000
+)
+
+// Config configures the offloading middleware.
+type Config struct {
+	// Store keeps the offloaded results.
+	// Required. It must be the store given to NewReadArtifactTool.
+	Store Store
+
+	// MaxResultChars is the size, in characters, above which a result is offloaded.
+	// Uses DefaultMaxResultChars when <= 0.
+	MaxResultChars int
+
+	// PreviewChars is the number of leading characters kept in place of an offloaded result.
+	// Uses DefaultPreviewChars when <= 0.
+	PreviewChars int
+
+	// ReadToolName is the name of the read tool mentioned in the handle, and never offloaded.
+	// Optional. Defaults to ReadArtifactToolName.
+	ReadToolName string
+
+	// SkipTools lists tools whose results are never offloaded. Optional.
+	SkipTools []string
+}
+
+type offloader struct {
+	store        Store
+	maxChars     int
+	previewChars int
+	readToolName string
+	skip         map[string]bool
+}
+
+// New creates a ToolMiddleware offloading large results to cfg.Store.
+func New(cfg *Config) (compose.ToolMiddleware, error) {
+	if cfg == nil || cfg.Store == nil {
+		return compose.ToolMiddleware{}, fmt.Errorf("store is required")
+	}
+	o := &offloader{
+		store:        cfg.Store,
+		maxChars:     DefaultMaxResultChars,
+		previewChars: DefaultPreviewChars,
+		readToolName: ReadArtifactToolName,
+		skip:         map[string]bool{},
+	}
+	if cfg.MaxResultChars > 0 {
+		o.maxChars = cfg.MaxResultChars
+	}
+	if cfg.PreviewChars > 0 {
+		o.previewChars = cfg.PreviewChars
+	}
+	if o.previewChars > o.maxChars {
+		o.previewChars = o.maxChars
+	}
+	if cfg.ReadToolName != "" {
+		o.readToolName = cfg.ReadToolName
+	}
+	o.skip[o.readToolName] = true
+	for _, name := range cfg.SkipTools {
+		o.skip[name] = true
+	}
+	return compose.ToolMiddleware{Invokable: o.invokable, Streamable: o.streamable}, nil
+}
+
+func (o *offloader) invokable(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+	return func(ctx context.Context, in *compose.ToolInput) (*compose.ToolOutput, error) {
+		output, err := next(ctx, in)
+		if err != nil || output == nil || o.skip[in.Name] {
+			return output, err
+		}
+		if !o.tooLarge(output.Result) {
+			return output, nil
+		}
+		result, err := o.offload(ctx, in, output.Result)
+		if err != nil {
+			return nil, err
+		}
+		return &compose.ToolOutput{Result: result}, nil
+	}
+}
+
+func (o *offloader) streamable(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
+	return func(ctx context.Context, in *compose.ToolInput) (*compose.StreamToolOutput, error) {
+		output, err := next(ctx, in)
+		if err != nil || output == nil || output.Result == nil || o.skip[in.Name] {
+			return output, err
+		}
+
+		sr, sw := schema.Pipe[string](1)
+		go o.relay(ctx, in, output.Result, sw)
+		return &compose.StreamToolOutput{Result: sr}, nil
+	}
+}
+
+// relay passes the first previewChars characters of stream on to sw as they arrive, and holds
+// the next chunks until the stream ends within maxChars. Past maxChars, the held chunks and the
+// rest of the stream are offloaded, and the stream ends with the handle.
+func (o *offloader) relay(ctx context.Context, in *compose.ToolInput, stream *schema.StreamReader[string],
+	sw *schema.StreamWriter[string]) {
+	defer sw.Close()
+	defer stream.Close()
+
+	var (
+		all     strings.Builder // the whole result so far, offloaded when too large
+		held    []string
+		total   int // characters received
+		emitted int // characters passed on, at most previewChars
+	)
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			for _, c := range held {
+				if sw.Send(c, nil) {
+					return
+				}
+			}
+			return
+		}
+		if err != nil {
+			sw.Send("", err)
+			return
+		}
+
+		all.WriteString(chunk)
+		total += utf8.RuneCountInString(chunk)
+		if total > o.maxChars {
+			o.spill(ctx, in, stream, sw, &all, emitted)
+			return
+		}
+
+		if len(held) > 0 || emitted == o.previewChars {
+			held = append(held, chunk)
+			continue
+		}
+		// pass on the part of the chunk within the preview, hold the rest
+		pass := chunk
+		if runes := []rune(chunk); emitted+len(runes) > o.previewChars {
+			pass = string(runes[:o.previewChars-emitted])
+			held = append(held, string(runes[o.previewChars-emitted:]))
+		}
+		emitted += utf8.RuneCountInString(pass)
+		if pass != "" && sw.Send(pass, nil) {
+			return
+		}
+	}
+}
+
+// spill reads the rest of stream into all, offloads it, and sends the end of the preview,
+// past the emitted characters, and the handle.
+func (o *offloader) spill(ctx context.Context, in *compose.ToolInput, stream *schema.StreamReader[string],
+	sw *schema.StreamWriter[string], all *strings.Builder, emitted int) {
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			sw.Send("", err)
+			return
+		}
+		all.WriteString(chunk)
+	}
+
+	result, err := o.offload(ctx, in, all.String())
+	if err != nil {
+		sw.Send("", err)
+		return
+	}
+	sw.Send(string([]rune(result)[emitted:]), nil)
+}
+
+func (o *offloader) tooLarge(result string) bool {
+	// the byte length bounds the character count, which saves counting runes of small results
+	return len(result) > o.maxChars && len([]rune(result)) > o.maxChars
+}
+
+func (o *offloader) offload(ctx context.Context, in *compose.ToolInput, result string) (string, error) {
+	id, err := o.store.Put(ctx, &Artifact{
+		ToolName:  in.Name,
+		CallID:    in.CallID,
+		Content:   result,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("offload result of tool '%s' failed: %w", in.Name, err)
+	}
+
+	runes := []rune(result)
+	preview := string(runes[:o.previewChars])
+	return fmt.Sprintf("%s\n\n[The output of tool '%s' has %d characters, too many to return in full. "+
+		"It was stored as artifact %q, shown above are its first %d characters. "+
+		"To read more, call %s with {\"id\": %q, \"offset\": %d}.]",
+		preview, in.Name, len(runes), id, o.previewChars, o.readToolName, id, o.previewChars), nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package offload
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestInvokable_OffloadsLargeResults(t *testing.T) {
+	store := NewMemoryStore(0)
+	mw, err := New(&Config{Store: store, MaxResultChars: 10, PreviewChars: 4})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	large := strings.Repeat("0123456789", 3)
+	next := func(_ context.Context, in *compose.ToolInput) (*compose.ToolOutput, error) {
+		if in.Name == "small" {
+			return &compose.ToolOutput{Result: "short"}, nil
+		}
+		return &compose.ToolOutput{Result: large}, nil
+	}
+	endpoint := mw.Invokable(next)
+
+	out, err := endpoint(context.Background(), &compose.ToolInput{Name: "small"})
+	if err != nil || out.Result != "short" {
+		t.Fatalf("small results must pass through, got %v %v", out, err)
+	}
+
+	out, err = endpoint(context.Background(), &compose.ToolInput{Name: "big", CallID: "c1"})
+	if err != nil {
+		t.Fatalf("invoke: %v", err)
+	}
+	if !strings.HasPrefix(out.Result, "0123\n") || strings.Contains(out.Result, large) {
+		t.Fatalf("expected a preview, got %q", out.Result)
+	}
+	id := out.Result[strings.Index(out.Result, "art_"):][:36]
+
+	a, err := store.Get(context.Background(), id)
+	if err != nil || a.Content != large || a.ToolName != "big" || a.CallID != "c1" {
+		t.Fatalf("unexpected artifact %+v, err=%v", a, err)
+	}
+
+	// reading the artifact back is never offloaded, even past the threshold
+	out, err = endpoint(context.Background(), &compose.ToolInput{Name: ReadArtifactToolName})
+	if err != nil || out.Result != large {
+		t.Fatalf("read_artifact results must pass through, got %v %v", out, err)
+	}
+
+	readTool, err := NewReadArtifactTool(store, 8)
+	if err != nil {
+		t.Fatalf("new read tool: %v", err)
+	}
+	args, _ := json.Marshal(&ReadArtifactInput{ID: id, Offset: 4, Limit: 100})
+	page, err := readTool.InvokableRun(context.Background(), string(args))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.HasPrefix(page, "45678901\n") || !strings.Contains(page, `"offset": 12`) {
+		t.Fatalf("unexpected page %q", page)
+	}
+}
+
+func TestStreamable_OffloadsLargeResults(t *testing.T) {
+	store := NewMemoryStore(0)
+	mw, err := New(&Config{Store: store, MaxResultChars: 10, PreviewChars: 4})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	chunks := []string{"01234", "56789", "abcde"}
+	next := func(_ context.Context, in *compose.ToolInput) (*compose.StreamToolOutput, error) {
+		return &compose.StreamToolOutput{Result: schema.StreamReaderFromArray(chunks[:len(in.Arguments)])}, nil
+	}
+	endpoint := mw.Streamable(next)
+
+	recvAll := func(args string) []string {
+		out, err := endpoint(context.Background(), &compose.ToolInput{Name: "t", Arguments: args})
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+		var got []string
+		for {
+			chunk, err := out.Result.Recv()
+			if err == io.EOF {
+				return got
+			}
+			if err != nil {
+				t.Fatalf("recv: %v", err)
+			}
+			got = append(got, chunk)
+		}
+	}
+
+	// the preview is passed on as it arrives, the rest once the stream ended within the limit
+	if got := recvAll("x"); strings.Join(got, "|") != "0123|4" {
+		t.Fatalf("small streams must pass through, got %q", got)
+	}
+	if got := recvAll("xx"); strings.Join(got, "|") != "0123|4|56789" {
+		t.Fatalf("small streams must pass through, got %q", got)
+	}
+	got := recvAll("xxx")
+	if len(got) != 2 || got[0] != "0123" || !strings.HasPrefix(got[1], "\n\n[") {
+		t.Fatalf("expected the preview then the handle, got %q", got)
+	}
+	id := got[1][strings.Index(got[1], "art_"):][:36]
+	if a, err := store.Get(context.Background(), id); err != nil || a.Content != strings.Join(chunks, "") {
+		t.Fatalf("unexpected artifact %+v, err=%v", a, err)
+	}
+
+	// a first chunk past the limit still gets the whole preview
+	chunks = []string{strings.Repeat("x", 12)}
+	if got = recvAll("x"); len(got) != 1 || !strings.HasPrefix(got[0], "xxxx\n\n[") {
+		t.Fatalf("expected the preview and the handle, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package offload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+const (
+	ReadArtifactToolName = "read_artifact"
+	DefaultPageChars     = 4000
+)
+
+// ReadArtifactInput is the input of the read_artifact tool.
+type ReadArtifactInput struct {
+	ID     string `json:"id" jsonschema_description:"ID of the artifact, as given where the tool output was cut"`
+	Offset int    `json:"offset,omitempty" jsonschema_description:"Character offset to start reading from, defaults to 0"`
+	Limit  int    `json:"limit,omitempty" jsonschema_description:"Maximum number of characters to read, defaults to 4000"`
+}
+
+// NewReadArtifactTool creates the read_artifact tool, paging through the artifacts of store.
+// A limit above pageChars is lowered to it, so that one page never floods the context again.
+// Uses DefaultPageChars when pageChars <= 0.
+func NewReadArtifactTool(store Store, pageChars int) (tool.InvokableTool, error) {
+	if pageChars <= 0 {
+		pageChars = DefaultPageChars
+	}
+	return utils.InferTool(ReadArtifactToolName,
+		"Read part of a tool output that was too long to return in full. "+
+			"Use the artifact ID and offset given where the output was cut, and keep reading from the next offset if needed.",
+		func(ctx context.Context, in *ReadArtifactInput) (string, error) {
+			a, err := store.Get(ctx, in.ID)
+			if errors.Is(err, ErrArtifactNotFound) {
+				// let the model correct itself instead of failing the run
+				return fmt.Sprintf("artifact %q not found", in.ID), nil
+			}
+			if err != nil {
+				return "", err
+			}
+			return readPage(a, in.Offset, in.Limit, pageChars), nil
+		})
+}
+
+func readPage(a *Artifact, offset, limit, pageChars int) string {
+	runes := []rune(a.Content)
+	total := len(runes)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return fmt.Sprintf("[artifact %q has %d characters, offset %d is past the end]", a.ID, total, offset)
+	}
+	if limit <= 0 || limit > pageChars {
+		limit = pageChars
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	page := string(runes[offset:end])
+	if end == total {
+		return fmt.Sprintf("%s\n\n[characters %d-%d of %d, end of artifact %q]", page, offset, end, total, a.ID)
+	}
+	return fmt.Sprintf("%s\n\n[characters %d-%d of %d, continue with {\"id\": %q, \"offset\": %d}]",
+		page, offset, end, total, a.ID, end)
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package offload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Artifact is an offloaded tool result.
+type Artifact struct {
+	ID        string    `json:"id"`
+	ToolName  string    `json:"tool_name"`
+	CallID    string    `json:"call_id,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ErrArtifactNotFound is returned by Store.Get for unknown or evicted artifacts.
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// Store keeps offloaded results. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores the artifact under a new ID and returns it, the ID of the argument is ignored.
+	Put(ctx context.Context, artifact *Artifact) (string, error)
+	// Get returns the artifact, or ErrArtifactNotFound.
+	Get(ctx context.Context, id string) (*Artifact, error)
+}
+
+func newArtifactID() string {
+	return "art_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+// MemoryStore keeps artifacts in memory, for a single process.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	artifacts map[string]*Artifact
+	order     []string
+	max       int
+}
+
+// NewMemoryStore creates a MemoryStore. With a positive maxArtifacts, the oldest artifacts
+// are evicted once the store holds more, to bound its memory; 0 keeps them all.
+func NewMemoryStore(maxArtifacts int) *MemoryStore {
+	return &MemoryStore{artifacts: map[string]*Artifact{}, max: maxArtifacts}
+}
+
+func (m *MemoryStore) Put(_ context.Context, artifact *Artifact) (string, error) {
+	a := *artifact
+	a.ID = newArtifactID()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.artifacts[a.ID] = &a
+	m.order = append(m.order, a.ID)
+	for m.max > 0 && len(m.order) > m.max {
+		delete(m.artifacts, m.order[0])
+		m.order = m.order[1:]
+	}
+	return a.ID, nil
+}
+
+func (m *MemoryStore) Get(_ context.Context, id string) (*Artifact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.artifacts[id]
+	if !ok {
+		return nil, ErrArtifactNotFound
+	}
+	cp := *a
+	return &cp, nil
+}
+
+// DirStore keeps one JSON file per artifact in a local directory, so that artifacts survive
+// restarts and can be inspected by hand.
+type DirStore struct {
+	dir string
+}
+
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create artifact dir: %w", err)
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+var artifactIDPattern = regexp.MustCompile(`^art_[0-9a-f]{32}$`)
+
+func (d *DirStore) path(id string) string {
+	return filepath.Join(d.dir, id+".json")
+}
+
+func (d *DirStore) Put(_ context.Context, artifact *Artifact) (string, error) {
+	a := *artifact
+	a.ID = newArtifactID()
+	data, err := json.Marshal(&a)
+	if err != nil {
+		return "", err
+	}
+
+	// write then rename, so that readers never see a partial artifact
+	tmp := d.path(a.ID) + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp, d.path(a.ID)); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return a.ID, nil
+}
+
+func (d *DirStore) Get(_ context.Context, id string) (*Artifact, error) {
+	// the ID comes from the model, never let it escape the directory
+	if !artifactIDPattern.MatchString(id) {
+		return nil, ErrArtifactNotFound
+	}
+	data, err := os.ReadFile(d.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrArtifactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	a := &Artifact{}
+	if err = json.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("decode artifact '%s': %w", id, err)
+	}
+	return a, nil
+}
@@ -0,0 +1,359 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	callbackutils "github.com/cloudwego/eino/utils/callbacks"
+)
+
+// Limiter paces batch items and the ChatModel requests of their inner tasks. The same Limiter
+// can be set on several batch nodes, e.g. all the nodes calling the same provider account,
+// so that they share its limits.
+//
+// A Limiter must not be shared between a batch node and a batch node nested in its inner task:
+// every outer item holds an item slot while its inner items wait for one, so once the outer
+// items take all the slots, the inner items wait forever.
+type Limiter interface {
+	// AcquireItem blocks until one more item may run, or ctx is done.
+	// release must be called once the item ends.
+	AcquireItem(ctx context.Context) (release func(), err error)
+
+	// WaitRequest blocks until a ChatModel request may be sent, or ctx is done.
+	WaitRequest(ctx context.Context) error
+
+	// ObserveRequest reports the end of a ChatModel request, with the tokens it used
+	// (0 if the model did not report them) and its error.
+	ObserveRequest(tokens int, err error)
+}
+
+// RateLimiterConfig configures a RateLimiter. Every limit is optional, 0 means no limit.
+type RateLimiterConfig struct {
+	// RequestsPerMinute limits the ChatModel requests.
+	RequestsPerMinute int
+
+	// TokensPerMinute limits the tokens used by ChatModel requests. The tokens of a request
+	// are only known once it ends, so requests are sent while tokens remain, and the
+	// tokens used beyond the limit delay the next requests.
+	TokensPerMinute int
+
+	// MaxConcurrency limits the items running at once, across all the nodes sharing the limiter.
+	// It also enables adaptive concurrency: the limit is halved when a request is rate limited,
+	// and raised back by one after as many successful requests as the current limit.
+	MaxConcurrency int
+
+	// MinConcurrency is the floor of the adaptive concurrency. Defaults to 1.
+	MinConcurrency int
+
+	// IsRateLimited reports whether a request failed because of the provider's rate limits,
+	// e.g. with HTTP 429. Defaults to IsRateLimitError.
+	IsRateLimited func(err error) bool
+}
+
+// RateLimiter is a Limiter with token buckets for requests and tokens per minute, and
+// an adaptive concurrency limit. Buckets start full: a minute's worth can be sent at once.
+type RateLimiter struct {
+	isRateLimited  func(error) bool
+	maxConcurrency int
+	minConcurrency int
+
+	mu        sync.Mutex
+	requests  *tokenBucket
+	tokens    *tokenBucket
+	limit     int
+	running   int
+	successes int
+	reducedAt time.Time
+	// changed is closed, and replaced, whenever an item slot may have become available
+	changed chan struct{}
+	now     func() time.Time
+}
+
+// reduceCooldown keeps a burst of rate limited requests from reducing the concurrency more than once.
+const reduceCooldown = time.Second
+
+// NewRateLimiter creates a RateLimiter from config, which is required. It returns an error
+// if a limit is negative or MinConcurrency exceeds MaxConcurrency.
+func NewRateLimiter(config *RateLimiterConfig) (*RateLimiter, error) {
+	if config == nil {
+		return nil, fmt.Errorf("rate limiter config is required")
+	}
+	if config.RequestsPerMinute < 0 || config.TokensPerMinute < 0 || config.MaxConcurrency < 0 || config.MinConcurrency < 0 {
+		return nil, fmt.Errorf("rate limits must not be negative")
+	}
+	minConcurrency := max(config.MinConcurrency, 1)
+	if config.MaxConcurrency > 0 && minConcurrency > config.MaxConcurrency {
+		return nil, fmt.Errorf("min concurrency %d exceeds max concurrency %d", minConcurrency, config.MaxConcurrency)
+	}
+
+	l := &RateLimiter{
+		isRateLimited:  config.IsRateLimited,
+		maxConcurrency: config.MaxConcurrency,
+		minConcurrency: minConcurrency,
+		limit:          config.MaxConcurrency,
+		changed:        make(chan struct{}),
+		now:            time.Now,
+	}
+	if l.isRateLimited == nil {
+		l.isRateLimited = IsRateLimitError
+	}
+	now := l.now()
+	if config.RequestsPerMinute > 0 {
+		l.requests = newTokenBucket(config.RequestsPerMinute, now)
+	}
+	if config.TokensPerMinute > 0 {
+		l.tokens = newTokenBucket(config.TokensPerMinute, now)
+	}
+	return l, nil
+}
+
+// Concurrency returns the current limit of items running at once, 0 if there is none.
+func (l *RateLimiter) Concurrency() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// AcquireItem blocks until fewer items than the current concurrency limit are running, or ctx
+// is done. The slot is held until release is called, which is safe to call more than once.
+// Do not use the same RateLimiter for a batch node and a batch node nested in its inner task,
+// see Limiter.
+func (l *RateLimiter) AcquireItem(ctx context.Context) (func(), error) {
+	for {
+		l.mu.Lock()
+		if l.limit == 0 || l.running < l.limit {
+			l.running++
+			l.mu.Unlock()
+			var once sync.Once
+			return func() { once.Do(l.releaseItem) }, nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (l *RateLimiter) releaseItem() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	l.notify()
+}
+
+// notify wakes up the items waiting for a slot, l.mu must be held.
+func (l *RateLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// WaitRequest blocks until the request bucket has a request left and the token bucket
+// at least one token, or ctx is done. It takes the request, the tokens are only taken
+// by ObserveRequest.
+func (l *RateLimiter) WaitRequest(ctx context.Context) error {
+	for {
+		wait := l.reserveRequest()
+		if wait == 0 {
+			return nil
+		}
+		if !sleep(ctx, wait) {
+			return ctx.Err()
+		}
+	}
+}
+
+// reserveRequest takes a request from the buckets if possible, and otherwise returns how long to wait.
+func (l *RateLimiter) reserveRequest() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	if l.requests != nil {
+		wait = max(wait, l.requests.waitFor(1, now))
+	}
+	if l.tokens != nil {
+		// a single token left is enough, the request pays for what it used once it ends
+		wait = max(wait, l.tokens.waitFor(1, now))
+	}
+	if wait > 0 {
+		return wait
+	}
+	if l.requests != nil {
+		l.requests.take(1)
+	}
+	return 0
+}
+
+// ObserveRequest takes the tokens used by a request from the token bucket, and adapts the
+// concurrency limit: a rate limited request halves it, at most once per second and not below
+// MinConcurrency, and as many successful requests as the limit raise it by one up to MaxConcurrency.
+func (l *RateLimiter) ObserveRequest(tokens int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.tokens != nil && tokens > 0 {
+		l.tokens.refill(now)
+		l.tokens.take(float64(tokens))
+	}
+	if l.maxConcurrency == 0 {
+		return
+	}
+
+	switch {
+	case err == nil:
+		l.successes++
+		if l.successes >= l.limit && l.limit < l.maxConcurrency {
+			l.limit++
+			l.successes = 0
+			l.notify()
+		}
+	case l.isRateLimited(err):
+		l.successes = 0
+		if now.Sub(l.reducedAt) >= reduceCooldown {
+			l.limit = max(l.limit/2, l.minConcurrency)
+			l.reducedAt = now
+		}
+	}
+}
+
+// IsRateLimitError reports whether err looks like a provider rate limit error,
+// i.e. mentions HTTP 429, "rate limit" or "too many requests".
+func IsRateLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "429") || strings.Contains(msg, "rate limit") ||
+		strings.Contains(msg, "too many requests")
+}
+
+// tokenBucket holds up to a minute's worth of tokens, refilled continuously.
+// Its level goes negative when more tokens are taken than available.
+type tokenBucket struct {
+	capacity float64
+	perSec   float64
+	level    float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		level:    float64(perMinute),
+		last:     now,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.level = math.Min(b.capacity, b.level+elapsed*b.perSec)
+		b.last = now
+	}
+}
+
+// waitFor returns how long until the bucket holds n tokens, 0 if it already does.
+func (b *tokenBucket) waitFor(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration(math.Ceil((n - b.level) / b.perSec * float64(time.Second)))
+}
+
+func (b *tokenBucket) take(n float64) {
+	b.level -= n
+}
+
+// newLimiterHandler returns the callback handler feeding the ChatModel requests of the inner tasks to limiter.
+func newLimiterHandler(limiter Limiter) callbacks.Handler {
+	return callbackutils.NewHandlerHelper().ChatModel(&callbackutils.ModelCallbackHandler{
+		OnStart: func(ctx context.Context, _ *callbacks.RunInfo, _ *model.CallbackInput) context.Context {
+			// the request fails on its own if ctx is done
+			_ = limiter.WaitRequest(ctx)
+			return ctx
+		},
+		OnEnd: func(ctx context.Context, _ *callbacks.RunInfo, output *model.CallbackOutput) context.Context {
+			limiter.ObserveRequest(usedTokens(output), nil)
+			return ctx
+		},
+		OnEndWithStreamOutput: func(ctx context.Context, _ *callbacks.RunInfo, output *schema.StreamReader[*model.CallbackOutput]) context.Context {
+			go func() {
+				defer output.Close()
+				// providers report the usage once at the end, or cumulatively, so keep the largest
+				tokens := 0
+				for {
+					chunk, err := output.Recv()
+					if errors.Is(err, io.EOF) {
+						limiter.ObserveRequest(tokens, nil)
+						return
+					}
+					if err != nil {
+						limiter.ObserveRequest(tokens, err)
+						return
+					}
+					tokens = max(tokens, usedTokens(chunk))
+				}
+			}()
+			return ctx
+		},
+		OnError: func(ctx context.Context, _ *callbacks.RunInfo, err error) context.Context {
+			limiter.ObserveRequest(0, err)
+			return ctx
+		},
+	}).Handler()
+}
+
+// usedTokens returns the tokens reported by the model, or by its message for the models
+// whose callbacks are injected by the graph.
+func usedTokens(output *model.CallbackOutput) int {
+	if output == nil {
+		return 0
+	}
+	if u := output.TokenUsage; u != nil {
+		return totalTokens(u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+	}
+	if msg := output.Message; msg != nil && msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		u := msg.ResponseMeta.Usage
+		return totalTokens(u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+	}
+	return 0
+}
+
+func totalTokens(prompt, completion, total int) int {
+	if total > 0 {
+		return total
+	}
+	return prompt + completion
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestRateLimiterBuckets(t *testing.T) {
+	l, err := NewRateLimiter(&RateLimiterConfig{RequestsPerMinute: 60, TokensPerMinute: 6000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 60; i++ {
+		if wait := l.reserveRequest(); wait != 0 {
+			t.Fatalf("request %d: expected no wait, got %v", i, wait)
+		}
+	}
+	if wait := l.reserveRequest(); wait != time.Second {
+		t.Fatalf("expected to wait 1s once the requests are used up, got %v", wait)
+	}
+	now = now.Add(time.Second)
+	if wait := l.reserveRequest(); wait != 0 {
+		t.Fatalf("expected a request after 1s, got a wait of %v", wait)
+	}
+
+	// requests using more tokens than left delay the next ones until the overdraft is repaid
+	now = now.Add(time.Minute)
+	l.ObserveRequest(6000+999, nil)
+	if wait := l.reserveRequest(); wait != 10*time.Second {
+		t.Fatalf("expected to wait 10s for the tokens, got %v", wait)
+	}
+}
+
+func TestRateLimiterAdaptiveConcurrency(t *testing.T) {
+	l, err := NewRateLimiter(&RateLimiterConfig{MaxConcurrency: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var releases []func()
+	for i := 0; i < 4; i++ {
+		release, err := l.AcquireItem(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = l.AcquireItem(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the 5th item to wait, got %v", err)
+	}
+
+	l.ObserveRequest(0, errors.New("error, status code: 429, message: Too Many Requests"))
+	l.ObserveRequest(0, errors.New("rate limit exceeded"))
+	if c := l.Concurrency(); c != 2 {
+		t.Fatalf("expected the concurrency to be halved once, got %d", c)
+	}
+	for _, release := range releases {
+		release()
+	}
+
+	l.ObserveRequest(0, errors.New("invalid request"))
+	l.ObserveRequest(0, nil)
+	l.ObserveRequest(0, nil)
+	if c := l.Concurrency(); c != 3 {
+		t.Fatalf("expected the concurrency to grow after 2 successes, got %d", c)
+	}
+}
+
+// usageModel answers every request with a message reporting the given token usage.
+type usageModel struct {
+	tokens int
+}
+
+func (m *usageModel) Generate(_ context.Context, _ []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	msg := schema.AssistantMessage("ok", nil)
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{TotalTokens: m.tokens}}
+	return msg, nil
+}
+
+func (m *usageModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+// countingLimiter records what batch nodes report to it.
+type countingLimiter struct {
+	mu       sync.Mutex
+	items    int
+	requests int
+	tokens   int
+}
+
+func (c *countingLimiter) AcquireItem(context.Context) (func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items++
+	return func() {}, nil
+}
+
+func (c *countingLimiter) WaitRequest(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	return nil
+}
+
+func (c *countingLimiter) ObserveRequest(tokens int, _ error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens += tokens
+}
+
+func TestLimiterSharedByNodes(t *testing.T) {
+	limiter := &countingLimiter{}
+	newNode := func(tokens int) *Node[[]*schema.Message, *schema.Message] {
+		g := compose.NewGraph[[]*schema.Message, *schema.Message]()
+		_ = g.AddChatModelNode("model", &usageModel{tokens: tokens})
+		_ = g.AddEdge(compose.START, "model")
+		_ = g.AddEdge("model", compose.END)
+		return NewBatchNode(&NodeConfig[[]*schema.Message, *schema.Message]{
+			InnerTask:      g,
+			MaxConcurrency: 2,
+			Limiter:        limiter,
+		})
+	}
+
+	inputs := make([][]*schema.Message, 5)
+	for i := range inputs {
+		inputs[i] = []*schema.Message{schema.UserMessage("hi")}
+	}
+	if _, err := newNode(10).Invoke(context.Background(), inputs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newNode(100).Invoke(context.Background(), inputs[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	if limiter.items != 7 || limiter.requests != 7 || limiter.tokens != 5*10+2*100 {
+		t.Fatalf("expected 7 items, 7 requests and 250 tokens, got %d, %d and %d",
+			limiter.items, limiter.requests, limiter.tokens)
+	}
+}
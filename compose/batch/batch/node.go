@@ -18,9 +18,11 @@ package batch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/compose"
@@ -37,6 +39,10 @@ type Node[I, O any] struct {
 	innerTask           Compilable[I, O]
 	maxConcurrency      int
 	innerCompileOptions []compose.GraphCompileOption
+	failurePolicy       FailurePolicy
+	limiter             Limiter
+	limiterHandler      callbacks.Handler
+	checkPointStore     compose.CheckPointStore
 }
 
 // NewBatchNode creates a new batch processing node.
@@ -53,12 +59,19 @@ func NewBatchNode[I, O any](config *NodeConfig[I, O]) *Node[I, O] {
 	if name == "" {
 		name = "Node"
 	}
-	return &Node[I, O]{
+	b := &Node[I, O]{
 		name:                name,
 		innerTask:           config.InnerTask,
 		maxConcurrency:      config.MaxConcurrency,
 		innerCompileOptions: config.InnerCompileOptions,
+		failurePolicy:       config.FailurePolicy,
+		limiter:             config.Limiter,
+		checkPointStore:     config.CheckPointStore,
 	}
+	if b.limiter != nil {
+		b.limiterHandler = newLimiterHandler(b.limiter)
+	}
+	return b
 }
 
 // GetType returns the node name for callback identification.
@@ -82,9 +95,26 @@ func (b *Node[I, O]) IsCallbacksEnabled() bool {
 //   - opts: Optional batch options (e.g., WithInnerOptions)
 //
 // Returns:
-//   - []O: Results in the same order as inputs
-//   - error: First normal error, or CompositeInterrupt if any task interrupted
+//   - []O: Results in the same order as inputs, zero values for failures tolerated by the failure policy
+//   - error: *Error if the failure policy failed the batch, or CompositeInterrupt if any task interrupted
+//
+// On resume, the interrupted tasks load their checkpoints one at a time, then run with the
+// configured concurrency once all of them are loaded: loading a checkpoint updates the resume
+// info shared by the whole run, which other tasks must not read meanwhile. When interrupts came
+// from a nested batch node, whose tasks load their checkpoints in the middle of the run, the
+// interrupted tasks resume one at a time instead.
 func (b *Node[I, O]) Invoke(ctx context.Context, inputs []I, opts ...Option) ([]O, error) {
+	res, err := b.InvokeWithResults(ctx, inputs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return res.Outputs(), nil
+}
+
+// InvokeWithResults is Invoke returning the outcome of every item: its output, or why it
+// did not succeed. When the failure policy fails the batch, the result is returned along
+// with the *Error, so that the outputs of the items that succeeded are not lost.
+func (b *Node[I, O]) InvokeWithResults(ctx context.Context, inputs []I, opts ...Option) (*Result[O], error) {
 	batchOpts := applyBatchOptions(opts...)
 
 	// Setup callbacks for batch-level monitoring
@@ -94,30 +124,44 @@ func (b *Node[I, O]) Invoke(ctx context.Context, inputs []I, opts ...Option) ([]
 		MaxConcurrency: b.maxConcurrency,
 	})
 
-	outputs, err := b.invoke(ctx, inputs, batchOpts)
+	res, err := b.invoke(ctx, inputs, batchOpts)
 	if err != nil {
 		callbacks.OnError(ctx, err)
-		return nil, err
+		return res, err
 	}
 
-	callbacks.OnEnd(ctx, &CallbackOutput[O]{Outputs: outputs})
-	return outputs, nil
+	callbacks.OnEnd(ctx, &CallbackOutput[O]{Outputs: res.Outputs(), Items: res.Items})
+	return res, nil
 }
 
 // invoke is the internal implementation of batch processing.
-func (b *Node[I, O]) invoke(ctx context.Context, inputs []I, batchOpts *options) ([]O, error) {
+func (b *Node[I, O]) invoke(ctx context.Context, inputs []I, batchOpts *options) (*Result[O], error) {
 	// Check if this is a resume from a previous interrupt
 	wasInterrupted, hasState, prevState := compose.GetInterruptState[*NodeInterruptState](ctx)
 
 	var store *batchBridgeStore
 	var indicesToProcess []int
 	var effectiveInputs []I
+	var gate *resumeGate
+	maxConcurrency := b.maxConcurrency
 
 	if wasInterrupted && hasState && prevState != nil {
 		// RESUME PATH: Restore state from previous interrupt
-		// Use fresh store (don't restore checkpoint data - it causes input issues)
-		store = newBatchBridgeStore()
 		indicesToProcess = prevState.InterruptedIndices
+		store = newBatchBridgeStore()
+		if b.checkPointStore != nil && prevState.CheckPointPrefix != "" {
+			// Restore the checkpoints of the interrupted tasks, so that they resume where they stopped
+			var err error
+			if store, err = newBackedBatchBridgeStore(b.checkPointStore, prevState.CheckPointPrefix, indicesToProcess); err != nil {
+				return nil, err
+			}
+			switch {
+			case prevState.NestedInterrupts:
+				maxConcurrency = 0
+			case maxConcurrency > 0:
+				gate = newResumeGate(len(indicesToProcess), maxConcurrency)
+			}
+		}
 
 		// Restore original inputs from interrupt state
 		// (inputs parameter is nil during resume)
@@ -130,6 +174,12 @@ func (b *Node[I, O]) invoke(ctx context.Context, inputs []I, batchOpts *options)
 	} else {
 		// FIRST RUN PATH: Process all inputs
 		store = newBatchBridgeStore()
+		if b.checkPointStore != nil {
+			var err error
+			if store, err = newBackedBatchBridgeStore(b.checkPointStore, "", nil); err != nil {
+				return nil, err
+			}
+		}
 		effectiveInputs = inputs
 		indicesToProcess = make([]int, len(inputs))
 		for i := range inputs {
@@ -137,121 +187,135 @@ func (b *Node[I, O]) invoke(ctx context.Context, inputs []I, batchOpts *options)
 		}
 	}
 
-	// Allocate output slice
-	outputs := make([]O, len(effectiveInputs))
+	// One result per item, every item not processed below succeeded in a previous run
+	res := &Result[O]{Items: make([]ItemResult[O], len(effectiveInputs))}
+	for i := range res.Items {
+		res.Items[i] = ItemResult[O]{Index: i, Status: ItemSucceeded}
+	}
+
+	maxFailures := b.policy(batchOpts).maxFailures(len(effectiveInputs))
+	failed := 0
 
-	// Restore completed results from previous run (if resuming)
+	// Restore completed results and tolerated failures from previous run (if resuming)
 	if wasInterrupted && hasState && prevState != nil {
 		for idx, result := range prevState.CompletedResults {
-			if idx < len(outputs) {
+			if idx < len(res.Items) {
 				if typedResult, ok := result.(O); ok {
-					outputs[idx] = typedResult
+					res.Items[idx].Output = typedResult
 				}
 			}
 		}
+		for idx, msg := range prevState.FailedErrors {
+			if idx < len(res.Items) {
+				res.Items[idx].Status = ItemFailed
+				res.Items[idx].Err = errors.New(msg)
+				failed++
+			}
+		}
 	}
 
-	// Compile inner task with checkpoint store
-	compileOpts := append([]compose.GraphCompileOption{
-		compose.WithCheckPointStore(store),
-	}, b.innerCompileOptions...)
-
-	runner, err := b.innerTask.Compile(ctx, compileOpts...)
+	runner, err := b.compile(ctx, store)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile inner task: %w", err)
+		return nil, err
 	}
 
 	// Nothing to process (all completed in previous run)
 	if len(indicesToProcess) == 0 {
-		return outputs, nil
+		return res, nil
 	}
 
-	// Task result for collecting outputs from goroutines
-	type taskResult struct {
-		index  int
-		output O
-		err    error
-	}
+	// Failing the batch cancels runCtx, which stops the tasks running and skips the others
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	resultCh := make(chan taskResult, len(indicesToProcess))
-	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var interruptErrs []error
+	interruptedIndices := make([]int, 0)
+	nestedInterrupts := false
+	stopped := false
+
+	// record stores the outcome of a task and applies the failure policy
+	record := func(result ItemResult[O]) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		result.Status = itemStatus(ctx, result.Err, stopped)
+		switch result.Status {
+		case ItemInterrupted:
+			// Interrupt error: collect for CompositeInterrupt
+			interruptErrs = append(interruptErrs, result.Err)
+			interruptedIndices = append(interruptedIndices, result.Index)
+			nestedInterrupts = nestedInterrupts || hasNestedInterrupt(ctx, result.Err)
+		case ItemCancelled:
+			result.Err = ErrItemCancelled
+		case ItemFailed:
+			failed++
+			if failed > maxFailures && !stopped {
+				stopped = true
+				cancel()
+			}
+		}
+		res.Items[result.Index] = result
+	}
 
-	// runTask executes a single inner task
+	// runTask executes a single inner task, its checkpoint is dropped unless it interrupted
 	runTask := func(index int, input I) {
-		defer wg.Done()
-
-		// Create sub-context with unique address segment for this task
-		// This enables proper interrupt ID generation (e.g., "batch_process:0")
-		subCtx := compose.AppendAddressSegment(ctx, AddressSegmentBatchProcess, strconv.Itoa(index))
-
-		// Combine checkpoint ID with user-provided inner options
-		invokeOpts := append([]compose.Option{
-			compose.WithCheckPointID(makeBatchCheckpointID(index)),
-		}, batchOpts.innerOptions...)
-
-		output, taskErr := runner.Invoke(subCtx, input, invokeOpts...)
-		resultCh <- taskResult{index: index, output: output, err: taskErr}
+		result := b.runItem(runCtx, runner, store, gate, index, input, batchOpts)
+		if !isInterrupt(result.Err) {
+			store.discard(ctx, index)
+		}
+		record(result)
 	}
 
 	// Execute tasks based on concurrency setting
-	if b.maxConcurrency == 0 {
-		// Sequential: Run one task at a time
+	if gate != nil {
+		// Resume: every task starts at once, the gate applies the concurrency limit
+		var wg sync.WaitGroup
 		for _, idx := range indicesToProcess {
 			wg.Add(1)
+			go func(index int, input I) {
+				defer wg.Done()
+				runTask(index, input)
+			}(idx, effectiveInputs[idx])
+		}
+		wg.Wait()
+	} else if maxConcurrency == 0 {
+		// Sequential: Run one task at a time
+		for _, idx := range indicesToProcess {
 			runTask(idx, effectiveInputs[idx])
 		}
 	} else {
 		// Concurrent: Use semaphore to limit parallelism
-		sem := make(chan struct{}, b.maxConcurrency)
+		sem := make(chan struct{}, maxConcurrency)
+		var wg sync.WaitGroup
 
 		for i, idx := range indicesToProcess {
-			wg.Add(1)
 			if i == 0 {
 				// First task runs on main goroutine (optimization)
 				runTask(idx, effectiveInputs[idx])
-			} else {
-				// Subsequent tasks run in goroutines with semaphore
-				go func(index int, input I) {
-					sem <- struct{}{}
-					defer func() { <-sem }()
-					runTask(index, input)
-				}(idx, effectiveInputs[idx])
+				continue
 			}
+			// Subsequent tasks run in goroutines with semaphore
+			wg.Add(1)
+			go func(index int, input I) {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+				case <-runCtx.Done():
+					// Cancelled while waiting for a slot
+					record(ItemResult[O]{Index: index, Err: runCtx.Err()})
+					return
+				}
+				defer func() { <-sem }()
+				runTask(index, input)
+			}(idx, effectiveInputs[idx])
 		}
-	}
-
-	// Close result channel when all tasks complete
-	go func() {
 		wg.Wait()
-		close(resultCh)
-	}()
-
-	// Collect results and categorize errors
-	var normalErr error
-	var interruptErrs []error
-	completedResults := make(map[int]any)
-	interruptedIndices := make([]int, 0)
-
-	for result := range resultCh {
-		if result.err != nil {
-			if _, ok := compose.ExtractInterruptInfo(result.err); ok {
-				// Interrupt error: collect for CompositeInterrupt
-				interruptErrs = append(interruptErrs, result.err)
-				interruptedIndices = append(interruptedIndices, result.index)
-			} else if normalErr == nil {
-				// Normal error: keep first one
-				normalErr = fmt.Errorf("task %d failed: %w", result.index, result.err)
-			}
-		} else {
-			// Success: store result
-			outputs[result.index] = result.output
-			completedResults[result.index] = result.output
-		}
 	}
 
-	// Return first normal error (if any)
-	if normalErr != nil {
-		return nil, normalErr
+	// Return the failures (if the failure policy does not tolerate them)
+	if failed > maxFailures {
+		return res, newError(res)
 	}
 
 	// Return composite interrupt (if any tasks interrupted)
@@ -261,15 +325,173 @@ func (b *Node[I, O]) invoke(ctx context.Context, inputs []I, batchOpts *options)
 		for i, v := range effectiveInputs {
 			originalInputs[i] = v
 		}
+		completedResults := make(map[int]any)
+		failedErrors := make(map[int]string)
+		for _, item := range res.Items {
+			switch item.Status {
+			case ItemSucceeded:
+				completedResults[item.Index] = item.Output
+			case ItemFailed:
+				failedErrors[item.Index] = item.Err.Error()
+			}
+		}
 		state := &NodeInterruptState{
 			OriginalInputs:     originalInputs,
 			CompletedResults:   completedResults,
 			InterruptedIndices: interruptedIndices,
 			TotalCount:         len(effectiveInputs),
+			FailedErrors:       failedErrors,
+			CheckPointPrefix:   store.prefix,
+			NestedInterrupts:   nestedInterrupts,
 		}
 		// CompositeInterrupt bundles all interrupt errors with state for resume
-		return nil, compose.CompositeInterrupt(ctx, nil, state, interruptErrs...)
+		return res, compose.CompositeInterrupt(ctx, nil, state, interruptErrs...)
+	}
+
+	return res, nil
+}
+
+// policy returns the failure policy of an invocation.
+func (b *Node[I, O]) policy(batchOpts *options) FailurePolicy {
+	if batchOpts.failurePolicy != nil {
+		return *batchOpts.failurePolicy
+	}
+	return b.failurePolicy
+}
+
+// compile compiles the inner task with the checkpoint store of the sub-tasks.
+func (b *Node[I, O]) compile(ctx context.Context, store *batchBridgeStore) (compose.Runnable[I, O], error) {
+	compileOpts := append([]compose.GraphCompileOption{
+		compose.WithCheckPointStore(store),
+	}, b.innerCompileOptions...)
+
+	runner, err := b.innerTask.Compile(ctx, compileOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile inner task: %w", err)
+	}
+	return runner, nil
+}
+
+// runItem runs the inner task for the item at index, retrying it according to the retry policy.
+// The status of the returned result is left to the caller, see itemStatus.
+// A task restored from a checkpoint runs its first attempt through gate when it is not nil.
+func (b *Node[I, O]) runItem(ctx context.Context, runner compose.Runnable[I, O], store *batchBridgeStore,
+	gate *resumeGate, index int, input I, batchOpts *options) ItemResult[O] {
+	restored := store.isRestored(index)
+	if gate != nil && restored {
+		// Let the other tasks load their checkpoints even if this one never starts
+		defer gate.done(index)
+		if err := gate.enter(ctx, index); err != nil {
+			return ItemResult[O]{Index: index, Err: err}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return ItemResult[O]{Index: index, Err: err}
+	}
+
+	// Create sub-context with unique address segment for this task
+	// This enables proper interrupt ID generation (e.g., "batch_process:0")
+	subCtx := compose.AppendAddressSegment(ctx, AddressSegmentBatchProcess, strconv.Itoa(index))
+	if b.checkPointStore != nil {
+		// Make the inner task persist the inputs of its nodes in its checkpoint: nodes interrupting
+		// from their own body are rerun with their original input when the task resumes
+		subCtx, _ = compose.WithGraphInterrupt(subCtx)
 	}
 
-	return outputs, nil
+	// Combine checkpoint ID with user-provided inner options
+	invokeOpts := append([]compose.Option{
+		compose.WithCheckPointID(makeBatchCheckpointID(index)),
+	}, batchOpts.innerOptions...)
+	if b.limiterHandler != nil {
+		// Feed the ChatModel requests of the inner task to the limiter
+		invokeOpts = append(invokeOpts, compose.WithCallbacks(b.limiterHandler))
+	}
+
+	start := time.Now()
+	result := ItemResult[O]{Index: index}
+	for {
+		result.Attempts++
+		switch {
+		case gate == nil:
+			result.Output, result.Err = b.invokeAttempt(subCtx, runner, input, batchOpts.itemTimeout, invokeOpts)
+		case restored:
+			// A retry starts over: loading the checkpoint again is not safe once the tasks run
+			restored = false
+			result.Output, result.Err = b.resumeAttempt(subCtx, gate, index, runner, input, batchOpts.itemTimeout, invokeOpts)
+			store.forget(index)
+		default:
+			result.Output, result.Err = b.gatedAttempt(subCtx, gate, runner, input, batchOpts.itemTimeout, invokeOpts)
+		}
+		if result.Err == nil || result.Attempts > batchOpts.retry.MaxRetries || !batchOpts.retryable(ctx, result.Err) ||
+			!sleep(ctx, batchOpts.retry.backoff(result.Attempts)) {
+			result.Duration = time.Since(start)
+			return result
+		}
+	}
+}
+
+// itemStatus returns the status of an item that ended with err. Items stopped by the
+// cancellation of the batch are cancelled, unless ctx, the context of the caller, is done.
+func itemStatus(ctx context.Context, err error, stopped bool) ItemStatus {
+	switch {
+	case err == nil:
+		return ItemSucceeded
+	case isInterrupt(err):
+		return ItemInterrupted
+	case stopped && ctx.Err() == nil && errors.Is(err, context.Canceled):
+		return ItemCancelled
+	default:
+		return ItemFailed
+	}
+}
+
+// invokeAttempt runs the inner task once: it waits for the limiter, if any, then runs
+// within the per-item timeout if one is set.
+func (b *Node[I, O]) invokeAttempt(ctx context.Context, runner compose.Runnable[I, O], input I,
+	timeout time.Duration, opts []compose.Option) (O, error) {
+	if b.limiter != nil {
+		release, err := b.limiter.AcquireItem(ctx)
+		if err != nil {
+			var zero O
+			return zero, err
+		}
+		defer release()
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return runner.Invoke(ctx, input, opts...)
+}
+
+func isInterrupt(err error) bool {
+	_, ok := compose.ExtractInterruptInfo(err)
+	return ok
+}
+
+// hasNestedInterrupt reports whether err, the interrupt of a task of the batch node running in
+// ctx, comes from a batch node nested in the task.
+func hasNestedInterrupt(ctx context.Context, err error) bool {
+	info, ok := compose.ExtractInterruptInfo(err)
+	if !ok {
+		return false
+	}
+	depth := countBatchSegments(compose.GetCurrentAddress(ctx)) + 1
+	for _, ic := range info.InterruptContexts {
+		if countBatchSegments(ic.Address) > depth {
+			return true
+		}
+	}
+	return false
+}
+
+func countBatchSegments(addr compose.Address) int {
+	n := 0
+	for _, seg := range addr {
+		if seg.Type == AddressSegmentBatchProcess {
+			n++
+		}
+	}
+	return n
 }
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+var errOdd = errors.New("odd input")
+
+// newTestNode returns a node doubling even inputs and failing odd ones. Failures of
+// inputs < 0 block until their context is cancelled.
+func newTestNode(t *testing.T, config *NodeConfig[int, int]) *Node[int, int] {
+	t.Helper()
+	wf := compose.NewWorkflow[int, int]()
+	wf.AddLambdaNode("double", compose.InvokableLambda(func(ctx context.Context, in int) (int, error) {
+		if in < 0 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		if in%2 == 1 {
+			return 0, fmt.Errorf("input %d: %w", in, errOdd)
+		}
+		return in * 2, nil
+	})).AddInput(compose.START)
+	wf.End().AddInput("double")
+	config.InnerTask = wf
+	return NewBatchNode(config)
+}
+
+func TestFailFastCancelsSiblings(t *testing.T) {
+	node := newTestNode(t, &NodeConfig[int, int]{MaxConcurrency: 3})
+
+	res, err := node.InvokeWithResults(context.Background(), []int{1, -1, -1, 2})
+	var batchErr *Error
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if !errors.Is(err, errOdd) {
+		t.Fatalf("expected the error to wrap the item error, got %v", err)
+	}
+	if len(batchErr.Failures) != 1 || batchErr.Failures[0] == nil {
+		t.Fatalf("expected item 0 to be the only failure, got %v", batchErr.Failures)
+	}
+	for _, i := range []int{1, 2} {
+		if res.Items[i].Status != ItemCancelled || !errors.Is(res.Items[i].Err, ErrItemCancelled) {
+			t.Fatalf("expected item %d to be cancelled, got %s: %v", i, res.Items[i].Status, res.Items[i].Err)
+		}
+	}
+
+	if _, err = node.Invoke(context.Background(), []int{2, 3}); !errors.Is(err, errOdd) {
+		t.Fatalf("expected Invoke to fail, got %v", err)
+	}
+}
+
+func TestCollectAll(t *testing.T) {
+	node := newTestNode(t, &NodeConfig[int, int]{
+		MaxConcurrency: 2,
+		FailurePolicy:  FailurePolicy{Mode: CollectAll},
+	})
+
+	res, err := node.InvokeWithResults(context.Background(), []int{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := res.Count(ItemSucceeded); got != 2 {
+		t.Fatalf("expected 2 succeeded items, got %d", got)
+	}
+	if failures := res.Failures(); len(failures) != 2 || failures[0] == nil || failures[2] == nil {
+		t.Fatalf("expected items 0 and 2 to fail, got %v", failures)
+	}
+	if outputs := res.Outputs(); fmt.Sprint(outputs) != "[0 4 0 8]" {
+		t.Fatalf("unexpected outputs %v", outputs)
+	}
+}
+
+func TestMaxFailureRatio(t *testing.T) {
+	node := newTestNode(t, &NodeConfig[int, int]{})
+	inputs := []int{1, 2, 4, 6, 8, 10, 12, 14, 16, 18}
+
+	outputs, err := node.Invoke(context.Background(), inputs,
+		WithFailurePolicy(FailurePolicy{Mode: MaxFailureRatio, MaxFailureRatio: 0.1}))
+	if err != nil {
+		t.Fatalf("expected 1 of 10 failures to be tolerated, got %v", err)
+	}
+	if len(outputs) != len(inputs) || outputs[1] != 4 {
+		t.Fatalf("unexpected outputs %v", outputs)
+	}
+
+	inputs[1] = 3
+	_, err = node.Invoke(context.Background(), inputs,
+		WithFailurePolicy(FailurePolicy{Mode: MaxFailureRatio, MaxFailureRatio: 0.1}))
+	var batchErr *Error
+	if !errors.As(err, &batchErr) || len(batchErr.Failures) != 2 || batchErr.Total != 10 {
+		t.Fatalf("expected 2 of 10 failures to fail the batch, got %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[int]int)
+	errFlaky := errors.New("flaky")
+
+	// input n fails its first n attempts, except input 9 whose attempts hang
+	wf := compose.NewWorkflow[int, int]()
+	wf.AddLambdaNode("flaky", compose.InvokableLambda(func(ctx context.Context, in int) (int, error) {
+		mu.Lock()
+		calls[in]++
+		call := calls[in]
+		mu.Unlock()
+		if in == 9 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		if call <= in {
+			return 0, errFlaky
+		}
+		return in, nil
+	})).AddInput(compose.START)
+	wf.End().AddInput("flaky")
+	node := NewBatchNode(&NodeConfig[int, int]{
+		InnerTask:      wf,
+		MaxConcurrency: 4,
+		FailurePolicy:  FailurePolicy{Mode: CollectAll},
+	})
+
+	res, err := node.InvokeWithResults(context.Background(), []int{0, 1, 3, 9},
+		WithItemTimeout(20*time.Millisecond),
+		WithRetry(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, Jitter: 0.5}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []struct {
+		status   ItemStatus
+		attempts int
+	}{{ItemSucceeded, 1}, {ItemSucceeded, 2}, {ItemFailed, 3}, {ItemFailed, 3}}
+	for i, e := range expected {
+		item := res.Items[i]
+		if item.Status != e.status || item.Attempts != e.attempts {
+			t.Fatalf("item %d: expected %s after %d attempts, got %s after %d: %v", i, e.status, e.attempts,
+				item.Status, item.Attempts, item.Err)
+		}
+	}
+	if !errors.Is(res.Items[3].Err, context.DeadlineExceeded) || res.Items[3].Duration < 60*time.Millisecond {
+		t.Fatalf("expected item 3 to time out 3 times, got %v after %v", res.Items[3].Err, res.Items[3].Duration)
+	}
+
+	// errors rejected by the predicate are not retried
+	res, _ = node.InvokeWithResults(context.Background(), []int{5},
+		WithRetry(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond}),
+		WithRetryIf(func(err error) bool { return !errors.Is(err, errFlaky) }),
+	)
+	if res.Items[0].Attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", res.Items[0].Attempts)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempts, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond,
+		4: 800 * time.Millisecond, 5: time.Second, 10: time.Second} {
+		if got := p.backoff(attempts); got != want {
+			t.Fatalf("backoff after %d attempts: expected %v, got %v", attempts, want, got)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(2); got < 100*time.Millisecond || got > 300*time.Millisecond {
+			t.Fatalf("jittered backoff %v out of range", got)
+		}
+	}
+}
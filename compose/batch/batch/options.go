@@ -16,13 +16,28 @@
 
 package batch
 
-import "github.com/cloudwego/eino/compose"
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+)
 
 // options holds runtime configuration for a batch invocation.
 type options struct {
 	// innerOptions are compose.Option values passed to each inner task invocation.
 	// These are request-time options (vs compile-time options in NodeConfig).
 	innerOptions []compose.Option
+
+	// failurePolicy overrides NodeConfig.FailurePolicy when set.
+	failurePolicy *FailurePolicy
+
+	// itemTimeout limits each attempt of an item, 0 means no limit.
+	itemTimeout time.Duration
+
+	// retry and retryIf decide whether failed attempts are retried.
+	retry   RetryPolicy
+	retryIf func(error) bool
 }
 
 // Option is a function that configures batch invocation options.
@@ -46,6 +61,61 @@ func WithInnerOptions(opts ...compose.Option) Option {
 	}
 }
 
+// WithFailurePolicy overrides NodeConfig.FailurePolicy for this invocation.
+//
+// Example:
+//
+//	// tolerate up to 1% of failed items, and get them from the result
+//	res, err := batchNode.InvokeWithResults(ctx, inputs,
+//	    batch.WithFailurePolicy(batch.FailurePolicy{Mode: batch.MaxFailureRatio, MaxFailureRatio: 0.01}),
+//	)
+func WithFailurePolicy(policy FailurePolicy) Option {
+	return func(o *options) {
+		o.failurePolicy = &policy
+	}
+}
+
+// WithItemTimeout limits how long each attempt of an item may run. An attempt running
+// longer has its context cancelled with context.DeadlineExceeded, and is retried like
+// any other failed attempt when a retry policy is set.
+func WithItemTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.itemTimeout = timeout
+	}
+}
+
+// WithRetry retries the items failing with a retryable error, see WithRetryIf.
+// Interrupted items are never retried, and neither are the items cancelled with the batch.
+//
+// Example:
+//
+//	// up to 3 retries after 0.5s, 1s and 2s, each ±20%
+//	results, err := batchNode.Invoke(ctx, inputs,
+//	    batch.WithItemTimeout(30*time.Second),
+//	    batch.WithRetry(batch.RetryPolicy{MaxRetries: 3, InitialBackoff: 500 * time.Millisecond, Jitter: 0.2}),
+//	)
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithRetryIf sets which errors are retried when a retry policy is set. By default every error is.
+func WithRetryIf(retryable func(err error) bool) Option {
+	return func(o *options) {
+		o.retryIf = retryable
+	}
+}
+
+// retryable reports whether a failed attempt may be retried. Interrupts are resumed rather
+// than retried, and nothing is retried once the batch is cancelled.
+func (o *options) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || isInterrupt(err) {
+		return false
+	}
+	return o.retryIf == nil || o.retryIf(err)
+}
+
 // applyBatchOptions creates an options struct from the given Option functions.
 func applyBatchOptions(opts ...Option) *options {
 	o := &options{}
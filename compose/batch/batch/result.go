@@ -0,0 +1,175 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FailureMode selects how a batch reacts to items failing with a normal error.
+type FailureMode int
+
+const (
+	// FailFast fails the batch on the first failed item, and cancels the items still running
+	// or waiting through their context. This is the default.
+	FailFast FailureMode = iota
+	// CollectAll runs every item and never fails the batch because of failed items.
+	CollectAll
+	// MaxFailureRatio fails the batch like FailFast once more than
+	// FailurePolicy.MaxFailureRatio of the items failed.
+	MaxFailureRatio
+)
+
+// FailurePolicy decides when failed items fail the whole batch.
+// Interrupted items are not failures: they are resumed later.
+type FailurePolicy struct {
+	Mode FailureMode
+
+	// MaxFailureRatio is the ratio of failed items tolerated in MaxFailureRatio mode, in [0, 1].
+	// For example 0.01 lets 100 of 10,000 items fail.
+	MaxFailureRatio float64
+}
+
+// maxFailures returns how many of total items may fail without failing the batch.
+func (p FailurePolicy) maxFailures(total int) int {
+	switch p.Mode {
+	case CollectAll:
+		return total
+	case MaxFailureRatio:
+		ratio := math.Max(0, math.Min(1, p.MaxFailureRatio))
+		return int(math.Floor(ratio * float64(total)))
+	default:
+		return 0
+	}
+}
+
+// ItemStatus is the outcome of one input item.
+type ItemStatus string
+
+const (
+	ItemSucceeded   ItemStatus = "succeeded"
+	ItemFailed      ItemStatus = "failed"
+	ItemInterrupted ItemStatus = "interrupted"
+	// ItemCancelled items were not run, or stopped, because the failure policy failed the batch.
+	ItemCancelled ItemStatus = "cancelled"
+)
+
+// ErrItemCancelled is the error of the items cancelled by the failure policy.
+var ErrItemCancelled = errors.New("cancelled after the batch failed")
+
+// ItemResult is the outcome of the input item at Index.
+type ItemResult[O any] struct {
+	Index  int
+	Status ItemStatus
+	// Output is set when the item succeeded.
+	Output O
+	// Err is the error of the item, nil when it succeeded.
+	Err error
+	// Attempts is the number of times the inner task ran in this invocation, 0 for the items
+	// completed before an interrupt or cancelled before they started.
+	Attempts int
+	// Duration is the time spent on the item in this invocation, waits between attempts included.
+	Duration time.Duration
+}
+
+// Result holds the outcome of every input item, in input order.
+type Result[O any] struct {
+	Items []ItemResult[O]
+}
+
+// Outputs returns the output of every item, the zero value for the items that did not succeed.
+func (r *Result[O]) Outputs() []O {
+	outputs := make([]O, len(r.Items))
+	for i, item := range r.Items {
+		outputs[i] = item.Output
+	}
+	return outputs
+}
+
+// Count returns the number of items with the given status.
+func (r *Result[O]) Count(status ItemStatus) int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failures maps the index of every failed item to its error.
+func (r *Result[O]) Failures() map[int]error {
+	failures := make(map[int]error)
+	for _, item := range r.Items {
+		if item.Status == ItemFailed {
+			failures[item.Index] = item.Err
+		}
+	}
+	return failures
+}
+
+// Error is returned when the failure policy fails the batch.
+type Error struct {
+	// Total is the number of input items.
+	Total int
+	// Failures maps the index of every failed item to its error.
+	Failures map[int]error
+	// Cancelled is the number of items cancelled after the batch failed.
+	Cancelled int
+}
+
+func newError[O any](r *Result[O]) *Error {
+	return &Error{
+		Total:     len(r.Items),
+		Failures:  r.Failures(),
+		Cancelled: r.Count(ItemCancelled),
+	}
+}
+
+func (e *Error) indices() []int {
+	indices := make([]int, 0, len(e.Failures))
+	for i := range e.Failures {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+func (e *Error) Error() string {
+	indices := e.indices()
+	if len(indices) == 0 {
+		return "batch failed"
+	}
+	first := fmt.Sprintf("task %d failed: %v", indices[0], e.Failures[indices[0]])
+	if len(indices) == 1 {
+		return first
+	}
+	return fmt.Sprintf("%d of %d tasks failed, first %s", len(indices), e.Total, first)
+}
+
+// Unwrap returns the errors of the failed items, so that errors.Is and errors.As look into them.
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, i := range e.indices() {
+		errs = append(errs, e.Failures[i])
+	}
+	return errs
+}
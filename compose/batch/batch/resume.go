@@ -0,0 +1,171 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// resumeGate runs the tasks restored from a checkpoint with the configured concurrency.
+// Loading a checkpoint updates the resume info shared by the whole run, and every task reads
+// it when it starts its nodes: the tasks load their checkpoints one at a time, and none of them
+// runs its nodes before all of them are loaded.
+type resumeGate struct {
+	loading chan struct{}  // held by the task loading its checkpoint
+	pending sync.WaitGroup // tasks that did not load their checkpoint yet
+	slots   chan struct{}  // one per running task, up to MaxConcurrency
+
+	mu      sync.Mutex
+	loaded  map[int]bool
+	holding int // index of the task holding the loading turn, -1 if none
+}
+
+// newResumeGate creates a gate for the given number of restored tasks.
+func newResumeGate(tasks, maxConcurrency int) *resumeGate {
+	g := &resumeGate{
+		loading: make(chan struct{}, 1),
+		slots:   make(chan struct{}, maxConcurrency),
+		loaded:  make(map[int]bool, tasks),
+		holding: -1,
+	}
+	g.pending.Add(tasks)
+	return g
+}
+
+// enter waits for the loading turn of the task at index. The task must not read the resume
+// info, e.g. with compose.AppendAddressSegment, before it has the turn.
+func (g *resumeGate) enter(ctx context.Context, index int) error {
+	select {
+	case g.loading <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.holding = index
+	return nil
+}
+
+// done marks the task at index as loaded, whether it loaded its checkpoint or ended before,
+// and passes the loading turn on if the task holds it.
+func (g *resumeGate) done(index int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.loaded[index] {
+		return
+	}
+	g.loaded[index] = true
+	g.pending.Done()
+	if g.holding == index {
+		g.holding = -1
+		<-g.loading
+	}
+}
+
+// acquire waits until every task is loaded, then for a slot and the limiter, if any, and applies
+// the per-item timeout. The returned func releases them.
+func (b *Node[I, O]) acquire(ctx context.Context, gate *resumeGate, timeout time.Duration) (context.Context, func(), error) {
+	gate.pending.Wait()
+	select {
+	case gate.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx, nil, ctx.Err()
+	}
+
+	releaseItem := func() {}
+	if b.limiter != nil {
+		var err error
+		if releaseItem, err = b.limiter.AcquireItem(ctx); err != nil {
+			<-gate.slots
+			return ctx, nil, err
+		}
+	}
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {
+		cancel()
+		releaseItem()
+		<-gate.slots
+	}, nil
+}
+
+// resumeAttempt runs the first attempt of the task at index, restored from its checkpoint, once
+// the task entered gate. The checkpoint is loaded when the inner task starts, before its graph
+// start callbacks: the task passes the loading turn on in its start callback, then waits there
+// for its slot.
+func (b *Node[I, O]) resumeAttempt(ctx context.Context, gate *resumeGate, index int, runner compose.Runnable[I, O],
+	input I, timeout time.Duration, opts []compose.Option) (O, error) {
+	var zero O
+	defer gate.done(index)
+
+	// The handler also applies to the nodes of the inner task: only the graph start, the first, counts
+	var startOnce sync.Once
+	var release func()
+	var startErr error
+	onStart := func(ctx context.Context) context.Context {
+		startOnce.Do(func() {
+			gate.done(index)
+			ctx, release, startErr = b.acquire(ctx, gate, timeout)
+			if startErr != nil {
+				// Cancel the run, its result is replaced by startErr
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				cancel()
+			}
+		})
+		return ctx
+	}
+	handler := callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, _ *callbacks.RunInfo, _ callbacks.CallbackInput) context.Context {
+			return onStart(ctx)
+		}).
+		OnStartWithStreamInputFn(func(ctx context.Context, _ *callbacks.RunInfo,
+			input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+			input.Close()
+			return onStart(ctx)
+		}).
+		Build()
+
+	out, err := runner.Invoke(ctx, input, append(opts, compose.WithCallbacks(handler))...)
+	if release != nil {
+		release()
+	}
+	if startErr != nil {
+		return zero, startErr
+	}
+	return out, err
+}
+
+// gatedAttempt runs a retry of a restored task, from the start, within the limits of gate.
+func (b *Node[I, O]) gatedAttempt(ctx context.Context, gate *resumeGate, runner compose.Runnable[I, O], input I,
+	timeout time.Duration, opts []compose.Option) (O, error) {
+	ctx, release, err := b.acquire(ctx, gate, timeout)
+	if err != nil {
+		var zero O
+		return zero, err
+	}
+	defer release()
+	return runner.Invoke(ctx, input, opts...)
+}
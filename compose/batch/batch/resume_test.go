@@ -0,0 +1,329 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (m *memoryStore) Get(_ context.Context, id string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[id]
+	return data, ok, nil
+}
+
+func (m *memoryStore) Set(_ context.Context, id string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[id] = data
+	return nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, id)
+	return nil
+}
+
+// checkCleaned fails if checkpoints of batch tasks are left in the store.
+func (m *memoryStore) checkCleaned(t *testing.T) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id := range m.data {
+		if strings.HasPrefix(id, "batch:") {
+			t.Fatalf("checkpoint %s left in the store", id)
+		}
+	}
+}
+
+// callCounter counts the runs of the expensive steps, e.g. LLM calls, by key.
+type callCounter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *callCounter) inc(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[key]++
+}
+
+// checkOnce fails unless every step ran exactly once.
+func (c *callCounter) checkOnce(t *testing.T, expected int) {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.calls) != expected {
+		t.Fatalf("expected %d steps to run, got %v", expected, c.calls)
+	}
+	for key, n := range c.calls {
+		if n != 1 {
+			t.Fatalf("expected step %s to run once, got %d", key, n)
+		}
+	}
+}
+
+// draftNode simulates an LLM call drafting a text for the input.
+func draftNode(calls *callCounter, prefix string) *compose.Lambda {
+	return compose.InvokableLambda(func(ctx context.Context, in int) (string, error) {
+		calls.inc(fmt.Sprintf("%s%d", prefix, in))
+		return fmt.Sprintf("%s%d", prefix, in), nil
+	})
+}
+
+// reviewNode interrupts for the drafts of even inputs, until it is resumed with a decision.
+func reviewNode() *compose.Lambda {
+	return compose.InvokableLambda(func(ctx context.Context, draft string) (string, error) {
+		if draft == "" {
+			return "", fmt.Errorf("review got no draft")
+		}
+		if (draft[len(draft)-1]-'0')%2 == 1 {
+			return draft, nil
+		}
+		isResumeTarget, hasData, decision := compose.GetResumeContext[string](ctx)
+		if isResumeTarget && hasData {
+			return draft + ":" + decision, nil
+		}
+		return "", compose.Interrupt(ctx, draft)
+	})
+}
+
+// runParent runs node as the only node of a parent graph with store as checkpoint store, resuming
+// it with a decision for every interrupt accepted by decide until it completes.
+func runParent[I, O any](t *testing.T, store *memoryStore, node *Node[I, O], inputs []I,
+	decide func(round int, info string) (string, bool)) ([]O, int) {
+	t.Helper()
+	g := compose.NewGraph[[]I, []O]()
+	_ = g.AddLambdaNode("batch", compose.InvokableLambda(func(ctx context.Context, in []I) ([]O, error) {
+		return node.Invoke(ctx, in)
+	}))
+	_ = g.AddEdge(compose.START, "batch")
+	_ = g.AddEdge("batch", compose.END)
+	runner, err := g.Compile(context.Background(), compose.WithCheckPointStore(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for round := 0; round < 10; round++ {
+		out, err := runner.Invoke(ctx, inputs, compose.WithCheckPointID("run"))
+		if err == nil {
+			return out, round
+		}
+		info, ok := compose.ExtractInterruptInfo(err)
+		if !ok {
+			t.Fatalf("round %d: unexpected error: %v", round, err)
+		}
+		decisions := make(map[string]any)
+		for _, ic := range info.InterruptContexts {
+			if d, ok := decide(round, fmt.Sprint(ic.Info)); ok {
+				decisions[ic.ID] = d
+			}
+		}
+		ctx = compose.BatchResumeWithData(context.Background(), decisions)
+		inputs = nil
+	}
+	t.Fatalf("still interrupted after 10 rounds")
+	return nil, 0
+}
+
+func approveAll(int, string) (string, bool) { return "ok", true }
+
+func TestResumeContinuesInnerWorkflow(t *testing.T) {
+	calls := &callCounter{}
+	wf := compose.NewWorkflow[int, string]()
+	wf.AddLambdaNode("draft", draftNode(calls, "d")).AddInput(compose.START)
+	wf.AddLambdaNode("review", reviewNode()).AddInput("draft")
+	wf.End().AddInput("review")
+	store := &memoryStore{data: map[string][]byte{}}
+	node := NewBatchNode(&NodeConfig[int, string]{InnerTask: wf, MaxConcurrency: 2, CheckPointStore: store})
+
+	out, rounds := runParent(t, store, node, []int{0, 1, 2, 3}, approveAll)
+	if rounds != 1 || strings.Join(out, ",") != "d0:ok,d1,d2:ok,d3" {
+		t.Fatalf("unexpected results %v after %d resumes", out, rounds)
+	}
+	calls.checkOnce(t, 4)
+	store.checkCleaned(t)
+}
+
+func TestResumeWithoutCheckPointStore(t *testing.T) {
+	calls := &callCounter{}
+	wf := compose.NewWorkflow[int, string]()
+	wf.AddLambdaNode("draft", draftNode(calls, "d")).AddInput(compose.START)
+	wf.AddLambdaNode("review", reviewNode()).AddInput("draft")
+	wf.End().AddInput("review")
+	node := NewBatchNode(&NodeConfig[int, string]{InnerTask: wf, MaxConcurrency: 2})
+
+	out, rounds := runParent(t, &memoryStore{data: map[string][]byte{}}, node, []int{0, 1, 2, 3}, approveAll)
+	if rounds != 1 || strings.Join(out, ",") != "d0:ok,d1,d2:ok,d3" {
+		t.Fatalf("unexpected results %v after %d resumes", out, rounds)
+	}
+	// the interrupted tasks are rerun from the start
+	if calls.calls["d0"] != 2 || calls.calls["d1"] != 1 || calls.calls["d2"] != 2 {
+		t.Fatalf("unexpected calls %v", calls.calls)
+	}
+}
+
+func TestResumeContinuesSubGraph(t *testing.T) {
+	calls := &callCounter{}
+	sub := compose.NewGraph[string, string]()
+	_ = sub.AddLambdaNode("polish", compose.InvokableLambda(func(ctx context.Context, draft string) (string, error) {
+		calls.inc("p" + draft)
+		return draft, nil
+	}))
+	_ = sub.AddLambdaNode("review", reviewNode())
+	_ = sub.AddEdge(compose.START, "polish")
+	_ = sub.AddEdge("polish", "review")
+	_ = sub.AddEdge("review", compose.END)
+
+	g := compose.NewGraph[int, string]()
+	_ = g.AddLambdaNode("draft", draftNode(calls, "d"))
+	_ = g.AddGraphNode("sub", sub)
+	_ = g.AddEdge(compose.START, "draft")
+	_ = g.AddEdge("draft", "sub")
+	_ = g.AddEdge("sub", compose.END)
+	store := &memoryStore{data: map[string][]byte{}}
+	node := NewBatchNode(&NodeConfig[int, string]{InnerTask: g, MaxConcurrency: 3, CheckPointStore: store})
+
+	// only the first interrupt is decided at first, the other one interrupts again
+	decide := func(round int, info string) (string, bool) {
+		return "ok", round > 0 || info == "d2"
+	}
+	out, rounds := runParent(t, store, node, []int{1, 2, 4}, decide)
+	if rounds != 2 || strings.Join(out, ",") != "d1,d2:ok,d4:ok" {
+		t.Fatalf("unexpected results %v after %d resumes", out, rounds)
+	}
+	calls.checkOnce(t, 6)
+	store.checkCleaned(t)
+}
+
+func TestResumeConcurrently(t *testing.T) {
+	calls := &callCounter{}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	sub := compose.NewGraph[string, string]()
+	_ = sub.AddLambdaNode("polish", compose.InvokableLambda(func(ctx context.Context, draft string) (string, error) {
+		calls.inc("p" + draft)
+		return draft, nil
+	}))
+	_ = sub.AddLambdaNode("review", reviewNode())
+	// publish measures how many tasks run at once after they resumed
+	_ = sub.AddLambdaNode("publish", compose.InvokableLambda(func(ctx context.Context, text string) (string, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return text, nil
+	}))
+	_ = sub.AddEdge(compose.START, "polish")
+	_ = sub.AddEdge("polish", "review")
+	_ = sub.AddEdge("review", "publish")
+	_ = sub.AddEdge("publish", compose.END)
+
+	g := compose.NewGraph[int, string]()
+	_ = g.AddLambdaNode("draft", draftNode(calls, "d"))
+	_ = g.AddGraphNode("sub", sub)
+	_ = g.AddEdge(compose.START, "draft")
+	_ = g.AddEdge("draft", "sub")
+	_ = g.AddEdge("sub", compose.END)
+	store := &memoryStore{data: map[string][]byte{}}
+	node := NewBatchNode(&NodeConfig[int, string]{InnerTask: g, MaxConcurrency: 4, CheckPointStore: store})
+
+	inputs := make([]int, 20)
+	for i := range inputs {
+		inputs[i] = i * 10
+	}
+	out, rounds := runParent(t, store, node, inputs, approveAll)
+	if rounds != 1 || len(out) != 20 || out[19] != "d190:ok" {
+		t.Fatalf("unexpected results %v after %d resumes", out, rounds)
+	}
+	calls.checkOnce(t, 40)
+	store.checkCleaned(t)
+	if maxRunning < 2 || maxRunning > 4 {
+		t.Fatalf("expected resumed tasks to run up to 4 at once, got %d", maxRunning)
+	}
+}
+
+func TestResumeNestedBatch(t *testing.T) {
+	calls := &callCounter{}
+	innerWf := compose.NewWorkflow[int, string]()
+	innerWf.AddLambdaNode("draft", draftNode(calls, "d")).AddInput(compose.START)
+	innerWf.AddLambdaNode("review", reviewNode()).AddInput("draft")
+	innerWf.End().AddInput("review")
+	store := &memoryStore{data: map[string][]byte{}}
+	inner := NewBatchNode(&NodeConfig[int, string]{InnerTask: innerWf, MaxConcurrency: 2, CheckPointStore: store})
+
+	// every outer item plans its inputs (an LLM call), then runs the nested batch over them
+	outerWf := compose.NewWorkflow[int, string]()
+	outerWf.AddLambdaNode("plan", compose.InvokableLambda(func(ctx context.Context, in int) ([]int, error) {
+		calls.inc(fmt.Sprintf("plan%d", in))
+		return []int{in*10 + 1, in*10 + 2, in*10 + 4}, nil
+	})).AddInput(compose.START)
+	outerWf.AddLambdaNode("nested", compose.InvokableLambda(func(ctx context.Context, in []int) (string, error) {
+		out, err := inner.Invoke(ctx, in)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(out, "+"), nil
+	})).AddInput("plan")
+	outerWf.End().AddInput("nested")
+	outer := NewBatchNode(&NodeConfig[int, string]{InnerTask: outerWf, MaxConcurrency: 2, CheckPointStore: store})
+
+	var mu sync.Mutex
+	var asked []string
+	decide := func(round int, info string) (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		asked = append(asked, fmt.Sprintf("%d:%s", round, info))
+		// decide one interrupt per round, to resume at every depth several times
+		return "ok", info == []string{"d12", "d14", "d22", "d24"}[round]
+	}
+	out, rounds := runParent(t, store, outer, []int{1, 2}, decide)
+	if rounds != 4 || strings.Join(out, ",") != "d11+d12:ok+d14:ok,d21+d22:ok+d24:ok" {
+		t.Fatalf("unexpected results %v after %d resumes", out, rounds)
+	}
+	calls.checkOnce(t, 8)
+	store.checkCleaned(t)
+
+	sort.Strings(asked)
+	if len(asked) != 10 {
+		t.Fatalf("expected 4+3+2+1 interrupts to be reported, got %v", asked)
+	}
+}
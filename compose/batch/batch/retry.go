@@ -0,0 +1,75 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 10 * time.Second
+)
+
+// RetryPolicy retries the items whose inner task failed, waiting an exponential backoff
+// between attempts: InitialBackoff, then twice as long after every failed attempt, up to MaxBackoff.
+type RetryPolicy struct {
+	// MaxRetries is the number of attempts after the first one. 0 disables retries.
+	MaxRetries int
+
+	// InitialBackoff is the wait before the first retry. Defaults to 200ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts. Defaults to 10s.
+	MaxBackoff time.Duration
+
+	// Jitter randomizes every wait by up to this fraction, in [0, 1], so that items failing
+	// together do not retry together. For example 0.2 waits between 80% and 120% of the backoff.
+	Jitter float64
+}
+
+// backoff returns the wait after the given number of failed attempts.
+func (p *RetryPolicy) backoff(attempts int) time.Duration {
+	initial, maxBackoff := p.InitialBackoff, p.MaxBackoff
+	if initial <= 0 {
+		initial = defaultInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	d := math.Min(float64(initial)*math.Pow(2, float64(attempts-1)), float64(maxBackoff))
+	if jitter := math.Max(0, math.Min(1, p.Jitter)); jitter > 0 {
+		d *= 1 + jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// sleep waits for d, and returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -18,10 +18,14 @@ package batch
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+
+	"github.com/cloudwego/eino/compose"
 )
 
 // batchBridgeStore implements compose.CheckPointStore for batch processing.
@@ -29,20 +33,80 @@ import (
 // to have its own checkpoint namespace.
 //
 // This store is used internally by BatchNode and is not meant for external use.
-// For interrupt/resume, the BatchNode stores its state via CompositeInterrupt,
-// not through this checkpoint store.
+// Without a backing store it only lives for one invocation. With the CheckPointStore
+// of the node as backing store, the checkpoints are kept there under a prefix saved in
+// the interrupt state of the node, so that the interrupted tasks resume from them.
 type batchBridgeStore struct {
 	mu   sync.RWMutex
-	data map[int][]byte // index -> checkpoint data
+	data map[int][]byte // index -> checkpoint data, when there is no backing store
+
+	backing  compose.CheckPointStore
+	prefix   string
+	restored map[int]bool // indices resuming from a checkpoint of the backing store
 }
 
 // newBatchBridgeStore creates a new empty checkpoint store.
 func newBatchBridgeStore() *batchBridgeStore {
 	return &batchBridgeStore{
-		data: make(map[int][]byte),
+		data:     make(map[int][]byte),
+		restored: make(map[int]bool),
 	}
 }
 
+// newBackedBatchBridgeStore creates a store keeping the checkpoints in backing. A new prefix is
+// generated when prefix is empty; otherwise the tasks at the restored indices resume from the
+// checkpoints saved under prefix.
+func newBackedBatchBridgeStore(backing compose.CheckPointStore, prefix string, restored []int) (*batchBridgeStore, error) {
+	s := newBatchBridgeStore()
+	s.backing = backing
+	s.prefix = prefix
+	if s.prefix == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return nil, fmt.Errorf("failed to generate checkpoint prefix: %w", err)
+		}
+		s.prefix = "batch:" + hex.EncodeToString(id) + ":"
+		return s, nil
+	}
+	for _, index := range restored {
+		s.restored[index] = true
+	}
+	return s, nil
+}
+
+// isRestored reports whether the task at index resumes from a checkpoint.
+func (m *batchBridgeStore) isRestored(index int) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.restored[index]
+}
+
+// forget makes the task at index run from the start if it runs again, keeping its checkpoint
+// in the backing store until it is discarded or overwritten.
+func (m *batchBridgeStore) forget(index int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.restored, index)
+}
+
+// discard drops the checkpoint of the task at index, which then runs from the start if it runs
+// again. It is deleted from the backing store when the store supports it.
+func (m *batchBridgeStore) discard(ctx context.Context, index int) {
+	m.mu.Lock()
+	delete(m.data, index)
+	delete(m.restored, index)
+	m.mu.Unlock()
+
+	if deleter, ok := m.backing.(checkPointDeleter); ok {
+		_ = deleter.Delete(ctx, m.prefix+makeBatchCheckpointID(index))
+	}
+}
+
+// checkPointDeleter is implemented by the checkpoint stores able to delete a checkpoint.
+type checkPointDeleter interface {
+	Delete(ctx context.Context, checkPointID string) error
+}
+
 // makeBatchCheckpointID creates a checkpoint ID for a given batch index.
 // Format: "batch_0", "batch_1", etc.
 func makeBatchCheckpointID(index int) string {
@@ -61,12 +125,23 @@ func parseBatchIndex(checkPointID string) (int, error) {
 
 // Get retrieves checkpoint data for a batch index.
 // Implements compose.CheckPointStore interface.
-func (m *batchBridgeStore) Get(_ context.Context, checkPointID string) ([]byte, bool, error) {
+func (m *batchBridgeStore) Get(ctx context.Context, checkPointID string) ([]byte, bool, error) {
 	index, err := parseBatchIndex(checkPointID)
 	if err != nil {
 		return nil, false, err
 	}
 
+	if m.backing != nil {
+		// only the checkpoints saved before the interrupt, or during this invocation, are used
+		m.mu.RLock()
+		restored := m.restored[index]
+		m.mu.RUnlock()
+		if !restored {
+			return nil, false, nil
+		}
+		return m.backing.Get(ctx, m.prefix+checkPointID)
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -76,12 +151,21 @@ func (m *batchBridgeStore) Get(_ context.Context, checkPointID string) ([]byte,
 
 // Set stores checkpoint data for a batch index.
 // Implements compose.CheckPointStore interface.
-func (m *batchBridgeStore) Set(_ context.Context, checkPointID string, checkPoint []byte) error {
+func (m *batchBridgeStore) Set(ctx context.Context, checkPointID string, checkPoint []byte) error {
 	index, err := parseBatchIndex(checkPointID)
 	if err != nil {
 		return err
 	}
 
+	if m.backing != nil {
+		if err = m.backing.Set(ctx, m.prefix+checkPointID, checkPoint); err != nil {
+			return err
+		}
+		// an interrupted task resumes from this checkpoint on the next resume, not before:
+		// the node only saves the indices of the interrupted tasks in its state
+		return nil
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
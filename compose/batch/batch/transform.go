@@ -0,0 +1,148 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/schema"
+)
+
+// Indexed is an item result emitted by Transform. Index is the position of the item in the input stream.
+type Indexed[O any] = ItemResult[O]
+
+// Transform processes the items of input as they arrive, and emits the result of every item
+// as soon as it is done, so in completion order rather than input order. At most MaxConcurrency
+// items (one if 0) are in flight at once, and the input is read no faster than the results are
+// received, so that neither the inputs nor the outputs are ever held in memory as a whole.
+//
+// Failed items are emitted with their error, and the failure policy, retries and timeouts apply
+// as in Invoke. When the failure policy fails the batch, the stream ends with a *Error once the
+// items still in flight are emitted. With MaxFailureRatio, the ratio can only be checked at
+// the end of the input. Input stream errors and the cancellation of ctx also end the stream.
+//
+// Interrupted items are emitted with their interrupt error but, unlike with Invoke, cannot be
+// resumed: use Invoke for inner tasks that interrupt.
+//
+// Closing the returned stream before its end cancels the items in flight and stops reading input.
+func (b *Node[I, O]) Transform(ctx context.Context, input *schema.StreamReader[I], opts ...Option) *schema.StreamReader[Indexed[O]] {
+	batchOpts := applyBatchOptions(opts...)
+
+	// Setup callbacks for batch-level monitoring
+	ctx = callbacks.EnsureRunInfo(ctx, b.name, ComponentOfBatchNode)
+	ctx, input = callbacks.OnStartWithStreamInput(ctx, input)
+
+	limit := max(b.maxConcurrency, 1)
+	output, writer := schema.Pipe[Indexed[O]](limit)
+	go b.transform(ctx, input, writer, batchOpts, limit)
+
+	_, output = callbacks.OnEndWithStreamOutput(ctx, output)
+	return output
+}
+
+// transform is the internal implementation of Transform, it runs until the input or the batch ends.
+func (b *Node[I, O]) transform(ctx context.Context, input *schema.StreamReader[I], writer *schema.StreamWriter[Indexed[O]],
+	batchOpts *options, limit int) {
+	defer writer.Close()
+	defer input.Close()
+
+	store := newBatchBridgeStore()
+	runner, err := b.compile(ctx, store)
+	if err != nil {
+		writer.Send(Indexed[O]{}, err)
+		return
+	}
+
+	// Failing the batch, or the consumer going away, cancels runCtx
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	policy := b.policy(batchOpts)
+	var mu sync.Mutex
+	failures := make(map[int]error)
+	cancelled := 0
+	stopped := false
+
+	// emit sends the result of an item after applying the failure policy
+	emit := func(result Indexed[O]) {
+		mu.Lock()
+		result.Status = itemStatus(ctx, result.Err, stopped)
+		switch result.Status {
+		case ItemCancelled:
+			result.Err = ErrItemCancelled
+			cancelled++
+		case ItemFailed:
+			failures[result.Index] = result.Err
+			if policy.Mode == FailFast && !stopped {
+				stopped = true
+				cancel()
+			}
+		}
+		mu.Unlock()
+
+		if closed := writer.Send(result, nil); closed {
+			cancel()
+		}
+	}
+
+	// Every slot of sem is held by an item from the moment it is read until its result is sent
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	var inputErr error
+	total := 0
+	for ; ; total++ {
+		select {
+		case sem <- struct{}{}:
+		case <-runCtx.Done():
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+
+		in, err := input.Recv()
+		if err != nil {
+			<-sem
+			if !errors.Is(err, io.EOF) {
+				inputErr = fmt.Errorf("failed to read input %d: %w", total, err)
+				cancel()
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, in I) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			emit(b.runItem(runCtx, runner, store, nil, index, in, batchOpts))
+		}(total, in)
+	}
+	wg.Wait()
+
+	switch {
+	case inputErr != nil:
+		writer.Send(Indexed[O]{}, inputErr)
+	case len(failures) > policy.maxFailures(total):
+		writer.Send(Indexed[O]{}, &Error{Total: total, Failures: failures, Cancelled: cancelled})
+	case ctx.Err() != nil:
+		writer.Send(Indexed[O]{}, ctx.Err())
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+)
+
+// recvAll receives every result of stream, and the error ending it if any.
+func recvAll[O any](stream *schema.StreamReader[Indexed[O]]) ([]Indexed[O], error) {
+	defer stream.Close()
+	var items []Indexed[O]
+	for {
+		item, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+}
+
+func TestTransformBoundsInFlight(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	wf := compose.NewWorkflow[int, int]()
+	wf.AddLambdaNode("slow", compose.InvokableLambda(func(ctx context.Context, in int) (int, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		// later inputs finish first
+		time.Sleep(time.Duration(10-in%10) * time.Millisecond)
+		return in * 2, nil
+	})).AddInput(compose.START)
+	wf.End().AddInput("slow")
+	node := NewBatchNode(&NodeConfig[int, int]{InnerTask: wf, MaxConcurrency: 3})
+
+	inputs := make([]int, 20)
+	for i := range inputs {
+		inputs[i] = i
+	}
+	items, err := recvAll(node.Transform(context.Background(), schema.StreamReaderFromArray(inputs)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != len(inputs) {
+		t.Fatalf("expected %d results, got %d", len(inputs), len(items))
+	}
+	seen := make(map[int]bool)
+	inOrder := true
+	for i, item := range items {
+		if item.Status != ItemSucceeded || item.Output != item.Index*2 {
+			t.Fatalf("unexpected result %+v", item)
+		}
+		seen[item.Index] = true
+		inOrder = inOrder && item.Index == i
+	}
+	if len(seen) != len(inputs) {
+		t.Fatalf("expected every index once, got %v", seen)
+	}
+	if inOrder {
+		t.Fatalf("expected results in completion order")
+	}
+	if m := maxInFlight.Load(); m > 3 {
+		t.Fatalf("expected at most 3 items in flight, got %d", m)
+	}
+}
+
+func TestTransformFailFast(t *testing.T) {
+	node := newTestNode(t, &NodeConfig[int, int]{MaxConcurrency: 2})
+
+	// input 1 fails while input -1 hangs until cancelled, inputs after them are never read
+	items, err := recvAll(node.Transform(context.Background(), schema.StreamReaderFromArray([]int{-1, 1, 2, 4, 6})))
+	var batchErr *Error
+	if !errors.As(err, &batchErr) || len(batchErr.Failures) != 1 || batchErr.Failures[1] == nil {
+		t.Fatalf("expected item 1 to fail the batch, got %v", err)
+	}
+	statuses := make(map[int]ItemStatus)
+	for _, item := range items {
+		statuses[item.Index] = item.Status
+	}
+	if statuses[0] != ItemCancelled || statuses[1] != ItemFailed || len(statuses) > 3 {
+		t.Fatalf("unexpected results %v", statuses)
+	}
+
+	// with CollectAll, failures are emitted and the stream ends normally
+	items, err = recvAll(node.Transform(context.Background(), schema.StreamReaderFromArray([]int{1, 2, 3}),
+		WithFailurePolicy(FailurePolicy{Mode: CollectAll})))
+	if err != nil || len(items) != 3 {
+		t.Fatalf("expected 3 results and no error, got %d, %v", len(items), err)
+	}
+}
+
+func TestTransformConsumerClose(t *testing.T) {
+	node := newTestNode(t, &NodeConfig[int, int]{MaxConcurrency: 2})
+
+	input, writer := schema.Pipe[int](0)
+	sent := make(chan int)
+	go func() {
+		defer close(sent)
+		defer writer.Close()
+		n := 0
+		for ; n < 1000; n++ {
+			if writer.Send(n*2, nil) {
+				break
+			}
+		}
+		sent <- n
+	}()
+
+	output := node.Transform(context.Background(), input)
+	if _, err := output.Recv(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output.Close()
+
+	select {
+	case n := <-sent:
+		if n == 1000 {
+			t.Fatalf("expected the input to stop being read")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("input still being read after the output was closed")
+	}
+}
@@ -21,7 +21,10 @@
 //   - Generic batch processing: Accept []I, return []O
 //   - Configurable concurrency: Sequential (0) or concurrent with limit (>0)
 //   - Interrupt handling: Collects interrupts from sub-tasks using CompositeInterrupt
-//   - Resume support: Restores state and only re-runs interrupted tasks
+//   - Resume support: Restores state and continues interrupted tasks from their checkpoints in CheckPointStore
+//   - Failure policy: Fail fast, collect all failures, or tolerate a ratio of failed items
+//   - Streaming: Transform emits results as they complete, with bounded in-flight items
+//   - Rate limiting: Shared request and token limits with adaptive concurrency
 //   - Callbacks: Implements Typer and Checker interfaces for callback support
 package batch
 
@@ -72,6 +75,21 @@ type NodeConfig[I, O any] struct {
 	// InnerCompileOptions are passed to InnerTask.Compile() for each invocation.
 	// Use this for compile-time options like WithGraphName.
 	InnerCompileOptions []compose.GraphCompileOption
+
+	// FailurePolicy decides when failed items fail the whole batch.
+	// Defaults to FailFast. Can be overridden per invocation with WithFailurePolicy.
+	FailurePolicy FailurePolicy
+
+	// Limiter paces the items and the ChatModel requests of their inner tasks, on top of
+	// MaxConcurrency, e.g. a RateLimiter shared with other nodes. Optional. It must not be
+	// shared with a batch node nested in InnerTask, see Limiter.
+	Limiter Limiter
+
+	// CheckPointStore keeps the checkpoints of the inner tasks that interrupted, so that they
+	// continue from their interrupt point on resume instead of running from scratch. Optional:
+	// without it, interrupted tasks are rerun from the start when the node resumes.
+	// It may be the checkpoint store of the parent graph: keys are prefixed per invocation.
+	CheckPointStore compose.CheckPointStore
 }
 
 // NodeInterruptState stores the batch node's state when an interrupt occurs.
@@ -92,6 +110,19 @@ type NodeInterruptState struct {
 	// TotalCount is the total number of input items.
 	// Used to allocate the correct output slice size on resume.
 	TotalCount int
+
+	// FailedErrors maps index -> error message for tasks that failed before interrupt,
+	// when the failure policy tolerated them. They are reported again, not re-run, on resume.
+	FailedErrors map[int]string
+
+	// CheckPointPrefix is the prefix of the checkpoints of the interrupted tasks in the
+	// CheckPointStore of the node, empty if it has none. On resume each task continues from
+	// its checkpoint instead of running its inner task from scratch.
+	CheckPointPrefix string
+
+	// NestedInterrupts is set when interrupts come from a batch node nested in the inner task.
+	// The interrupted tasks then resume one at a time, see Node.Invoke.
+	NestedInterrupts bool
 }
 
 // CallbackInput is passed to callbacks.OnStart when batch processing begins.
@@ -102,5 +133,6 @@ type CallbackInput[I any] struct {
 
 // CallbackOutput is passed to callbacks.OnEnd when batch processing completes.
 type CallbackOutput[O any] struct {
-	Outputs []O // All output results
+	Outputs []O             // All output results, zero values for items that did not succeed
+	Items   []ItemResult[O] // Outcome, attempts and duration of every item, including the errors of tolerated failures
 }
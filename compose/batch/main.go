@@ -26,20 +26,25 @@
 //  2. Concurrent Processing - Process multiple documents in parallel
 //  3. Compile Options - Configure inner workflow at compile time
 //  4. Invoke Options (Callbacks) - Add callbacks for monitoring
-//  5. Error Handling - Handle errors from individual tasks
+//  5. Error Handling - Handle errors from individual tasks, and retry transient ones
 //  6. Interrupt & Resume - Human-in-the-loop for high-priority documents
 //  7. Parent Graph with Reduce - Integrate BatchNode in a larger pipeline
+//  8. Streaming - Review documents as they arrive and get results as they complete
+//  9. Rate Limiting - Share provider request and token limits between batch nodes
 package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 
@@ -128,6 +133,14 @@ func main() {
 	runParentGraphWithReduce(ctx)
 	fmt.Println()
 
+	fmt.Println("--- Scenario 8: Streaming with Transform ---")
+	runStreaming(ctx)
+	fmt.Println()
+
+	fmt.Println("--- Scenario 9: Rate Limiting ---")
+	runWithRateLimiter(ctx)
+	fmt.Println()
+
 	fmt.Println("=== All Scenarios Completed ===")
 }
 
@@ -319,13 +332,76 @@ func runWithError(ctx context.Context) {
 		MaxConcurrency: 0,
 	})
 
-	results, err := batchNode.Invoke(ctx, docs)
+	// Default policy (FailFast): the first failure fails the batch
+	_, err := batchNode.Invoke(ctx, docs)
 	if err != nil {
 		fmt.Printf("Expected error occurred: %v\n", err)
+	}
+
+	// CollectAll: every document is processed, failures are reported per item
+	res, err := batchNode.InvokeWithResults(ctx, docs,
+		batch.WithFailurePolicy(batch.FailurePolicy{Mode: batch.CollectAll}))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	for _, item := range res.Items {
+		if item.Status != batch.ItemSucceeded {
+			fmt.Printf("  %s: %s - %v\n", docs[item.Index].DocumentID, item.Status, item.Err)
+			continue
+		}
+		fmt.Printf("  %s: %s - score %.2f\n", item.Output.DocumentID, item.Status, item.Output.Score)
+	}
+	fmt.Printf("Succeeded: %d, failed: %d\n", res.Count(batch.ItemSucceeded), res.Count(batch.ItemFailed))
 
-	fmt.Printf("Results: %v\n", results)
+	runWithRetry(ctx)
+}
+
+// runWithRetry shows transient failures, like rate-limited LLM calls, being retried by BatchNode
+func runWithRetry(ctx context.Context) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+
+	workflow := compose.NewWorkflow[ReviewRequest, ReviewResult]()
+	workflow.AddLambdaNode("analyze", compose.InvokableLambda(func(ctx context.Context, req ReviewRequest) (ReviewResult, error) {
+		mu.Lock()
+		calls[req.DocumentID]++
+		call := calls[req.DocumentID]
+		mu.Unlock()
+
+		// Simulate a flaky model: every document fails its first attempt
+		if call == 1 {
+			return ReviewResult{}, fmt.Errorf("review service unavailable for document %s", req.DocumentID)
+		}
+		return ReviewResult{
+			DocumentID: req.DocumentID,
+			Approved:   true,
+			Score:      0.9,
+			Comments:   "Passed validation",
+			ReviewedAt: time.Now(),
+		}, nil
+	})).AddInput(compose.START)
+	workflow.End().AddInput("analyze")
+
+	batchNode := batch.NewBatchNode(&batch.NodeConfig[ReviewRequest, ReviewResult]{
+		Name:           "RetryingReviewer",
+		InnerTask:      workflow,
+		MaxConcurrency: 2,
+	})
+
+	docs := createSampleDocuments(3)
+	res, err := batchNode.InvokeWithResults(ctx, docs,
+		batch.WithItemTimeout(5*time.Second),
+		batch.WithRetry(batch.RetryPolicy{MaxRetries: 2, InitialBackoff: 50 * time.Millisecond, Jitter: 0.2}),
+	)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, item := range res.Items {
+		fmt.Printf("  %s: %s after %d attempt(s) in %v\n", item.Output.DocumentID, item.Status, item.Attempts,
+			item.Duration.Round(time.Millisecond))
+	}
 }
 
 // Scenario 6: Interrupt & Resume
@@ -384,10 +460,15 @@ func runInterruptAndResume(ctx context.Context) {
 
 	innerWorkflow.End().AddInput("analyze")
 
+	// The checkpoint store persists the state of the parent graph, and the checkpoints of the
+	// interrupted review tasks so that they continue where they stopped
+	store := newMemoryCheckpointStore()
+
 	batchNode := batch.NewBatchNode(&batch.NodeConfig[ReviewRequest, ReviewResult]{
-		Name:           "InterruptReviewer",
-		InnerTask:      innerWorkflow,
-		MaxConcurrency: 0,
+		Name:            "InterruptReviewer",
+		InnerTask:       innerWorkflow,
+		MaxConcurrency:  0,
+		CheckPointStore: store,
 	})
 
 	// Wrap BatchNode in a parent graph for proper interrupt handling
@@ -399,7 +480,6 @@ func runInterruptAndResume(ctx context.Context) {
 	_ = parentGraph.AddEdge("batch_review", compose.END)
 
 	// Compile with checkpoint store for state persistence
-	store := newMemoryCheckpointStore()
 	runner, err := parentGraph.Compile(ctx,
 		compose.WithGraphName("InterruptResumeDemo"),
 		compose.WithCheckPointStore(store),
@@ -609,3 +689,114 @@ func runParentGraphWithReduce(ctx context.Context) {
 		fmt.Printf("    %s %s (score: %.2f)\n", status, r.DocumentID, r.Score)
 	}
 }
+
+// Scenario 8: Streaming with Transform
+// Demonstrates: Processing an input stream, emitting each result as soon as it completes
+func runStreaming(ctx context.Context) {
+	batchNode := batch.NewBatchNode(&batch.NodeConfig[ReviewRequest, ReviewResult]{
+		Name:           "StreamingReviewer",
+		InnerTask:      createSimpleReviewWorkflow(),
+		MaxConcurrency: 3, // At most 3 documents in flight
+	})
+
+	// Documents arrive one by one, e.g. from a queue, and are never collected into a slice
+	input, writer := schema.Pipe[ReviewRequest](0)
+	go func() {
+		defer writer.Close()
+		for _, doc := range createSampleDocuments(6) {
+			if closed := writer.Send(doc, nil); closed {
+				return
+			}
+		}
+	}()
+
+	start := time.Now()
+	results := batchNode.Transform(ctx, input)
+	defer results.Close()
+	for {
+		item, err := results.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if item.Err != nil {
+			fmt.Printf("  [%v] document #%d %s: %v\n", time.Since(start).Round(10*time.Millisecond), item.Index, item.Status, item.Err)
+			continue
+		}
+		fmt.Printf("  [%v] %s: approved=%v, score=%.2f\n", time.Since(start).Round(10*time.Millisecond),
+			item.Output.DocumentID, item.Output.Approved, item.Output.Score)
+	}
+}
+
+// simulatedReviewModel stands in for an LLM, reporting the token usage of every answer
+type simulatedReviewModel struct{}
+
+func (m *simulatedReviewModel) Generate(ctx context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	time.Sleep(20 * time.Millisecond)
+	msg := schema.AssistantMessage("Approved: the document meets the compliance requirements.", nil)
+	msg.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{PromptTokens: 400, CompletionTokens: 100, TotalTokens: 500}}
+	return msg, nil
+}
+
+func (m *simulatedReviewModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+// Scenario 9: Rate Limiting
+// Demonstrates: Two batch nodes sharing the request and token limits of one model provider
+func runWithRateLimiter(ctx context.Context) {
+	// Both nodes draw from the same budget of 600 requests and 100,000 tokens per minute,
+	// and never run more than 4 documents at once together
+	limiter, err := batch.NewRateLimiter(&batch.RateLimiterConfig{
+		RequestsPerMinute: 600,
+		TokensPerMinute:   100000,
+		MaxConcurrency:    4,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	newReviewer := func(name string) *batch.Node[ReviewRequest, string] {
+		workflow := compose.NewWorkflow[ReviewRequest, string]()
+		workflow.AddLambdaNode("prompt", compose.InvokableLambda(func(ctx context.Context, req ReviewRequest) ([]*schema.Message, error) {
+			return []*schema.Message{schema.UserMessage("Review this document: " + req.Content)}, nil
+		})).AddInput(compose.START)
+		workflow.AddChatModelNode("model", &simulatedReviewModel{}).AddInput("prompt")
+		workflow.AddLambdaNode("answer", compose.InvokableLambda(func(ctx context.Context, msg *schema.Message) (string, error) {
+			return msg.Content, nil
+		})).AddInput("model")
+		workflow.End().AddInput("answer")
+
+		return batch.NewBatchNode(&batch.NodeConfig[ReviewRequest, string]{
+			Name:           name,
+			InnerTask:      workflow,
+			MaxConcurrency: 3,
+			Limiter:        limiter, // Shared by both nodes
+		})
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, name := range []string{"LegalReviewer", "FinanceReviewer"} {
+		wg.Add(1)
+		go func(reviewer *batch.Node[ReviewRequest, string]) {
+			defer wg.Done()
+			res, err := reviewer.InvokeWithResults(ctx, createSampleDocuments(4))
+			if err != nil {
+				fmt.Printf("  %s error: %v\n", reviewer.GetType(), err)
+				return
+			}
+			fmt.Printf("  %s reviewed %d documents after %v\n", reviewer.GetType(), res.Count(batch.ItemSucceeded),
+				time.Since(start).Round(100*time.Millisecond))
+		}(newReviewer(name))
+	}
+	wg.Wait()
+}
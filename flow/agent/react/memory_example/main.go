@@ -70,14 +70,18 @@ func main() {
 	store := memory.NewInMemoryStore()
 	sessionID := "session:demo"
 
-	verifyGobRoundTrip()
+	verifyCodecRoundTrip()
 
 	run := func(turn string) {
 		fmt.Println("\n========== Turn Start ==========")
 		fmt.Printf("[User Input] %s\n", turn)
 
-		prev, _ := store.Read(ctx, sessionID)
-		fmt.Printf("[Restored %d messages]\n", len(prev))
+		page, err := store.ReadRange(ctx, sessionID, 0, 0)
+		if err != nil {
+			panic(err)
+		}
+		prev := page.Messages
+		fmt.Printf("[Restored %d messages, version %d]\n", len(prev), page.Version)
 		for i, m := range prev {
 			if len(m.ToolCalls) > 0 {
 				for _, tc := range m.ToolCalls {
@@ -90,7 +94,8 @@ func main() {
 			}
 		}
 
-		eff := append(prev, schema.UserMessage(turn))
+		userMsg := schema.UserMessage(turn)
+		eff := append(prev, userMsg)
 
 		msgFutureOpt, msgFuture := react.WithMessageFuture()
 
@@ -138,7 +143,11 @@ func main() {
 		wg.Wait()
 
 		fmt.Printf("[Produced %d messages this turn]\n", len(produced))
-		_ = store.Write(ctx, sessionID, append(eff, produced...))
+		// only the new messages are written; the version check rejects the turn
+		// if another writer changed the session while the agent was running
+		if _, err = store.AppendIfVersion(ctx, sessionID, page.Version, append([]*schema.Message{userMsg}, produced...)...); err != nil {
+			fmt.Printf("[Append failed: %v]\n", err)
+		}
 
 		hits, _ := store.Query(ctx, sessionID, "restaurant", 3)
 		fmt.Printf("[Query 'restaurant' hits=%d]\n", len(hits))
@@ -169,7 +178,7 @@ func printMessage(idx int, m *schema.Message) {
 	}
 }
 
-func verifyGobRoundTrip() {
+func verifyCodecRoundTrip() {
 	msgs := []*schema.Message{
 		schema.UserMessage("a"),
 		schema.AssistantMessage("b", nil),
@@ -182,7 +191,7 @@ func verifyGobRoundTrip() {
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("gob_round_trip=%d\n", len(out))
+	fmt.Printf("codec_round_trip=%d\n", len(out))
 }
 
 func truncate(s string, n int) string {
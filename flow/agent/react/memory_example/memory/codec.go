@@ -0,0 +1,184 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// Codec serializes messages for storage. Stores keep one encoded entry per message, so that
+// appending never rewrites the previous ones; the slice methods are for whole-session blobs.
+type Codec interface {
+	EncodeMessage(msg *schema.Message) ([]byte, error)
+	DecodeMessage(b []byte) (*schema.Message, error)
+	EncodeMessages(msgs []*schema.Message) ([]byte, error)
+	DecodeMessages(b []byte) ([]*schema.Message, error)
+}
+
+// DefaultCodec is the codec of the stores unless WithCodec is given.
+var DefaultCodec Codec = JSONCodec{}
+
+// JSONFormatVersion is the version written by JSONCodec in every payload.
+const JSONFormatVersion = 1
+
+// jsonEnvelope is the JSON payload: {"v":1,"message":{...}} or {"v":1,"messages":[...]}.
+// Messages use the JSON form of schema.Message, which carries tool calls and multimodal parts,
+// and can be read by any JSON tool. Unknown fields are ignored, so that adding fields to
+// schema.Message never breaks reading older payloads; a breaking change bumps the version.
+type jsonEnvelope struct {
+	Version  int               `json:"v"`
+	Message  *schema.Message   `json:"message,omitempty"`
+	Messages []*schema.Message `json:"messages,omitempty"`
+}
+
+// JSONCodec encodes messages as versioned JSON. It also decodes Gob payloads written by
+// GobCodec, so that data saved before the switch to JSON keeps loading.
+type JSONCodec struct{}
+
+func (JSONCodec) EncodeMessage(msg *schema.Message) ([]byte, error) {
+	return json.Marshal(&jsonEnvelope{Version: JSONFormatVersion, Message: msg})
+}
+
+func (JSONCodec) DecodeMessage(b []byte) (*schema.Message, error) {
+	if !isJSON(b) {
+		return GobCodec{}.DecodeMessage(b)
+	}
+	env, err := decodeEnvelope(b)
+	if err != nil {
+		return nil, err
+	}
+	if env.Message == nil {
+		return nil, fmt.Errorf("memory: JSON payload has no message")
+	}
+	return env.Message, nil
+}
+
+func (JSONCodec) EncodeMessages(msgs []*schema.Message) ([]byte, error) {
+	if msgs == nil {
+		msgs = []*schema.Message{}
+	}
+	return json.Marshal(&jsonEnvelope{Version: JSONFormatVersion, Messages: msgs})
+}
+
+func (JSONCodec) DecodeMessages(b []byte) ([]*schema.Message, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	if !isJSON(b) {
+		return GobCodec{}.DecodeMessages(b)
+	}
+	env, err := decodeEnvelope(b)
+	if err != nil {
+		return nil, err
+	}
+	return env.Messages, nil
+}
+
+// isJSON tells a JSON envelope from a Gob stream, which starts with a length byte rather than '{'.
+// A payload starting with '{' is always decoded as JSON, so that a corrupt one reports the JSON error.
+func isJSON(b []byte) bool {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	return len(b) > 0 && b[0] == '{'
+}
+
+func decodeEnvelope(b []byte) (*jsonEnvelope, error) {
+	env := &jsonEnvelope{}
+	if err := json.Unmarshal(b, env); err != nil {
+		return nil, fmt.Errorf("memory: decode JSON payload: %w", err)
+	}
+	if env.Version < 1 || env.Version > JSONFormatVersion {
+		return nil, fmt.Errorf("memory: unsupported JSON payload version %d", env.Version)
+	}
+	return env, nil
+}
+
+// GobCodec is the legacy encoding/gob codec. Gob payloads depend on the Go shape of
+// schema.Message and cannot be read outside Go; prefer JSONCodec, which also reads them.
+// Gob registrations for eino message types are provided by the framework; no manual registration needed here.
+type GobCodec struct{}
+
+func (GobCodec) EncodeMessage(msg *schema.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) DecodeMessage(b []byte) (*schema.Message, error) {
+	msg := &schema.Message{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (GobCodec) EncodeMessages(msgs []*schema.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(msgs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (GobCodec) DecodeMessages(b []byte) ([]*schema.Message, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	var msgs []*schema.Message
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// EncodeMessages serializes messages using DefaultCodec.
+func EncodeMessages(msgs []*schema.Message) ([]byte, error) {
+	return DefaultCodec.EncodeMessages(msgs)
+}
+
+// DecodeMessages deserializes messages previously encoded by EncodeMessages, or by its Gob version.
+func DecodeMessages(b []byte) ([]*schema.Message, error) {
+	return DefaultCodec.DecodeMessages(b)
+}
+
+// StoreOption configures InMemoryStore and RedisStore.
+type StoreOption func(*storeOptions)
+
+type storeOptions struct {
+	codec Codec
+}
+
+func newStoreOptions(opts []StoreOption) *storeOptions {
+	o := &storeOptions{codec: DefaultCodec}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithCodec sets the codec of the stored messages, DefaultCodec by default.
+func WithCodec(codec Codec) StoreOption {
+	return func(o *storeOptions) {
+		o.codec = codec
+	}
+}
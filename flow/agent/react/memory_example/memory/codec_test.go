@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestJSONCodec_RoundTrip(t *testing.T) {
+	url := "https://example.com/cat.png"
+	idx := 0
+	msgs := []*schema.Message{
+		{Role: schema.User, UserInputMultiContent: []schema.MessageInputPart{
+			{Type: schema.ChatMessagePartTypeText, Text: "what is this"},
+			{Type: schema.ChatMessagePartTypeImageURL, Image: &schema.MessageInputImage{
+				MessagePartCommon: schema.MessagePartCommon{URL: &url}, Detail: schema.ImageURLDetailLow}},
+		}},
+		schema.AssistantMessage("", []schema.ToolCall{{ID: "c1", Index: &idx, Type: "function",
+			Function: schema.FunctionCall{Name: "search", Arguments: `{"q":"cat"}`}}}),
+		schema.ToolMessage("a cat", "c1", schema.WithToolName("search")),
+	}
+
+	codec := JSONCodec{}
+	for _, m := range msgs {
+		b, err := codec.EncodeMessage(m)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		if !strings.HasPrefix(string(b), `{"v":1,`) {
+			t.Fatalf("payload should be versioned JSON, got %s", b)
+		}
+		got, err := codec.DecodeMessage(b)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if got.Role != m.Role || got.ToolCallID != m.ToolCallID || got.ToolName != m.ToolName ||
+			len(got.ToolCalls) != len(m.ToolCalls) || len(got.UserInputMultiContent) != len(m.UserInputMultiContent) {
+			t.Fatalf("round trip changed the message: %+v => %+v", m, got)
+		}
+	}
+
+	b, err := codec.EncodeMessages(msgs)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	got, err := codec.DecodeMessages(b)
+	if err != nil || len(got) != 3 {
+		t.Fatalf("decode: %d messages, err=%v", len(got), err)
+	}
+	if img := got[0].UserInputMultiContent[1].Image; img == nil || *img.URL != url || img.Detail != schema.ImageURLDetailLow {
+		t.Fatalf("image part lost: %+v", got[0].UserInputMultiContent[1])
+	}
+	if tc := got[1].ToolCalls[0]; tc.Function.Arguments != `{"q":"cat"}` || *tc.Index != 0 {
+		t.Fatalf("tool call lost: %+v", tc)
+	}
+}
+
+func TestJSONCodec_ReadsGob(t *testing.T) {
+	legacy := GobCodec{}
+	b, err := legacy.EncodeMessage(schema.UserMessage("from gob"))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	m, err := JSONCodec{}.DecodeMessage(b)
+	if err != nil || m.Content != "from gob" {
+		t.Fatalf("gob message not detected: %v %v", m, err)
+	}
+
+	b, err = legacy.EncodeMessages([]*schema.Message{schema.UserMessage("a"), schema.UserMessage("b")})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	msgs, err := JSONCodec{}.DecodeMessages(b)
+	if err != nil || len(msgs) != 2 {
+		t.Fatalf("gob messages not detected: %v %v", msgs, err)
+	}
+
+	if _, err = (JSONCodec{}).DecodeMessage([]byte(`{"v":2,"message":{}}`)); err == nil {
+		t.Fatalf("a payload from a newer version must be rejected")
+	}
+
+	// a corrupt JSON payload is reported as such, not as a Gob error
+	_, err = JSONCodec{}.DecodeMessage([]byte(`{"v":1,"message":{"role":`))
+	if err == nil || !strings.Contains(err.Error(), "decode JSON payload") {
+		t.Fatalf("expected a JSON error for a truncated payload, got %v", err)
+	}
+}
@@ -27,32 +27,122 @@ import (
 // InMemoryStore keeps serialized messages in a process-local map.
 // Suitable for demos/tests; not shared across processes.
 type InMemoryStore struct {
-	mu   sync.RWMutex
-	data map[string][]byte
+	mu    sync.RWMutex
+	data  map[string]*memSession
+	codec Codec
 }
 
-func NewInMemoryStore() *InMemoryStore {
-	return &InMemoryStore{data: make(map[string][]byte)}
+// memSession keeps each message encoded on its own, so that appending never re-encodes the session.
+type memSession struct {
+	msgs    [][]byte
+	version int64
+}
+
+func NewInMemoryStore(opts ...StoreOption) *InMemoryStore {
+	return &InMemoryStore{data: make(map[string]*memSession), codec: newStoreOptions(opts).codec}
+}
+
+func encodeEach(codec Codec, msgs []*schema.Message) ([][]byte, error) {
+	out := make([][]byte, 0, len(msgs))
+	for _, m := range msgs {
+		b, err := codec.EncodeMessage(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+func decodeEach(codec Codec, encoded [][]byte) ([]*schema.Message, error) {
+	if len(encoded) == 0 {
+		return nil, nil
+	}
+	out := make([]*schema.Message, 0, len(encoded))
+	for _, b := range encoded {
+		m, err := codec.DecodeMessage(b)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
 }
 
 // Write encodes and stores messages for the given key.
 func (s *InMemoryStore) Write(ctx context.Context, sessionID string, msgs []*schema.Message) error {
-	b, err := EncodeMessages(msgs)
+	encoded, err := encodeEach(s.codec, msgs)
 	if err != nil {
 		return err
 	}
 	s.mu.Lock()
-	s.data[sessionID] = b
-	s.mu.Unlock()
+	defer s.mu.Unlock()
+	sess := s.session(sessionID)
+	sess.msgs = encoded
+	sess.version++
 	return nil
 }
 
+// session returns the session, creating it if needed. The caller must hold the write lock.
+func (s *InMemoryStore) session(sessionID string) *memSession {
+	sess := s.data[sessionID]
+	if sess == nil {
+		sess = &memSession{}
+		s.data[sessionID] = sess
+	}
+	return sess
+}
+
+// Append encodes the new messages and adds them to the session.
+func (s *InMemoryStore) Append(ctx context.Context, sessionID string, msgs ...*schema.Message) (int64, error) {
+	return s.AppendIfVersion(ctx, sessionID, -1, msgs...)
+}
+
+// AppendIfVersion appends if the session is at expectedVersion; a negative expectedVersion always appends.
+func (s *InMemoryStore) AppendIfVersion(ctx context.Context, sessionID string, expectedVersion int64, msgs ...*schema.Message) (int64, error) {
+	encoded, err := encodeEach(s.codec, msgs)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess := s.session(sessionID)
+	if expectedVersion >= 0 && sess.version != expectedVersion {
+		return sess.version, ErrVersionConflict
+	}
+	sess.msgs = append(sess.msgs, encoded...)
+	sess.version++
+	return sess.version, nil
+}
+
 // Read returns decoded messages for the given session; returns nil if absent.
 func (s *InMemoryStore) Read(ctx context.Context, sessionID string) ([]*schema.Message, error) {
+	page, err := s.ReadRange(ctx, sessionID, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Messages, nil
+}
+
+// ReadRange decodes only the messages of the range.
+func (s *InMemoryStore) ReadRange(ctx context.Context, sessionID string, offset, limit int) (*Page, error) {
 	s.mu.RLock()
-	b := s.data[sessionID]
+	sess := s.data[sessionID]
+	page := &Page{}
+	var encoded [][]byte
+	if sess != nil {
+		start, end := pageBounds(offset, limit, len(sess.msgs))
+		page.Offset, page.Total, page.Version = start, len(sess.msgs), sess.version
+		encoded = sess.msgs[start:end]
+	}
 	s.mu.RUnlock()
-	return DecodeMessages(b)
+
+	msgs, err := decodeEach(s.codec, encoded)
+	if err != nil {
+		return nil, err
+	}
+	page.Messages = msgs
+	return page, nil
 }
 
 // Query performs a simple substring search on message contents for the session.
@@ -18,41 +18,268 @@ package memory
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 
 	miniredis "github.com/alicebob/miniredis/v2"
 	"github.com/cloudwego/eino/schema"
 	"github.com/redis/go-redis/v9"
 )
 
-// RedisStore persists serialized messages in Redis under the provided session key.
+// RedisStore persists each message as an entry of a Redis list, next to a version counter:
+//   - memory:<sessionID>:messages, the list of messages encoded by the Codec;
+//   - memory:<sessionID>:version, incremented by every change.
+//
+// Sessions saved by earlier versions of this store, as a single blob under the session ID,
+// are migrated to this layout on first access, or explicitly with Migrate.
 type RedisStore struct {
-	cli *redis.Client
+	cli   *redis.Client
+	codec Codec
+	// sessions known to be migrated, to check the legacy key once per process
+	migrated sync.Map
 }
 
-func NewRedisStore(cli *redis.Client) *RedisStore {
-	return &RedisStore{cli: cli}
+func NewRedisStore(cli *redis.Client, opts ...StoreOption) *RedisStore {
+	return &RedisStore{cli: cli, codec: newStoreOptions(opts).codec}
 }
 
-// Write encodes and stores messages using Redis SET.
+func messagesKey(sessionID string) string {
+	return "memory:" + sessionID + ":messages"
+}
+
+func versionKey(sessionID string) string {
+	return "memory:" + sessionID + ":version"
+}
+
+func encodeValues(codec Codec, msgs []*schema.Message) ([]any, error) {
+	encoded, err := encodeEach(codec, msgs)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(encoded))
+	for i, b := range encoded {
+		values[i] = b
+	}
+	return values, nil
+}
+
+// Write replaces the list of the session in a single transaction.
 func (s *RedisStore) Write(ctx context.Context, sessionID string, msgs []*schema.Message) error {
-	b, err := EncodeMessages(msgs)
+	if err := s.migrate(ctx, sessionID); err != nil {
+		return err
+	}
+	values, err := encodeValues(s.codec, msgs)
 	if err != nil {
 		return err
 	}
-	return s.cli.Set(ctx, sessionID, b, 0).Err()
+	_, err = s.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, messagesKey(sessionID))
+		if len(values) > 0 {
+			p.RPush(ctx, messagesKey(sessionID), values...)
+		}
+		p.Incr(ctx, versionKey(sessionID))
+		return nil
+	})
+	return err
 }
 
-// Read returns decoded messages from Redis GET; returns nil if not found.
-func (s *RedisStore) Read(ctx context.Context, sessionID string) ([]*schema.Message, error) {
-	res, err := s.cli.Get(ctx, sessionID).Bytes()
-	if err == redis.Nil {
-		return nil, nil
+// Append pushes the new messages with RPUSH, concurrent appends never overwrite each other.
+func (s *RedisStore) Append(ctx context.Context, sessionID string, msgs ...*schema.Message) (int64, error) {
+	if err := s.migrate(ctx, sessionID); err != nil {
+		return 0, err
+	}
+	values, err := encodeValues(s.codec, msgs)
+	if err != nil {
+		return 0, err
 	}
+	var version *redis.IntCmd
+	_, err = s.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		if len(values) > 0 {
+			p.RPush(ctx, messagesKey(sessionID), values...)
+		}
+		version = p.Incr(ctx, versionKey(sessionID))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version.Val(), nil
+}
+
+// AppendIfVersion watches the version key, so that the append fails if another writer changed the session.
+func (s *RedisStore) AppendIfVersion(ctx context.Context, sessionID string, expectedVersion int64, msgs ...*schema.Message) (int64, error) {
+	if expectedVersion < 0 {
+		return s.Append(ctx, sessionID, msgs...)
+	}
+	if err := s.migrate(ctx, sessionID); err != nil {
+		return 0, err
+	}
+	values, err := encodeValues(s.codec, msgs)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	err = s.cli.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, versionKey(sessionID)).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if current != expectedVersion {
+			version = current
+			return ErrVersionConflict
+		}
+		var incr *redis.IntCmd
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			if len(values) > 0 {
+				p.RPush(ctx, messagesKey(sessionID), values...)
+			}
+			incr = p.Incr(ctx, versionKey(sessionID))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		version = incr.Val()
+		return nil
+	}, versionKey(sessionID))
+	if errors.Is(err, redis.TxFailedErr) {
+		return version, ErrVersionConflict
+	}
+	return version, err
+}
+
+// Read returns all decoded messages of the session; returns nil if not found.
+func (s *RedisStore) Read(ctx context.Context, sessionID string) ([]*schema.Message, error) {
+	page, err := s.ReadRange(ctx, sessionID, 0, 0)
 	if err != nil {
 		return nil, err
 	}
-	return DecodeMessages(res)
+	return page.Messages, nil
+}
+
+// ReadRange reads the range with LRANGE, in the same transaction as the length and the version.
+func (s *RedisStore) ReadRange(ctx context.Context, sessionID string, offset, limit int) (*Page, error) {
+	if err := s.migrate(ctx, sessionID); err != nil {
+		return nil, err
+	}
+	if offset >= 0 {
+		page, _, err := s.readRange(ctx, sessionID, offset, limit, -1)
+		return page, err
+	}
+
+	// a negative offset depends on the length, which is checked again within the transaction
+	// in case a concurrent write changed it in between
+	for {
+		total, err := s.cli.LLen(ctx, messagesKey(sessionID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		start, _ := pageBounds(offset, limit, int(total))
+		page, ok, err := s.readRange(ctx, sessionID, start, limit, total)
+		if err != nil || ok {
+			return page, err
+		}
+	}
+}
+
+// readRange reads limit messages from start, and reports false if the list does not have
+// expectedTotal messages, unless expectedTotal is negative.
+func (s *RedisStore) readRange(ctx context.Context, sessionID string, start, limit int, expectedTotal int64) (*Page, bool, error) {
+	stop := int64(-1) // LRANGE has an inclusive stop, -1 is the last message
+	if limit > 0 {
+		stop = int64(start + limit - 1)
+	}
+
+	var (
+		length  *redis.IntCmd
+		version *redis.StringCmd
+		values  *redis.StringSliceCmd
+	)
+	_, err := s.cli.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		length = p.LLen(ctx, messagesKey(sessionID))
+		version = p.Get(ctx, versionKey(sessionID))
+		values = p.LRange(ctx, messagesKey(sessionID), int64(start), stop)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, false, err
+	}
+	if expectedTotal >= 0 && length.Val() != expectedTotal {
+		return nil, false, nil
+	}
+
+	page := &Page{Total: int(length.Val())}
+	page.Offset, _ = pageBounds(start, limit, page.Total)
+	if v, err := version.Int64(); err == nil {
+		page.Version = v
+	}
+	encoded := make([][]byte, 0, len(values.Val()))
+	for _, v := range values.Val() {
+		encoded = append(encoded, []byte(v))
+	}
+	if page.Messages, err = decodeEach(s.codec, encoded); err != nil {
+		return nil, false, err
+	}
+	return page, true, nil
+}
+
+func (s *RedisStore) migrate(ctx context.Context, sessionID string) error {
+	if _, ok := s.migrated.Load(sessionID); ok {
+		return nil
+	}
+	if _, err := s.Migrate(ctx, sessionID); err != nil {
+		return err
+	}
+	s.migrated.Store(sessionID, true)
+	return nil
+}
+
+// Migrate moves a session saved as a single blob under its ID, by earlier versions of
+// RedisStore, to the list layout, and deletes the blob. Messages already in the list are kept
+// after the migrated ones. It reports whether there was a blob to migrate.
+func (s *RedisStore) Migrate(ctx context.Context, sessionID string) (bool, error) {
+	migrated := false
+	err := s.cli.Watch(ctx, func(tx *redis.Tx) error {
+		typ, err := tx.Type(ctx, sessionID).Result()
+		if err != nil {
+			return err
+		}
+		if typ != "string" {
+			return nil
+		}
+		blob, err := tx.Get(ctx, sessionID).Bytes()
+		if err != nil {
+			return err
+		}
+		// the blob may be Gob or JSON, JSONCodec reads both
+		msgs, err := JSONCodec{}.DecodeMessages(blob)
+		if err != nil {
+			return fmt.Errorf("decode legacy session '%s': %w", sessionID, err)
+		}
+		values, err := encodeValues(s.codec, msgs)
+		if err != nil {
+			return err
+		}
+		// LPUSH inserts one by one at the head, so push the messages in reverse order
+		for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+			values[i], values[j] = values[j], values[i]
+		}
+
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			if len(values) > 0 {
+				p.LPush(ctx, messagesKey(sessionID), values...)
+			}
+			p.Incr(ctx, versionKey(sessionID))
+			p.Del(ctx, sessionID)
+			return nil
+		})
+		migrated = err == nil
+		return err
+	}, sessionID)
+	return migrated, err
 }
 
 func (s *RedisStore) Query(ctx context.Context, sessionID string, text string, limit int) ([]*schema.Message, error) {
@@ -0,0 +1,316 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ImportanceExtraKey is the Extra key of a message holding its importance, a float64 in [0, 1].
+const ImportanceExtraKey = "memory_importance"
+
+// ScoreWeights weights the terms of the recall score, each in [0, 1].
+type ScoreWeights struct {
+	Similarity float64
+	Recency    float64
+	Importance float64
+}
+
+// SemanticStoreConfig configures a SemanticStore.
+type SemanticStoreConfig struct {
+	// History keeps the conversation history served by Write and Read.
+	// Required.
+	History MemoryStore
+
+	// Embedder embeds the indexed chunks and the queries.
+	// Required.
+	Embedder embedding.Embedder
+
+	// Index keeps the embedded chunks.
+	// Optional. Defaults to NewInMemoryIndex().
+	Index VectorIndex
+
+	// SharedNamespaces are searched by Query besides the namespace of the session,
+	// e.g. "user:alice" for facts written with Remember that outlive a session.
+	// Optional.
+	SharedNamespaces []string
+
+	// ChunkChars is the max number of characters of an indexed chunk, longer messages are split.
+	// Uses 800 when <= 0.
+	ChunkChars int
+
+	// Weights of the recall score.
+	// Optional. Defaults to {Similarity: 1, Recency: 0.2, Importance: 0.1} when all zero.
+	Weights ScoreWeights
+
+	// RecencyHalfLife is the age at which the recency term of a memory drops to a half.
+	// Uses 24 hours when <= 0.
+	RecencyHalfLife time.Duration
+
+	// Importance rates a message when it is indexed.
+	// Optional. Defaults to the ImportanceExtraKey of the message, or 0.5.
+	Importance func(msg *schema.Message) float64
+
+	// Now returns the current time, used to date memories and score their recency.
+	// Optional. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// SemanticStore is a MemoryStore whose Query recalls messages by meaning rather than by substring.
+// Writes and reads go to the History store, and writes also index the user and assistant messages
+// of the session into its own namespace. Each message is embedded once, however often it is written.
+type SemanticStore struct {
+	history    MemoryStore
+	embedder   embedding.Embedder
+	index      VectorIndex
+	shared     []string
+	chunkChars int
+	weights    ScoreWeights
+	halfLife   time.Duration
+	importance func(*schema.Message) float64
+	now        func() time.Time
+}
+
+func NewSemanticStore(cfg *SemanticStoreConfig) (*SemanticStore, error) {
+	if cfg == nil || cfg.History == nil || cfg.Embedder == nil {
+		return nil, fmt.Errorf("history and embedder are required")
+	}
+	s := &SemanticStore{
+		history:    cfg.History,
+		embedder:   cfg.Embedder,
+		index:      cfg.Index,
+		shared:     cfg.SharedNamespaces,
+		chunkChars: 800,
+		weights:    cfg.Weights,
+		halfLife:   24 * time.Hour,
+		importance: cfg.Importance,
+		now:        cfg.Now,
+	}
+	if s.index == nil {
+		s.index = NewInMemoryIndex()
+	}
+	if cfg.ChunkChars > 0 {
+		s.chunkChars = cfg.ChunkChars
+	}
+	if s.weights == (ScoreWeights{}) {
+		s.weights = ScoreWeights{Similarity: 1, Recency: 0.2, Importance: 0.1}
+	}
+	if cfg.RecencyHalfLife > 0 {
+		s.halfLife = cfg.RecencyHalfLife
+	}
+	if s.importance == nil {
+		s.importance = defaultImportance
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+	return s, nil
+}
+
+func defaultImportance(msg *schema.Message) float64 {
+	if v, ok := msg.Extra[ImportanceExtraKey].(float64); ok {
+		return v
+	}
+	return 0.5
+}
+
+// SessionNamespace is the namespace of the messages written for a session.
+func SessionNamespace(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// Write stores the history, then indexes the messages not indexed yet.
+func (s *SemanticStore) Write(ctx context.Context, sessionID string, msgs []*schema.Message) error {
+	if err := s.history.Write(ctx, sessionID, msgs); err != nil {
+		return err
+	}
+	return s.Remember(ctx, SessionNamespace(sessionID), msgs...)
+}
+
+func (s *SemanticStore) Read(ctx context.Context, sessionID string) ([]*schema.Message, error) {
+	return s.history.Read(ctx, sessionID)
+}
+
+// Append appends to the history, then indexes the new messages.
+func (s *SemanticStore) Append(ctx context.Context, sessionID string, msgs ...*schema.Message) (int64, error) {
+	version, err := s.history.Append(ctx, sessionID, msgs...)
+	if err != nil {
+		return version, err
+	}
+	return version, s.Remember(ctx, SessionNamespace(sessionID), msgs...)
+}
+
+func (s *SemanticStore) AppendIfVersion(ctx context.Context, sessionID string, expectedVersion int64, msgs ...*schema.Message) (int64, error) {
+	version, err := s.history.AppendIfVersion(ctx, sessionID, expectedVersion, msgs...)
+	if err != nil {
+		return version, err
+	}
+	return version, s.Remember(ctx, SessionNamespace(sessionID), msgs...)
+}
+
+func (s *SemanticStore) ReadRange(ctx context.Context, sessionID string, offset, limit int) (*Page, error) {
+	return s.history.ReadRange(ctx, sessionID, offset, limit)
+}
+
+// Remember indexes messages into a namespace. System messages, tool messages and assistant
+// messages without content are skipped: they hold instructions and raw data rather than memories.
+func (s *SemanticStore) Remember(ctx context.Context, namespace string, msgs ...*schema.Message) error {
+	var entries []*VectorEntry
+	var texts []string
+	now := s.now()
+	for _, m := range msgs {
+		if m == nil || m.Content == "" || (m.Role != schema.User && m.Role != schema.Assistant) {
+			continue
+		}
+		msgID := messageID(m)
+		for i, chunk := range splitRunes(m.Content, s.chunkChars) {
+			id := msgID + "#" + strconv.Itoa(i)
+			ok, err := s.index.Has(ctx, namespace, id)
+			if err != nil {
+				return err
+			}
+			if ok {
+				continue
+			}
+			entries = append(entries, &VectorEntry{
+				ID:         id,
+				Chunk:      chunk,
+				Message:    m,
+				CreatedAt:  now,
+				Importance: s.importance(m),
+			})
+			texts = append(texts, chunk)
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	vectors, err := s.embedder.EmbedStrings(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embed messages failed: %w", err)
+	}
+	if len(vectors) != len(entries) {
+		return fmt.Errorf("embedder returned %d vectors for %d chunks", len(vectors), len(entries))
+	}
+	for i, e := range entries {
+		e.Vector = vectors[i]
+	}
+	return s.index.Upsert(ctx, namespace, entries)
+}
+
+// Recalled is a message found by Recall.
+type Recalled struct {
+	Message    *schema.Message
+	Namespace  string
+	Similarity float64
+	Score      float64
+}
+
+// Recall returns up to limit messages of the session and shared namespaces related to text,
+// by decreasing score: the weighted sum of cosine similarity, recency and importance.
+func (s *SemanticStore) Recall(ctx context.Context, sessionID string, text string, limit int) ([]*Recalled, error) {
+	if text == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+	vectors, err := s.embedder.EmbedStrings(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("embed query failed: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embedder returned %d vectors for 1 query", len(vectors))
+	}
+
+	namespaces := append([]string{SessionNamespace(sessionID)}, s.shared...)
+	// a message may have several chunks and recency can reorder hits, so look further than limit
+	hits, err := s.index.Search(ctx, namespaces, vectors[0], limit*4)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	best := make(map[string]*Recalled)
+	for _, h := range hits {
+		age := now.Sub(h.Entry.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		recency := math.Pow(0.5, float64(age)/float64(s.halfLife))
+		score := s.weights.Similarity*h.Similarity + s.weights.Recency*recency + s.weights.Importance*h.Entry.Importance
+
+		key := h.Namespace + "/" + messageID(h.Entry.Message)
+		if r, ok := best[key]; ok && r.Score >= score {
+			continue
+		}
+		best[key] = &Recalled{Message: h.Entry.Message, Namespace: h.Namespace, Similarity: h.Similarity, Score: score}
+	}
+
+	out := make([]*Recalled, 0, len(best))
+	for _, r := range best {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// Query returns the messages of Recall.
+func (s *SemanticStore) Query(ctx context.Context, sessionID string, text string, limit int) ([]*schema.Message, error) {
+	recalled, err := s.Recall(ctx, sessionID, text, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*schema.Message, 0, len(recalled))
+	for _, r := range recalled {
+		out = append(out, r.Message)
+	}
+	return out, nil
+}
+
+// messageID identifies a message by its role and content, the same message written again keeps its ID.
+func messageID(m *schema.Message) string {
+	sum := sha1.Sum([]byte(string(m.Role) + "\x00" + m.Content))
+	return hex.EncodeToString(sum[:])
+}
+
+func splitRunes(s string, n int) []string {
+	runes := []rune(s)
+	chunks := make([]string, 0, len(runes)/n+1)
+	for start := 0; start < len(runes); start += n {
+		end := start + n
+		if end > len(runes) {
+			end = len(runes)
+		}
+		chunks = append(chunks, string(runes[start:end]))
+	}
+	return chunks
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/schema"
+)
+
+// bagOfWords embeds a text as hashed word counts, so that texts sharing words are similar.
+type bagOfWords struct {
+	calls int
+}
+
+func (b *bagOfWords) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	out := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		b.calls++
+		v := make([]float64, 1024)
+		for _, w := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(strings.Trim(w, ".,?!")))
+			v[h.Sum32()%1024]++
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func TestSemanticStore_Recall(t *testing.T) {
+	ctx := context.Background()
+	emb := &bagOfWords{}
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store, err := NewSemanticStore(&SemanticStoreConfig{
+		History:          NewInMemoryStore(),
+		Embedder:         emb,
+		SharedNamespaces: []string{"user:alice"},
+		Now:              func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	history := []*schema.Message{
+		schema.SystemMessage("you are a helpful assistant"),
+		schema.UserMessage("find me a spicy noodle restaurant in Beijing"),
+		schema.AssistantMessage("", []schema.ToolCall{{ID: "1"}}),
+		schema.ToolMessage("raw tool output about noodle restaurant", "1"),
+		schema.AssistantMessage("Try the Sichuan noodle restaurant near Sanlitun", nil),
+		schema.UserMessage("what is the weather tomorrow"),
+	}
+	if err = store.Write(ctx, "s1", history); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if emb.calls != 3 {
+		t.Fatalf("expected 3 indexed messages, embedded %d", emb.calls)
+	}
+	// writing the same history again embeds nothing new
+	if err = store.Write(ctx, "s1", append(history, schema.AssistantMessage("sunny", nil))); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if emb.calls != 4 {
+		t.Fatalf("expected only the new message to be embedded, embedded %d", emb.calls)
+	}
+
+	if err = store.Remember(ctx, "user:alice", schema.UserMessage("I am allergic to peanuts in noodle dishes")); err != nil {
+		t.Fatalf("remember: %v", err)
+	}
+	if err = store.Write(ctx, "s2", []*schema.Message{schema.UserMessage("noodle restaurant in Shanghai")}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	got, err := store.Recall(ctx, "s1", "noodle restaurant", 3)
+	if err != nil {
+		t.Fatalf("recall: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 hits, got %d", len(got))
+	}
+	for _, r := range got {
+		if strings.Contains(r.Message.Content, "Shanghai") {
+			t.Fatalf("another session leaked into the recall: %q", r.Message.Content)
+		}
+		if r.Message.Role == schema.Tool || r.Message.Role == schema.System {
+			t.Fatalf("unexpected %s message recalled", r.Message.Role)
+		}
+	}
+	// both noodle messages of the session share two words with the query, the shared memory one
+	if got[0].Namespace != "session:s1" || got[1].Namespace != "session:s1" || got[2].Namespace != "user:alice" {
+		t.Fatalf("unexpected recall order: %q, %q, %q", got[0].Message.Content, got[1].Message.Content, got[2].Message.Content)
+	}
+
+	msgs, err := store.Read(ctx, "s1")
+	if err != nil || len(msgs) != len(history)+1 {
+		t.Fatalf("read should return the history, got %d messages, err=%v", len(msgs), err)
+	}
+}
+
+func TestSemanticStore_RecencyAndImportance(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store, err := NewSemanticStore(&SemanticStoreConfig{
+		History:         NewInMemoryStore(),
+		Embedder:        &bagOfWords{},
+		Weights:         ScoreWeights{Similarity: 1, Recency: 1},
+		RecencyHalfLife: time.Hour,
+		Now:             func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	if err = store.Remember(ctx, SessionNamespace("s"), schema.UserMessage("the meeting is on monday")); err != nil {
+		t.Fatalf("remember: %v", err)
+	}
+	now = now.Add(10 * time.Hour)
+	if err = store.Remember(ctx, SessionNamespace("s"), schema.UserMessage("the meeting moved to friday")); err != nil {
+		t.Fatalf("remember: %v", err)
+	}
+
+	got, err := store.Query(ctx, "s", "when is the meeting", 2)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("query: %v %v", got, err)
+	}
+	if !strings.Contains(got[0].Content, "friday") {
+		t.Fatalf("expected the recent memory first, got %q", got[0].Content)
+	}
+}
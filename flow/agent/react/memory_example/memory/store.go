@@ -17,42 +17,63 @@
 package memory
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
+	"errors"
 
 	"github.com/cloudwego/eino/schema"
 )
 
 // MemoryStore persists and restores short-term conversation history.
 // Implementations are responsible for storing a slice of messages under a session key.
+//
+// Every change of a session increments its version, starting from 0 for a missing session,
+// so that writers can detect concurrent changes with AppendIfVersion.
 type MemoryStore interface {
+	// Write replaces the messages of the session.
 	Write(ctx context.Context, sessionID string, msgs []*schema.Message) error
 	Read(ctx context.Context, sessionID string) ([]*schema.Message, error)
 	Query(ctx context.Context, sessionID string, text string, limit int) ([]*schema.Message, error)
-}
 
-// Gob registrations for eino message types are provided by the framework; no manual registration needed here.
+	// Append adds messages at the end of the session, without rewriting the previous ones,
+	// and returns the new version.
+	Append(ctx context.Context, sessionID string, msgs ...*schema.Message) (int64, error)
+	// AppendIfVersion appends like Append if the session is still at expectedVersion,
+	// and fails with ErrVersionConflict otherwise. A negative expectedVersion always appends.
+	AppendIfVersion(ctx context.Context, sessionID string, expectedVersion int64, msgs ...*schema.Message) (int64, error)
+	// ReadRange returns up to limit messages starting at offset, all of them if limit <= 0.
+	// A negative offset counts from the end, e.g. -10 reads the last 10 messages.
+	ReadRange(ctx context.Context, sessionID string, offset, limit int) (*Page, error)
+}
 
-// EncodeMessages serializes messages using Gob.
-func EncodeMessages(msgs []*schema.Message) ([]byte, error) {
-	var buf bytes.Buffer
-	enc := gob.NewEncoder(&buf)
-	if err := enc.Encode(msgs); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+// Page is a range of the messages of a session.
+type Page struct {
+	Messages []*schema.Message
+	// Offset is the index of the first message of the page.
+	Offset int
+	// Total is the number of messages of the session.
+	Total int
+	// Version is the version of the session the page was read at.
+	Version int64
 }
 
-// DecodeMessages deserializes messages previously encoded by EncodeMessages.
-func DecodeMessages(b []byte) ([]*schema.Message, error) {
-	if len(b) == 0 {
-		return nil, nil
+// ErrVersionConflict is returned by AppendIfVersion when the session changed since it was read.
+var ErrVersionConflict = errors.New("memory: session version conflict")
+
+// pageBounds clamps a range to a session of total messages, see MemoryStore.ReadRange.
+func pageBounds(offset, limit, total int) (start, end int) {
+	start = offset
+	if start < 0 {
+		start += total
+		if start < 0 {
+			start = 0
+		}
+	}
+	if start > total {
+		start = total
 	}
-	dec := gob.NewDecoder(bytes.NewReader(b))
-	var msgs []*schema.Message
-	if err := dec.Decode(&msgs); err != nil {
-		return nil, err
+	end = total
+	if limit > 0 && start+limit < total {
+		end = start + limit
 	}
-	return msgs, nil
+	return start, end
 }
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func contents(msgs []*schema.Message) []string {
+	out := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, m.Content)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testStore(t *testing.T, store MemoryStore) {
+	ctx := context.Background()
+
+	page, err := store.ReadRange(ctx, "s", 0, 10)
+	if err != nil || page.Version != 0 || page.Total != 0 || len(page.Messages) != 0 {
+		t.Fatalf("missing session should be empty at version 0, got %+v, err=%v", page, err)
+	}
+
+	v, err := store.Append(ctx, "s", schema.UserMessage("a"), schema.AssistantMessage("b", nil))
+	if err != nil || v != 1 {
+		t.Fatalf("append: version=%d err=%v", v, err)
+	}
+	if v, err = store.AppendIfVersion(ctx, "s", 1, schema.UserMessage("c")); err != nil || v != 2 {
+		t.Fatalf("append at version 1: version=%d err=%v", v, err)
+	}
+	if _, err = store.AppendIfVersion(ctx, "s", 1, schema.UserMessage("lost")); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale append should conflict, got %v", err)
+	}
+	if _, err = store.Append(ctx, "s", schema.AssistantMessage("d", nil), schema.UserMessage("e")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	cases := []struct {
+		offset, limit int
+		want          []string
+		wantOffset    int
+	}{
+		{0, 0, []string{"a", "b", "c", "d", "e"}, 0},
+		{1, 2, []string{"b", "c"}, 1},
+		{3, 10, []string{"d", "e"}, 3},
+		{-2, 0, []string{"d", "e"}, 3},
+		{-3, 2, []string{"c", "d"}, 2},
+		{-10, 1, []string{"a"}, 0},
+		{7, 1, nil, 5},
+	}
+	for _, c := range cases {
+		page, err = store.ReadRange(ctx, "s", c.offset, c.limit)
+		if err != nil {
+			t.Fatalf("read range: %v", err)
+		}
+		if got := contents(page.Messages); !equal(got, c.want) || page.Offset != c.wantOffset || page.Total != 5 || page.Version != 3 {
+			t.Fatalf("ReadRange(%d, %d) = %q offset=%d total=%d version=%d, want %q offset=%d",
+				c.offset, c.limit, got, page.Offset, page.Total, page.Version, c.want, c.wantOffset)
+		}
+	}
+
+	if err = store.Write(ctx, "s", []*schema.Message{schema.UserMessage("x")}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	msgs, err := store.Read(ctx, "s")
+	if err != nil || !equal(contents(msgs), []string{"x"}) {
+		t.Fatalf("write should replace the session, got %q, err=%v", contents(msgs), err)
+	}
+}
+
+func TestInMemoryStore(t *testing.T) {
+	testStore(t, NewInMemoryStore())
+}
+
+func TestRedisStore(t *testing.T) {
+	cli, closer, err := NewMiniRedisClient()
+	if err != nil {
+		t.Fatalf("miniredis: %v", err)
+	}
+	defer closer()
+	testStore(t, NewRedisStore(cli))
+}
+
+func TestRedisStore_MigratesGobBlobs(t *testing.T) {
+	ctx := context.Background()
+	cli, closer, err := NewMiniRedisClient()
+	if err != nil {
+		t.Fatalf("miniredis: %v", err)
+	}
+	defer closer()
+
+	// a session saved by the previous RedisStore.Write
+	blob, err := GobCodec{}.EncodeMessages([]*schema.Message{schema.UserMessage("old-1"), schema.AssistantMessage("old-2", nil)})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err = cli.Set(ctx, "legacy", blob, 0).Err(); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+
+	store := NewRedisStore(cli)
+	if _, err = store.Append(ctx, "legacy", schema.UserMessage("new")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	msgs, err := store.Read(ctx, "legacy")
+	if err != nil || !equal(contents(msgs), []string{"old-1", "old-2", "new"}) {
+		t.Fatalf("unexpected migrated session %q, err=%v", contents(msgs), err)
+	}
+	if n, _ := cli.Exists(ctx, "legacy").Result(); n != 0 {
+		t.Fatalf("the legacy blob should be deleted")
+	}
+	if ok, err := store.Migrate(ctx, "legacy"); ok || err != nil {
+		t.Fatalf("nothing left to migrate, got %v %v", ok, err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// VectorEntry is one embedded chunk of a message.
+type VectorEntry struct {
+	// ID identifies the entry within its namespace, re-adding an ID replaces the entry.
+	ID         string
+	Vector     []float64
+	Chunk      string
+	Message    *schema.Message
+	CreatedAt  time.Time
+	Importance float64
+}
+
+// VectorHit is an entry found by VectorIndex.Search.
+type VectorHit struct {
+	Namespace  string
+	Entry      *VectorEntry
+	Similarity float64
+}
+
+// VectorIndex stores embedded chunks by namespace and finds the nearest ones.
+// Implementations must be safe for concurrent use.
+type VectorIndex interface {
+	Upsert(ctx context.Context, namespace string, entries []*VectorEntry) error
+	Has(ctx context.Context, namespace string, id string) (bool, error)
+	// Search returns up to topK entries of the namespaces, by decreasing cosine similarity to vector.
+	Search(ctx context.Context, namespaces []string, vector []float64, topK int) ([]*VectorHit, error)
+}
+
+// InMemoryIndex is a VectorIndex scanning every entry of the namespaces on Search.
+// It needs no external service, and stays fast up to tens of thousands of entries.
+type InMemoryIndex struct {
+	mu      sync.RWMutex
+	entries map[string]map[string]*VectorEntry
+}
+
+func NewInMemoryIndex() *InMemoryIndex {
+	return &InMemoryIndex{entries: make(map[string]map[string]*VectorEntry)}
+}
+
+// Upsert stores the entries with normalized vectors, so that Search only computes dot products.
+func (x *InMemoryIndex) Upsert(ctx context.Context, namespace string, entries []*VectorEntry) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	ns := x.entries[namespace]
+	if ns == nil {
+		ns = make(map[string]*VectorEntry)
+		x.entries[namespace] = ns
+	}
+	for _, e := range entries {
+		cp := *e
+		cp.Vector = normalize(e.Vector)
+		ns[e.ID] = &cp
+	}
+	return nil
+}
+
+func (x *InMemoryIndex) Has(ctx context.Context, namespace string, id string) (bool, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	_, ok := x.entries[namespace][id]
+	return ok, nil
+}
+
+func (x *InMemoryIndex) Search(ctx context.Context, namespaces []string, vector []float64, topK int) ([]*VectorHit, error) {
+	q := normalize(vector)
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	var hits []*VectorHit
+	for _, namespace := range namespaces {
+		for _, e := range x.entries[namespace] {
+			hits = append(hits, &VectorHit{Namespace: namespace, Entry: e, Similarity: dot(q, e.Vector)})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Similarity != hits[j].Similarity {
+			return hits[i].Similarity > hits[j].Similarity
+		}
+		return hits[i].Entry.ID < hits[j].Entry.ID
+	})
+	if topK > 0 && len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, nil
+}
+
+func normalize(v []float64) []float64 {
+	var norm float64
+	for _, f := range v {
+		norm += f * f
+	}
+	out := make([]float64, len(v))
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, f := range v {
+		out[i] = f / norm
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var s float64
+	for i := 0; i < n; i++ {
+		s += a[i] * b[i]
+	}
+	return s
+}
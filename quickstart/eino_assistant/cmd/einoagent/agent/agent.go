@@ -19,7 +19,6 @@ package agent
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -28,12 +27,14 @@ import (
 
 	"github.com/cloudwego/eino-ext/callbacks/apmplus"
 	"github.com/cloudwego/eino-ext/callbacks/langfuse"
+	"github.com/cloudwego/eino-ext/components/model/ark"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/task"
 )
 
 var memory = mem.GetDefaultMemory()
@@ -42,6 +43,42 @@ var cbHandler callbacks.Handler
 
 var once sync.Once
 
+// runs holds the cancel function of every running chat, keyed by conversation ID.
+var runs = &runRegistry{cancels: make(map[string]context.CancelFunc)}
+
+type runRegistry struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// start registers a chat, it reports false if a chat is already running for id.
+func (r *runRegistry) start(id string, cancel context.CancelFunc) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cancels[id]; ok {
+		return false
+	}
+	r.cancels[id] = cancel
+	return true
+}
+
+func (r *runRegistry) finish(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cancels, id)
+}
+
+// cancel stops the chat running for id, it reports false if there is none.
+func (r *runRegistry) cancel(id string) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[id]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
 func Init() error {
 	var err error
 	once.Do(func() {
@@ -100,6 +137,22 @@ func Init() error {
 		if len(callbackHandlers) > 0 {
 			callbacks.InitCallbackHandlers(callbackHandlers)
 		}
+
+		// notify the tasks falling due while the server runs
+		task.GetDefaultReminderScheduler().Start(context.Background())
+
+		// keep a running summary of the messages that left the history window
+		if os.Getenv("MEMORY_SUMMARY") == "true" {
+			var cm *ark.ChatModel
+			cm, err = ark.NewChatModel(context.Background(), &ark.ChatModelConfig{
+				Model:  os.Getenv("ARK_CHAT_MODEL"),
+				APIKey: os.Getenv("ARK_API_KEY"),
+			})
+			if err != nil {
+				return
+			}
+			memory.SetSummarizer(mem.NewModelSummarizer(cm))
+		}
 	})
 	return err
 }
@@ -111,12 +164,24 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 		return nil, fmt.Errorf("failed to build agent graph: %w", err)
 	}
 
-	conversation := memory.GetConversation(id, true)
+	conversation, err := memory.GetConversation(id, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	history, err := conversation.GetMessages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
 
 	userMessage := &einoagent.UserMessage{
 		ID:      id,
 		Query:   msg,
-		History: conversation.GetMessages(),
+		History: history,
+	}
+	if reminder, err := task.GetDefaultReminderScheduler().Current(); err != nil {
+		log.Printf("[Chat] Error listing task reminders: %v\n", err)
+	} else {
+		userMessage.Reminders = reminder.String()
 	}
 	if os.Getenv("APMPLUS_APP_KEY") != "" {
 		// set session info for apmplus callback
@@ -129,41 +194,10 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 
 	srs := sr.Copy(2)
 
+	// save the turn to memory from the second copy
 	go func() {
-		// for save to memory
-		fullMsgs := make([]*schema.Message, 0)
-
-		defer func() {
-			// close stream if you used it
-			srs[1].Close()
-
-			// add user input to history
-			conversation.Append(schema.UserMessage(msg))
-
-			fullMsg, err := schema.ConcatMessages(fullMsgs)
-			if err != nil {
-				fmt.Println("error concatenating messages: ", err.Error())
-			}
-			// add agent response to history
-			conversation.Append(fullMsg)
-		}()
-
-	outer:
-		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("context done", ctx.Err())
-				return
-			default:
-				chunk, err := srs[1].Recv()
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						break outer
-					}
-				}
-
-				fullMsgs = append(fullMsgs, chunk)
-			}
+		if err := conversation.RecordTurn(ctx, msg, srs[1]); err != nil {
+			log.Printf("[Chat] Error recording turn of chat ID %s: %v\n", id, err)
 		}
 	}()
 
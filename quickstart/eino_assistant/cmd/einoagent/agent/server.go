@@ -20,12 +20,14 @@ import (
 	"bufio"
 	"context"
 	"embed"
+	"encoding/json"
 	"errors"
 	"io"
 	"log"
 	"mime"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
@@ -54,6 +56,9 @@ func BindRoutes(r *route.RouterGroup) error {
 	r.GET("/api/log", HandleLog)
 	r.GET("/api/history", HandleHistory)
 	r.DELETE("/api/history", HandleDeleteHistory)
+	r.PUT("/api/history", HandleRenameHistory)
+	r.GET("/api/history/search", HandleSearchHistory)
+	r.DELETE("/api/runs/:id", HandleCancelRun)
 
 	// 静态文件服务
 	r.GET("/", func(ctx context.Context, c *app.RequestContext) {
@@ -96,6 +101,18 @@ func HandleChat(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
+	// the run is cancelled when the client disconnects or through DELETE /api/runs/:id
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if !runs.start(id, cancel) {
+		c.JSON(consts.StatusConflict, map[string]string{
+			"status": "error",
+			"error":  "a chat is already running for this id",
+		})
+		return
+	}
+	defer runs.finish(id)
+
 	log.Printf("[Chat] Starting chat with ID: %s, Message: %s\n", id, message)
 
 	sr, err := RunAgent(ctx, id, message)
@@ -137,33 +154,67 @@ outer:
 				Data: []byte(msg.Content),
 			})
 			if err != nil {
-				log.Printf("[Chat] Error publishing message: %v\n", err)
+				// the client is gone, stop the agent instead of generating for nobody
+				log.Printf("[Chat] Error publishing message, cancelling chat ID %s: %v\n", id, err)
+				cancel()
 				break outer
 			}
 		}
 	}
 }
 
+func HandleCancelRun(ctx context.Context, c *app.RequestContext) {
+	id := c.Param("id")
+	if !runs.cancel(id) {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"status": "error",
+			"error":  "no chat is running for this id",
+		})
+		return
+	}
+	log.Printf("[Chat] Cancelled chat with ID: %s\n", id)
+	c.JSON(consts.StatusOK, map[string]string{
+		"status": "success",
+	})
+}
+
 func HandleHistory(ctx context.Context, c *app.RequestContext) {
 	// query: id => get history, none => list all
 	id := c.Query("id")
 
 	if id == "" {
-		ids := mem.GetDefaultMemory().ListConversations()
+		infos, err := mem.GetDefaultMemory().ListConversationInfos()
+		if err != nil {
+			c.JSON(consts.StatusInternalServerError, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+		ids := make([]string, 0, len(infos))
+		for _, info := range infos {
+			ids = append(ids, info.ID)
+		}
 
 		c.JSON(consts.StatusOK, map[string]interface{}{
-			"ids": ids,
+			"ids":           ids,
+			"conversations": infos,
 		})
 		return
 	}
 
-	conversation := mem.GetDefaultMemory().GetConversation(id, false)
-	if conversation == nil {
+	conversation, err := mem.GetDefaultMemory().GetConversation(id, false)
+	if errors.Is(err, mem.ErrConversationNotFound) {
 		c.JSON(consts.StatusNotFound, map[string]string{
 			"error": "conversation not found",
 		})
 		return
 	}
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	c.JSON(consts.StatusOK, map[string]interface{}{
 		"conversation": conversation,
@@ -180,12 +231,89 @@ func HandleDeleteHistory(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	mem.GetDefaultMemory().DeleteConversation(id)
+	err := mem.GetDefaultMemory().DeleteConversation(id)
+	if errors.Is(err, mem.ErrConversationNotFound) {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]string{
+		"status": "success",
+	})
+}
+
+type RenameRequest struct {
+	Title string `json:"title"`
+}
+
+func HandleRenameHistory(ctx context.Context, c *app.RequestContext) {
+	id := c.Query("id")
+	var req RenameRequest
+	if err := json.Unmarshal(c.Request.Body(), &req); err != nil || id == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing id parameter or invalid body",
+		})
+		return
+	}
+
+	err := mem.GetDefaultMemory().RenameConversation(id, req.Title)
+	if errors.Is(err, mem.ErrConversationNotFound) {
+		c.JSON(consts.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	c.JSON(consts.StatusOK, map[string]string{
 		"status": "success",
 	})
 }
 
+func HandleSearchHistory(ctx context.Context, c *app.RequestContext) {
+	// query: q => text to look for in titles and messages, limit => max results, default 20
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(consts.StatusBadRequest, map[string]string{
+			"error": "missing q parameter",
+		})
+		return
+	}
+	limit := 20
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(consts.StatusBadRequest, map[string]string{
+				"error": "limit must be a positive integer",
+			})
+			return
+		}
+		limit = n
+	}
+
+	results, err := mem.GetDefaultMemory().SearchConversations(query, limit)
+	if err != nil {
+		c.JSON(consts.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(consts.StatusOK, map[string]interface{}{
+		"results": results,
+	})
+}
+
 func HandleLog(ctx context.Context, c *app.RequestContext) {
 	file, err := os.Open("log/eino.log")
 	if err != nil {
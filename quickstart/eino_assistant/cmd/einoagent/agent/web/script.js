@@ -7,6 +7,7 @@ document.addEventListener('DOMContentLoaded', () => {
     const rightPanel = document.getElementById('right-panel');
     const togglePanel = document.getElementById('toggle-panel');
     const newChatButton = document.getElementById('new-chat');
+    const historySearch = document.getElementById('history-search');
 
     let chatId = uuidv4();
     let currentConversation = null;
@@ -25,6 +26,8 @@ document.addEventListener('DOMContentLoaded', () => {
         if (abortController) {
             abortController.abort();
             abortController = null;
+            // 通知服务端停止 agent，即使断开连接没有传到服务端
+            fetch(`/agent/api/runs/${chatId}`, {method: 'DELETE'}).catch(() => {});
         }
 
         // 隐藏取消按钮，显示发送按钮
@@ -126,38 +129,75 @@ document.addEventListener('DOMContentLoaded', () => {
         rightPanel.classList.toggle('opacity-100');
     });
 
-    // 新建对话
-    newChatButton.addEventListener('click', () => {
-        chatId = uuidv4();
-        currentConversation = null;
-        chatMessages.innerHTML = '';
-        messageInput.value = '';
-
-        // 创建新的历史记录项
+    // 创建历史记录项，标题用 textContent 设置，避免消息内容被当作 HTML
+    function createHistoryItem(id, title, snippet) {
         const historyItem = document.createElement('div');
         historyItem.className = 'chat-item p-3 hover:bg-gray-100 cursor-pointer rounded-lg mb-2 transition-colors flex justify-between items-start';
-        historyItem.dataset.chatId = chatId;
+        historyItem.dataset.chatId = id;
         historyItem.innerHTML = `
-            <div class="flex-1 min-w-0 mr-2" onclick="event.stopPropagation()">
-                <div class="font-medium text-gray-900 truncate">Empty</div>
-                <div class="text-sm text-gray-500">ID: ${chatId.substring(0, 8)}...</div>
+            <div class="flex-1 min-w-0 mr-2">
+                <div class="font-medium text-gray-900 truncate"></div>
+                <div class="text-sm text-gray-500 truncate"></div>
             </div>
-            <button class="delete-chat p-1 hover:bg-red-100 rounded-lg transition-colors" onclick="event.stopPropagation()">
+            <button class="rename-chat p-1 hover:bg-gray-200 rounded-lg transition-colors" title="Rename">
+                <svg class="w-5 h-5 text-gray-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                    <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M15.232 5.232l3.536 3.536M9 13l6.232-6.232a2.5 2.5 0 013.536 3.536L12.536 16.536 9 17l.464-3.536z" />
+                </svg>
+            </button>
+            <button class="delete-chat p-1 hover:bg-red-100 rounded-lg transition-colors" title="Delete">
                 <svg class="w-5 h-5 text-red-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
                     <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M19 7l-.867 12.142A2 2 0 0116.138 21H7.862a2 2 0 01-1.995-1.858L5 7m5 4v6m4-6v6m1-10V4a1 1 0 00-1-1h-4a1 1 0 00-1 1v3M4 7h16" />
                 </svg>
             </button>
         `;
-        
-        // 添加删除按钮事件
-        const deleteButton = historyItem.querySelector('.delete-chat');
-        deleteButton.addEventListener('click', (e) => {
+        historyItem.querySelector('.font-medium').textContent = title || 'Empty';
+        historyItem.querySelector('.text-sm').textContent = snippet || `ID: ${id.substring(0, 8)}...`;
+
+        // 添加重命名和删除按钮事件
+        historyItem.querySelector('.rename-chat').addEventListener('click', (e) => {
             e.stopPropagation();
-            deleteConversation(chatId, historyItem);
+            renameConversation(id, historyItem);
         });
-        
+        historyItem.querySelector('.delete-chat').addEventListener('click', (e) => {
+            e.stopPropagation();
+            deleteConversation(id, historyItem);
+        });
+
         // 添加点击事件
-        historyItem.querySelector('.flex-1').addEventListener('click', () => loadConversation(chatId));
+        historyItem.querySelector('.flex-1').addEventListener('click', () => loadConversation(id));
+        return historyItem;
+    }
+
+    // 重命名对话
+    async function renameConversation(id, element) {
+        const titleDiv = element.querySelector('.font-medium');
+        const title = prompt('Rename conversation', titleDiv.textContent);
+        if (title === null || title.trim() === '') return;
+        try {
+            const response = await fetch(`/agent/api/history?id=${id}`, {
+                method: 'PUT',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({title: title.trim()})
+            });
+            if (response.ok) {
+                titleDiv.textContent = title.trim();
+            } else {
+                console.error('Failed to rename conversation');
+            }
+        } catch (error) {
+            console.error('Error renaming conversation:', error);
+        }
+    }
+
+    // 新建对话
+    newChatButton.addEventListener('click', () => {
+        chatId = uuidv4();
+        currentConversation = null;
+        chatMessages.innerHTML = '';
+        messageInput.value = '';
+
+        // 创建新的历史记录项
+        const historyItem = createHistoryItem(chatId, 'Empty');
 
         // 将新对话添加到列表顶部
         if (chatHistory.firstChild) {
@@ -463,62 +503,46 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     });
 
-    // 加载历史对话列表
-    fetch('/agent/api/history')
-        .then(response => response.json())
-        .then(data => {
-            if (data.ids && data.ids.length > 0) {
+    // 加载历史对话列表，最近更新的在前
+    function loadHistory(loadFirst) {
+        return fetch('/agent/api/history')
+            .then(response => response.json())
+            .then(data => {
                 chatHistory.innerHTML = ''; // 清空现有历史
-                let firstConversationId = null;
-
-                const loadPromises = data.ids.map(id => 
-                    fetch(`/agent/api/history?id=${id}`)
-                        .then(response => response.json())
-                        .then(convData => {
-                            if (convData.conversation) {
-                                const firstMessage = convData.conversation.messages.length > 0 
-                                    ? convData.conversation.messages[0].content 
-                                    : 'Empty';
-
-                                const historyItem = document.createElement('div');
-                                historyItem.className = 'chat-item p-3 hover:bg-gray-100 cursor-pointer rounded-lg mb-2 transition-colors flex justify-between items-start';
-                                historyItem.dataset.chatId = id;
-                                historyItem.innerHTML = `
-                                    <div class="flex-1 min-w-0 mr-2" onclick="event.stopPropagation()">
-                                        <div class="font-medium text-gray-900 truncate">${firstMessage}</div>
-                                        <div class="text-sm text-gray-500">ID: ${id.substring(0, 8)}...</div>
-                                    </div>
-                                    <button class="delete-chat p-1 hover:bg-red-100 rounded-lg transition-colors" onclick="event.stopPropagation()">
-                                        <svg class="w-5 h-5 text-red-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
-                                            <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M19 7l-.867 12.142A2 2 0 0116.138 21H7.862a2 2 0 01-1.995-1.858L5 7m5 4v6m4-6v6m1-10V4a1 1 0 00-1-1h-4a1 1 0 00-1 1v3M4 7h16" />
-                                        </svg>
-                                    </button>
-                                `;
-                                
-                                const deleteButton = historyItem.querySelector('.delete-chat');
-                                deleteButton.addEventListener('click', (e) => {
-                                    e.stopPropagation();
-                                    deleteConversation(id, historyItem);
-                                });
-                                
-                                historyItem.querySelector('.flex-1').addEventListener('click', () => loadConversation(id));
-                                chatHistory.appendChild(historyItem);
-
-                                // 记录第一个对话的ID
-                                if (firstConversationId === null) {
-                                    firstConversationId = id;
-                                }
-                            }
-                        })
-                );
-
-                // 等待所有历史记录加载完成后，加载第一个对话
-                Promise.all(loadPromises).then(() => {
-                    if (firstConversationId) {
-                        loadConversation(firstConversationId);
-                    }
+                (data.conversations || []).forEach(conv => {
+                    chatHistory.appendChild(createHistoryItem(conv.id, conv.title));
                 });
+                if (loadFirst && data.conversations && data.conversations.length > 0) {
+                    loadConversation(data.conversations[0].id);
+                } else {
+                    highlightCurrentChat();
+                }
+            })
+            .catch(error => console.error('Error loading history:', error));
+    }
+
+    // 搜索历史对话的标题和消息
+    let searchTimer = null;
+    historySearch.addEventListener('input', () => {
+        clearTimeout(searchTimer);
+        searchTimer = setTimeout(() => {
+            const query = historySearch.value.trim();
+            if (!query) {
+                loadHistory(false);
+                return;
             }
-        })
-        .catch(error => console.error('Error loading history:', error));
+            fetch(`/agent/api/history/search?q=${encodeURIComponent(query)}`)
+                .then(response => response.json())
+                .then(data => {
+                    chatHistory.innerHTML = '';
+                    (data.results || []).forEach(result => {
+                        chatHistory.appendChild(createHistoryItem(result.id, result.title, result.snippet));
+                    });
+                    highlightCurrentChat();
+                })
+                .catch(error => console.error('Error searching history:', error));
+        }, 300);
+    });
+
+    loadHistory(true);
 }); 
\ No newline at end of file
@@ -55,7 +55,7 @@ func main() {
 	}
 
 	// 创建 Hertz 服务器
-	h := server.Default(server.WithHostPorts(":" + port))
+	h := server.Default(server.WithHostPorts(":"+port), server.WithSenseClientDisconnection(true))
 
 	h.Use(LogMiddleware())
 
@@ -72,7 +72,7 @@ func main() {
 			provider.WithResourceAttribute(attribute.String("apmplus.business_type", "llm")),
 		)
 		tracer, cfg := hertztracing.NewServerTracer()
-		h = server.Default(server.WithHostPorts(":"+port), tracer)
+		h = server.Default(server.WithHostPorts(":"+port), server.WithSenseClientDisconnection(true), tracer)
 		h.Use(LogMiddleware(), hertztracing.ServerMiddleware(cfg))
 	}
 
@@ -48,6 +48,17 @@ function formatDate(dateStr) {
     });
 }
 
+const priorityLabels = {
+    low: { text: '低', class: 'bg-gray-100 text-gray-600' },
+    medium: { text: '中', class: 'bg-blue-100 text-blue-700' },
+    high: { text: '高', class: 'bg-orange-100 text-orange-700' },
+    urgent: { text: '紧急', class: 'bg-red-100 text-red-700' }
+};
+
+function parseTags(value) {
+    return value.split(/[,，]/).map(tag => tag.trim()).filter(tag => tag);
+}
+
 function calculateUrgency(task) {
     if (!task.deadline || task.completed) return Infinity;
     const now = new Date();
@@ -188,6 +199,21 @@ function renderTasks(tasks) {
             }
         }
 
+        const priority = priorityLabels[task.priority];
+        if (priority && task.priority !== 'medium') {
+            const priorityEl = item.querySelector('.task-priority');
+            priorityEl.textContent = `优先级: ${priority.text}`;
+            priorityEl.classList.remove('hidden');
+            priorityEl.classList.add(...priority.class.split(' '));
+        }
+        const tagsEl = item.querySelector('.task-tags');
+        (task.tags || []).forEach(tag => {
+            const tagEl = document.createElement('span');
+            tagEl.className = 'px-2 rounded bg-green-100 text-green-700';
+            tagEl.textContent = `#${tag}`;
+            tagsEl.appendChild(tagEl);
+        });
+
         if (task.completed) {
             item.querySelector('.task-title').classList.add('line-through', 'text-gray-500');
             item.querySelector('.task-content').classList.add('line-through', 'text-gray-500');
@@ -215,6 +241,8 @@ function openEditDialog(task) {
     form.title.value = task.title;
     form.content.value = task.content;
     form.deadline.value = task.deadline ? task.deadline.slice(0, 16) : '';
+    form.priority.value = task.priority || 'medium';
+    form.tags.value = (task.tags || []).join(', ');
     
     dialog.classList.remove('hidden');
 }
@@ -256,7 +284,9 @@ document.addEventListener('DOMContentLoaded', () => {
         const task = {
             title: form.title.value,
             content: form.content.value,
-            deadline: form.deadline.value
+            deadline: form.deadline.value,
+            priority: form.priority.value,
+            tags: parseTags(form.tags.value)
         };
 
         try {
@@ -348,7 +378,9 @@ document.addEventListener('DOMContentLoaded', () => {
             id: form.id.value,
             title: form.title.value,
             content: form.content.value,
-            deadline: form.deadline.value
+            deadline: form.deadline.value,
+            priority: form.priority.value,
+            tags: parseTags(form.tags.value)
         };
 
         try {
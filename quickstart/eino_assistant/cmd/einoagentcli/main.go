@@ -20,7 +20,6 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
-	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -33,6 +32,7 @@ import (
 	"github.com/cloudwego/eino-ext/callbacks/apmplus"
 	clc "github.com/cloudwego/eino-ext/callbacks/cozeloop"
 	"github.com/cloudwego/eino-ext/callbacks/langfuse"
+	"github.com/cloudwego/eino-ext/components/model/ark"
 	"github.com/cloudwego/eino-ext/devops"
 	"github.com/cloudwego/eino/callbacks"
 	"github.com/cloudwego/eino/compose"
@@ -42,6 +42,7 @@ import (
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/eino/einoagent"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/env"
 	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/task"
 )
 
 var id = flag.String("id", "", "conversation id")
@@ -187,6 +188,18 @@ func Init() error {
 		callbacks.InitCallbackHandlers(callbackHandlers)
 	}
 
+	// keep a running summary of the messages that left the history window
+	if os.Getenv("MEMORY_SUMMARY") == "true" {
+		cm, err := ark.NewChatModel(ctx, &ark.ChatModelConfig{
+			Model:  os.Getenv("ARK_CHAT_MODEL"),
+			APIKey: os.Getenv("ARK_API_KEY"),
+		})
+		if err != nil {
+			return err
+		}
+		memory.SetSummarizer(mem.NewModelSummarizer(cm))
+	}
+
 	return nil
 }
 
@@ -197,12 +210,24 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 		return nil, fmt.Errorf("failed to build agent graph: %w", err)
 	}
 
-	conversation := memory.GetConversation(id, true)
+	conversation, err := memory.GetConversation(id, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	history, err := conversation.GetMessages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
 
 	userMessage := &einoagent.UserMessage{
 		ID:      id,
 		Query:   msg,
-		History: conversation.GetMessages(),
+		History: history,
+	}
+	if reminder, err := task.GetDefaultReminderScheduler().Current(); err != nil {
+		log.Printf("[Chat] Error listing task reminders: %v\n", err)
+	} else {
+		userMessage.Reminders = reminder.String()
 	}
 
 	sr, err := runner.Stream(ctx, userMessage, compose.WithCallbacks(cbHandler))
@@ -212,41 +237,10 @@ func RunAgent(ctx context.Context, id string, msg string) (*schema.StreamReader[
 
 	srs := sr.Copy(2)
 
+	// save the turn to memory from the second copy
 	go func() {
-		// for save to memory
-		fullMsgs := make([]*schema.Message, 0)
-
-		defer func() {
-			// close stream if you used it
-			srs[1].Close()
-
-			// add user input to history
-			conversation.Append(schema.UserMessage(msg))
-
-			fullMsg, err := schema.ConcatMessages(fullMsgs)
-			if err != nil {
-				fmt.Println("error concatenating messages: ", err.Error())
-			}
-			// add agent response to history
-			conversation.Append(fullMsg)
-		}()
-
-	outer:
-		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("context done", ctx.Err())
-				return
-			default:
-				chunk, err := srs[1].Recv()
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						break outer
-					}
-				}
-
-				fullMsgs = append(fullMsgs, chunk)
-			}
+		if err := conversation.RecordTurn(ctx, msg, srs[1]); err != nil {
+			log.Printf("[Chat] Error recording turn of chat ID %s: %v\n", id, err)
 		}
 	}()
 
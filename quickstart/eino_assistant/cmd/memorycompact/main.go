@@ -0,0 +1,62 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// memorycompact rewrites the JSONL files of the conversation memory. It can run while the
+// agent server or CLI is running, the memory directory is locked meanwhile.
+//
+//	go run cmd/memorycompact/main.go [-dir data/memory] [-id <conversation id>]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/mem"
+)
+
+var (
+	dir = flag.String("dir", "data/memory", "memory directory")
+	id  = flag.String("id", "", "conversation id, all conversations if empty")
+)
+
+func main() {
+	flag.Parse()
+
+	memory, err := mem.NewSimpleMemory(mem.SimpleMemoryConfig{Dir: *dir})
+	if err != nil {
+		log.Fatalf("open memory failed, err=%v", err)
+	}
+
+	var res *mem.CompactResult
+	if *id != "" {
+		res, err = memory.Compact(*id)
+	} else {
+		res, err = memory.CompactAll()
+	}
+	if err != nil {
+		log.Fatalf("compact failed, err=%v", err)
+	}
+
+	fmt.Printf("compacted %d conversation(s), %d message(s): %d -> %d bytes\n",
+		res.Conversations, res.Messages, res.BytesBefore, res.BytesAfter)
+	if res.Quarantined > 0 {
+		fmt.Printf("moved %d unreadable line(s) to %s/quarantine\n", res.Quarantined, *dir)
+	}
+	for _, name := range res.RemovedFiles {
+		fmt.Printf("removed %s\n", name)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// taskstore moves the tasks of the task tool between stores, and purges deleted tasks.
+// A store is "jsonl:<dir>", "sqlite:<file>", or "-" for JSON lines on stdin or stdout.
+//
+//	go run cmd/taskstore/main.go -from jsonl:data/task -to sqlite:data/task/tasks.db
+//	go run cmd/taskstore/main.go -from sqlite:data/task/tasks.db -to - > tasks.jsonl
+//	go run cmd/taskstore/main.go -from - -to sqlite:data/task/tasks.db < tasks.jsonl
+//	go run cmd/taskstore/main.go -purge 720h -to sqlite:data/task/tasks.db
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino-examples/quickstart/eino_assistant/pkg/tool/task"
+)
+
+var (
+	from  = flag.String("from", "", "store to read the tasks from")
+	to    = flag.String("to", "", "store to write the tasks to")
+	purge = flag.Duration("purge", 0, "after the copy, if any, remove from -to the tasks deleted longer ago than this")
+)
+
+func open(spec string) (task.TaskStore, error) {
+	kind, path, ok := strings.Cut(spec, ":")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid store %q, expected jsonl:<dir> or sqlite:<file>", spec)
+	}
+	return task.OpenStore(kind, path)
+}
+
+func main() {
+	flag.Parse()
+	if *to == "" || (*from == "" && *purge == 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *to == "-" {
+		src, err := open(*from)
+		if err != nil {
+			log.Fatalf("open store failed, err=%v", err)
+		}
+		defer src.Close()
+		n, err := task.Export(src, os.Stdout)
+		if err != nil {
+			log.Fatalf("export failed, err=%v", err)
+		}
+		log.Printf("exported %d task(s)", n)
+		return
+	}
+
+	dst, err := open(*to)
+	if err != nil {
+		log.Fatalf("open store failed, err=%v", err)
+	}
+	defer dst.Close()
+
+	switch *from {
+	case "":
+	case "-":
+		n, err := task.Import(dst, os.Stdin)
+		if err != nil {
+			log.Fatalf("import failed, err=%v", err)
+		}
+		log.Printf("imported %d task(s)", n)
+	default:
+		src, err := open(*from)
+		if err != nil {
+			log.Fatalf("open store failed, err=%v", err)
+		}
+		defer src.Close()
+		n, err := task.Copy(dst, src)
+		if err != nil {
+			log.Fatalf("copy failed, err=%v", err)
+		}
+		log.Printf("copied %d task(s)", n)
+	}
+
+	if *purge > 0 {
+		n, err := dst.Purge(time.Now().Add(-*purge))
+		if err != nil {
+			log.Fatalf("purge failed, err=%v", err)
+		}
+		log.Printf("purged %d deleted task(s)", n)
+	}
+}
@@ -28,9 +28,14 @@ func newLambda(ctx context.Context, input *UserMessage, opts ...any) (output str
 
 // newLambda2 component initialization function of node 'InputToHistory' in graph 'EinoAgent'
 func newLambda2(ctx context.Context, input *UserMessage, opts ...any) (output map[string]any, err error) {
+	reminders := input.Reminders
+	if reminders == "" {
+		reminders = "none"
+	}
 	return map[string]any{
-		"content": input.Query,
-		"history": input.History,
-		"date":    time.Now().Format("2006-01-02 15:04:05"),
+		"content":   input.Query,
+		"history":   input.History,
+		"date":      time.Now().Format("2006-01-02 15:04:05"),
+		"reminders": reminders,
 	}, nil
 }
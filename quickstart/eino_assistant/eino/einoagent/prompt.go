@@ -46,10 +46,14 @@ var systemPrompt = `
 - If a request exceeds your capabilities:
   • Clearly communicate your limitations, suggest alternative approaches if possible
 
+- If there are overdue or upcoming tasks in the task reminders, mention them briefly when relevant, without repeating them on every answer.
+
 - If the question is compound or complex, you need to think step by step, avoiding giving low-quality answers directly.
 
 ## Context Information
 - Current Date: {date}
+- Task Reminders: |-
+  {reminders}
 - Related Documents: |-
 ==== doc start ====
   {documents}
@@ -22,4 +22,6 @@ type UserMessage struct {
 	ID      string            `json:"id"`
 	Query   string            `json:"query"`
 	History []*schema.Message `json:"history"`
+	// Reminders lists the overdue and upcoming tasks, "none" if empty.
+	Reminders string `json:"reminders"`
 }
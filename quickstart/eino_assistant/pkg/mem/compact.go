@@ -0,0 +1,144 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompactResult reports what a compaction did.
+type CompactResult struct {
+	Conversations int `json:"conversations"`
+	Messages      int `json:"messages"`
+	// Quarantined is the number of bad lines moved to the quarantine.
+	Quarantined int `json:"quarantined"`
+	// RemovedFiles are the metadata files without a conversation and the temporary files
+	// left by interrupted writes.
+	RemovedFiles []string `json:"removed_files,omitempty"`
+	BytesBefore  int64    `json:"bytes_before"`
+	BytesAfter   int64    `json:"bytes_after"`
+}
+
+// Compact rewrites the messages file of a conversation with one message per line,
+// moving the lines that cannot be read to the quarantine.
+func (m *SimpleMemory) Compact(id string) (*CompactResult, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	res := &CompactResult{}
+	err := m.withLock(func() error {
+		return m.compact(id, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CompactAll compacts every conversation, then removes the files nothing refers to.
+func (m *SimpleMemory) CompactAll() (*CompactResult, error) {
+	res := &CompactResult{}
+	err := m.withLock(func() error {
+		ids, err := m.listIDs()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err = m.compact(id, res); err != nil && !errors.Is(err, ErrConversationNotFound) {
+				return fmt.Errorf("conversation %s: %w", id, err)
+			}
+		}
+		return m.removeLeftovers(ids, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (m *SimpleMemory) compact(id string, res *CompactResult) error {
+	path := filepath.Join(m.dir, id+messagesFileExt)
+	fi, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrConversationNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	msgs, bad, _, err := readMessages(path, 0)
+	if err != nil {
+		return err
+	}
+	if len(bad) > 0 {
+		if err = quarantine(m.dir, id, bad); err != nil {
+			return err
+		}
+		if err = resetSummary(m.dir, id, bad[0].Index); err != nil {
+			return err
+		}
+	}
+	data, err := encodeMessages(msgs)
+	if err != nil {
+		return err
+	}
+	// cached conversations see the file was replaced and read it again
+	if err = writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to rewrite messages file: %w", err)
+	}
+
+	res.Conversations++
+	res.Messages += len(msgs)
+	res.Quarantined += len(bad)
+	res.BytesBefore += fi.Size()
+	res.BytesAfter += int64(len(data))
+	return nil
+}
+
+func (m *SimpleMemory) removeLeftovers(ids []string, res *CompactResult) error {
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+
+	files, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read memory dir: %w", err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() {
+			continue
+		}
+		orphanMeta := strings.HasSuffix(name, metaFileExt) && !known[strings.TrimSuffix(name, metaFileExt)]
+		// the lock is held, so no write is in progress
+		temp := strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-")
+		if !orphanMeta && !temp {
+			continue
+		}
+		if err = os.Remove(filepath.Join(m.dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		res.RemovedFiles = append(res.RemovedFiles, name)
+	}
+	return nil
+}
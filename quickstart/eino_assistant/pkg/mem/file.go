@@ -0,0 +1,210 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// quarantineDir holds the lines of messages files that could not be read, one file per conversation.
+const quarantineDir = "quarantine"
+
+// badLine is a line of a messages file that does not decode to a message, or the last line
+// of the file left without its newline by an interrupted append.
+type badLine struct {
+	Offset int64
+	// Index is the number of messages read before the line.
+	Index int
+	Data  string
+	Err   string
+}
+
+// readMessages reads the messages of a JSONL file from offset on, and returns the offset of
+// the end of what it read.
+func readMessages(path string, offset int64) (msgs []*schema.Message, bad []badLine, end int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, offset, err
+	}
+	defer f.Close()
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return nil, nil, offset, fmt.Errorf("failed to seek messages file: %w", err)
+	}
+
+	// unlike bufio.Scanner, a reader has no limit on the length of a line
+	r := bufio.NewReader(f)
+	end = offset
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			at := end
+			end += int64(len(line))
+			if !bytes.HasSuffix(line, []byte("\n")) {
+				bad = append(bad, badLine{Offset: at, Index: len(msgs), Data: string(line), Err: "truncated line"})
+			} else if data := bytes.TrimSpace(line); len(data) > 0 {
+				msg := &schema.Message{}
+				if uerr := json.Unmarshal(data, msg); uerr != nil {
+					bad = append(bad, badLine{Offset: at, Index: len(msgs), Data: string(data), Err: uerr.Error()})
+				} else {
+					msgs = append(msgs, msg)
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return msgs, bad, end, nil
+		}
+		if err != nil {
+			return nil, nil, offset, fmt.Errorf("failed to read messages file: %w", err)
+		}
+	}
+}
+
+func encodeMessages(msgs []*schema.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		line, err := json.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// appendLine appends one line to path and syncs it to disk before returning, so that an
+// acknowledged message survives a crash.
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open messages file: %w", err)
+	}
+	// one write, so that a crash leaves at most a truncated last line
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to append message: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync messages file: %w", err)
+	}
+	return f.Close()
+}
+
+// tempPattern names the temporary files of writeFileAtomic, hidden and never ending in a
+// known extension, so that listings skip them.
+func tempPattern(path string) string {
+	return "." + filepath.Base(path) + ".tmp-*"
+}
+
+// writeFileAtomic replaces path with data through a synced temporary file and a rename,
+// so that readers see either the old or the new content, even after a crash.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, tempPattern(path))
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		// no-op once the rename succeeded
+		_ = os.Remove(tmpName)
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir makes a rename in dir durable. Not every platform can sync a directory, so it is best effort.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+}
+
+type quarantineRecord struct {
+	ConversationID string    `json:"conversation_id"`
+	Offset         int64     `json:"offset"`
+	Error          string    `json:"error"`
+	Data           string    `json:"data"`
+	QuarantinedAt  time.Time `json:"quarantined_at"`
+}
+
+// quarantine keeps the bad lines of a conversation aside for inspection, in
+// <dir>/quarantine/<id>.jsonl, before they are dropped from its messages file.
+func quarantine(dir, id string, bad []badLine) error {
+	qdir := filepath.Join(dir, quarantineDir)
+	if err := os.MkdirAll(qdir, 0755); err != nil {
+		return fmt.Errorf("failed to create quarantine dir: %w", err)
+	}
+
+	var buf bytes.Buffer
+	now := time.Now()
+	for _, b := range bad {
+		line, err := json.Marshal(&quarantineRecord{
+			ConversationID: id,
+			Offset:         b.Offset,
+			Error:          b.Err,
+			Data:           b.Data,
+			QuarantinedAt:  now,
+		})
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(filepath.Join(qdir, id+messagesFileExt), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open quarantine file: %w", err)
+	}
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write quarantine file: %w", err)
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
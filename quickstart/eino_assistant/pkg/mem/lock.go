@@ -0,0 +1,56 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const lockFileName = ".lock"
+
+// dirLock is the exclusive lock of a memory directory. The file lock keeps processes apart,
+// e.g. the CLI and the web server, the mutex keeps goroutines apart since a file lock is held
+// by the process as a whole.
+type dirLock struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func newDirLock(dir string) (*dirLock, error) {
+	f, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	return &dirLock{f: f}, nil
+}
+
+func (l *dirLock) lock() error {
+	l.mu.Lock()
+	if err := lockFile(l.f); err != nil {
+		l.mu.Unlock()
+		return fmt.Errorf("failed to lock memory dir: %w", err)
+	}
+	return nil
+}
+
+func (l *dirLock) unlock() {
+	_ = unlockFile(l.f)
+	l.mu.Unlock()
+}
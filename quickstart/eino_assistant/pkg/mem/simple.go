@@ -17,192 +17,803 @@
 package mem
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cloudwego/eino/schema"
 )
 
+var (
+	defaultMemory     *SimpleMemory
+	defaultMemoryOnce sync.Once
+)
+
+// GetDefaultMemory returns the memory shared by the agent and the history endpoints.
+// It panics if the memory directory cannot be created.
 func GetDefaultMemory() *SimpleMemory {
-	return NewSimpleMemory(SimpleMemoryConfig{
-		Dir:           "data/memory",
-		MaxWindowSize: 6,
+	defaultMemoryOnce.Do(func() {
+		var err error
+		defaultMemory, err = NewSimpleMemory(SimpleMemoryConfig{
+			Dir:             "data/memory",
+			MaxWindowSize:   6,
+			MaxWindowTokens: 2000,
+		})
+		if err != nil {
+			panic(fmt.Sprintf("failed to create default memory: %v", err))
+		}
 	})
+	return defaultMemory
+}
+
+// ErrConversationNotFound is returned for operations on a conversation that does not exist.
+var ErrConversationNotFound = errors.New("conversation not found")
+
+var conversationIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]{0,127}$`)
+
+// checkID rejects IDs that would not map to a single file of the memory directory.
+func checkID(id string) error {
+	if !conversationIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid conversation id %q", id)
+	}
+	return nil
 }
 
 type SimpleMemoryConfig struct {
-	Dir           string
+	Dir string
+
+	// MaxWindowSize is the maximum number of messages sent to the model with each query.
+	// An assistant message calling tools and its tool results are kept or dropped together.
+	// Zero means no limit.
 	MaxWindowSize int
+	// MaxWindowTokens is the maximum number of tokens of those messages, summary included.
+	// The newest message is always sent, even over the budget. Zero means no limit.
+	MaxWindowTokens int
+	// TokenCounter counts the tokens of a message. Defaults to ApproxTokenCounter.
+	TokenCounter TokenCounter
+
+	// Summarizer, if set, keeps a running summary of the messages that left the window,
+	// sent in front of the window. See Conversation.UpdateSummary.
+	Summarizer Summarizer
 }
 
-func NewSimpleMemory(cfg SimpleMemoryConfig) *SimpleMemory {
+// NewSimpleMemory opens the memory directory, creating it if needed. Several processes,
+// e.g. the CLI and the web server, can share the directory: every operation locks it and
+// picks up the changes made by the others first.
+func NewSimpleMemory(cfg SimpleMemoryConfig) (*SimpleMemory, error) {
 	if cfg.Dir == "" {
 		cfg.Dir = "/tmp/eino/memory"
 	}
 	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
-		return nil
+		return nil, fmt.Errorf("failed to create memory dir: %w", err)
+	}
+	lock, err := newDirLock(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TokenCounter == nil {
+		cfg.TokenCounter = ApproxTokenCounter
 	}
 
 	return &SimpleMemory{
-		dir:           cfg.Dir,
-		maxWindowSize: cfg.MaxWindowSize,
+		dir:  cfg.Dir,
+		lock: lock,
+		policy: &windowPolicy{
+			maxMessages: cfg.MaxWindowSize,
+			maxTokens:   cfg.MaxWindowTokens,
+			counter:     cfg.TokenCounter,
+		},
+		summarizer:    cfg.Summarizer,
 		conversations: make(map[string]*Conversation),
-	}
+	}, nil
 }
 
 // simple memory can store messages of each conversation
 type SimpleMemory struct {
-	mu            sync.Mutex
-	dir           string
-	maxWindowSize int
+	dir    string
+	lock   *dirLock
+	policy *windowPolicy
+
+	mu         sync.Mutex
+	summarizer Summarizer
+
+	// conversations caches the conversations read so far, guarded by lock
 	conversations map[string]*Conversation
 }
 
-func (m *SimpleMemory) GetConversation(id string, createIfNotExist bool) *Conversation {
+// SetSummarizer sets the Summarizer of all conversations, nil disables the running summary.
+func (m *SimpleMemory) SetSummarizer(s Summarizer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summarizer = s
+}
+
+func (m *SimpleMemory) getSummarizer() Summarizer {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.summarizer
+}
+
+// withLock runs fn holding the lock of the memory directory.
+func (m *SimpleMemory) withLock(fn func() error) error {
+	if err := m.lock.lock(); err != nil {
+		return err
+	}
+	defer m.lock.unlock()
+	return fn()
+}
+
+const (
+	messagesFileExt = ".jsonl"
+	metaFileExt     = ".meta.json"
+)
+
+// GetConversation returns the conversation, creating it if asked to.
+// It returns ErrConversationNotFound if the conversation does not exist and is not created.
+func (m *SimpleMemory) GetConversation(id string, createIfNotExist bool) (*Conversation, error) {
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
 
-	_, ok := m.conversations[id]
+	var con *Conversation
+	err := m.withLock(func() error {
+		var err error
+		con, err = m.getConversation(id, createIfNotExist)
+		return err
+	})
+	return con, err
+}
 
-	filePath := filepath.Join(m.dir, id+".jsonl")
+// getConversation must be called with the directory locked.
+func (m *SimpleMemory) getConversation(id string, createIfNotExist bool) (*Conversation, error) {
+	con, ok := m.conversations[id]
 	if !ok {
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			if createIfNotExist {
-				if err := os.WriteFile(filePath, []byte(""), 0644); err != nil {
-					return nil
-				}
-				m.conversations[id] = &Conversation{
-					ID:            id,
-					Messages:      make([]*schema.Message, 0),
-					filePath:      filePath,
-					maxWindowSize: m.maxWindowSize,
-				}
-			}
+		con = &Conversation{
+			ID:       id,
+			Messages: make([]*schema.Message, 0),
+			filePath: filepath.Join(m.dir, id+messagesFileExt),
+			metaPath: filepath.Join(m.dir, id+metaFileExt),
+			memory:   m,
 		}
+	}
 
-		con := &Conversation{
-			ID:            id,
-			Messages:      make([]*schema.Message, 0),
-			filePath:      filePath,
-			maxWindowSize: m.maxWindowSize,
+	con.mu.Lock()
+	defer con.mu.Unlock()
+
+	err := con.refresh()
+	if errors.Is(err, ErrConversationNotFound) {
+		// never created, or deleted by another process
+		delete(m.conversations, id)
+		if !createIfNotExist {
+			return nil, err
 		}
-		con.load()
-		m.conversations[id] = con
+		err = con.create()
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return m.conversations[id]
+	m.conversations[id] = con
+	return con, nil
 }
 
-func (m *SimpleMemory) ListConversations() []string {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// ListConversations returns the IDs of all conversations.
+func (m *SimpleMemory) ListConversations() ([]string, error) {
+	var ids []string
+	err := m.withLock(func() error {
+		var err error
+		ids, err = m.listIDs()
+		return err
+	})
+	return ids, err
+}
 
+func (m *SimpleMemory) listIDs() ([]string, error) {
 	files, err := os.ReadDir(m.dir)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("failed to read memory dir: %w", err)
 	}
 
 	ids := make([]string, 0, len(files))
 	for _, file := range files {
-		if file.IsDir() {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, messagesFileExt) || strings.HasPrefix(name, ".") {
 			continue
 		}
-		ids = append(ids, strings.TrimSuffix(file.Name(), ".jsonl"))
+		ids = append(ids, strings.TrimSuffix(name, messagesFileExt))
 	}
 
-	return ids
+	return ids, nil
 }
 
-func (m *SimpleMemory) DeleteConversation(id string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// allConversations returns every conversation, up to date. It must be called with the directory locked.
+func (m *SimpleMemory) allConversations() ([]*Conversation, error) {
+	ids, err := m.listIDs()
+	if err != nil {
+		return nil, err
+	}
+	cons := make([]*Conversation, 0, len(ids))
+	for _, id := range ids {
+		con, err := m.getConversation(id, false)
+		if errors.Is(err, ErrConversationNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("conversation %s: %w", id, err)
+		}
+		cons = append(cons, con)
+	}
+	return cons, nil
+}
+
+// ConversationInfo is the metadata of a conversation.
+type ConversationInfo struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MessageCount int       `json:"message_count"`
+}
+
+// ListConversationInfos returns the metadata of all conversations, most recently updated first.
+func (m *SimpleMemory) ListConversationInfos() ([]ConversationInfo, error) {
+	infos := make([]ConversationInfo, 0)
+	err := m.withLock(func() error {
+		cons, err := m.allConversations()
+		if err != nil {
+			return err
+		}
+		for _, con := range cons {
+			infos = append(infos, con.info())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].UpdatedAt.After(infos[j].UpdatedAt)
+	})
+	return infos, nil
+}
 
-	filePath := filepath.Join(m.dir, id+".jsonl")
-	if err := os.Remove(filePath); err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+// RenameConversation sets the title of a conversation.
+func (m *SimpleMemory) RenameConversation(id, title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("title must not be empty")
+	}
+	con, err := m.GetConversation(id, false)
+	if err != nil {
+		return err
 	}
+	return con.SetTitle(title)
+}
 
-	delete(m.conversations, id)
-	return nil
+// SearchResult is a conversation matching a search, with the text around the first match.
+type SearchResult struct {
+	ConversationInfo
+	Snippet string `json:"snippet"`
+}
+
+// SearchConversations returns up to limit conversations whose title or messages contain
+// query, case-insensitively, most recently updated first. A limit <= 0 returns all matches.
+func (m *SimpleMemory) SearchConversations(query string, limit int) ([]SearchResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+
+	results := make([]SearchResult, 0)
+	err := m.withLock(func() error {
+		cons, err := m.allConversations()
+		if err != nil {
+			return err
+		}
+		for _, con := range cons {
+			if snippet, ok := con.match(query); ok {
+				results = append(results, SearchResult{ConversationInfo: con.info(), Snippet: snippet})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].UpdatedAt.After(results[j].UpdatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// DeleteConversation removes the files of a conversation, its quarantined lines excepted.
+func (m *SimpleMemory) DeleteConversation(id string) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+
+	return m.withLock(func() error {
+		delete(m.conversations, id)
+
+		err := os.Remove(filepath.Join(m.dir, id+messagesFileExt))
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrConversationNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+		if err = os.Remove(filepath.Join(m.dir, id+metaFileExt)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to delete metadata file: %w", err)
+		}
+		return nil
+	})
 }
 
 type Conversation struct {
 	mu sync.Mutex
 
-	ID       string            `json:"id"`
-	Messages []*schema.Message `json:"messages"`
+	ID        string            `json:"id"`
+	Title     string            `json:"title"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Messages  []*schema.Message `json:"messages"`
+
+	// Summary is the running summary of the first SummarizedCount messages.
+	Summary         string `json:"summary,omitempty"`
+	SummarizedCount int    `json:"summarized_count,omitempty"`
 
 	filePath string
+	metaPath string
+
+	// file and meta are the files as last read, offset is where reading file stopped
+	file   os.FileInfo
+	offset int64
+	meta   os.FileInfo
+
+	memory *SimpleMemory
+}
 
-	maxWindowSize int
+// conversationMeta is what the metadata file of a conversation holds.
+type conversationMeta struct {
+	Title           string    `json:"title"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Summary         string    `json:"summary,omitempty"`
+	SummarizedCount int       `json:"summarized_count,omitempty"`
 }
 
-func (c *Conversation) Append(msg *schema.Message) {
+// MarshalJSON encodes the conversation as last read.
+func (c *Conversation) MarshalJSON() ([]byte, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.Messages = append(c.Messages, msg)
+	// plain has the fields of Conversation without its methods
+	type plain Conversation
+	return json.Marshal((*plain)(c))
+}
+
+// locked runs fn with the memory directory locked and the conversation up to date with its files.
+func (c *Conversation) locked(fn func() error) error {
+	return c.memory.withLock(func() error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if err := c.refresh(); err != nil {
+			return err
+		}
+		return fn()
+	})
+}
+
+// Append adds a message to the conversation. The message is on disk when Append returns.
+func (c *Conversation) Append(msg *schema.Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
 
-	c.save(msg)
+	return c.locked(func() error {
+		if err := appendLine(c.filePath, line); err != nil {
+			return err
+		}
+		c.Messages = append(c.Messages, msg)
+		if err := c.stat(); err != nil {
+			return err
+		}
+
+		if c.Title == "" && msg.Role == schema.User {
+			c.Title = defaultTitle(msg.Content)
+		}
+		c.UpdatedAt = time.Now()
+		return c.saveMeta()
+	})
 }
 
-func (c *Conversation) GetFullMessages() []*schema.Message {
+// GetFullMessages returns all the messages of the conversation.
+func (c *Conversation) GetFullMessages() ([]*schema.Message, error) {
+	var msgs []*schema.Message
+	err := c.locked(func() error {
+		msgs = append([]*schema.Message(nil), c.Messages...)
+		return nil
+	})
+	return msgs, err
+}
+
+// GetMessages returns the window of recent messages sent to the model, bounded by the
+// message and token limits of the memory, preceded by the running summary if there is one.
+func (c *Conversation) GetMessages() ([]*schema.Message, error) {
+	var msgs []*schema.Message
+	err := c.locked(func() error {
+		policy := c.memory.policy
+		reserved := 0
+		var summary *schema.Message
+		if c.Summary != "" {
+			summary = summaryMessage(c.Summary)
+			reserved = policy.counter(summary)
+		}
+
+		start := policy.start(c.Messages, reserved)
+		msgs = window(c.Messages, start)
+		if summary != nil && start > 0 {
+			msgs = append([]*schema.Message{summary}, msgs...)
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+// UpdateSummary folds the messages that left the window since the last update into the
+// running summary. It does nothing without a Summarizer. It calls the model, so call it
+// after a turn rather than before the next one.
+func (c *Conversation) UpdateSummary(ctx context.Context) error {
+	summarizer := c.memory.getSummarizer()
+	if summarizer == nil {
+		return nil
+	}
+
+	var (
+		from, start int
+		previous    string
+		evicted     []*schema.Message
+	)
+	err := c.locked(func() error {
+		policy := c.memory.policy
+		reserved := 0
+		if c.Summary != "" {
+			reserved = policy.counter(summaryMessage(c.Summary))
+		}
+		start = policy.start(c.Messages, reserved)
+		from, previous = c.SummarizedCount, c.Summary
+		if start > from {
+			evicted = append(evicted, c.Messages[from:start]...)
+		}
+		return nil
+	})
+	if err != nil || len(evicted) == 0 {
+		return err
+	}
+
+	// the lock is not held while the model runs, the messages before start never change
+	summary, err := summarizer(ctx, previous, evicted)
+	if err != nil {
+		return err
+	}
+
+	return c.locked(func() error {
+		if c.SummarizedCount != from || c.Summary != previous {
+			// another update won the race, its summary covers these messages already
+			return nil
+		}
+		c.Summary, c.SummarizedCount = summary, start
+		return c.saveMeta()
+	})
+}
+
+// RecordTurn reads the reply streamed to query until it ends, then appends the query and the
+// reply to the conversation and updates the summary. A reply cut short by an error or by the
+// end of ctx is kept as far as it went, and no reply is appended when nothing was received.
+// It closes sr, and is meant to run on its own goroutine with a copy of the stream sent to the user.
+func (c *Conversation) RecordTurn(ctx context.Context, query string, sr *schema.StreamReader[*schema.Message]) error {
+	defer sr.Close()
+
+	var chunks []*schema.Message
+	for ctx.Err() == nil {
+		chunk, err := sr.Recv()
+		if err != nil {
+			// a cancelled run ends with the context error instead of io.EOF
+			break
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if err := c.Append(schema.UserMessage(query)); err != nil {
+		return fmt.Errorf("failed to save user message: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil
+	}
+	reply, err := schema.ConcatMessages(chunks)
+	if err != nil {
+		return fmt.Errorf("failed to concatenate reply: %w", err)
+	}
+	if err = c.Append(reply); err != nil {
+		return fmt.Errorf("failed to save reply: %w", err)
+	}
+
+	// the run is over, so ctx may be cancelled already
+	if err = c.UpdateSummary(context.Background()); err != nil {
+		return fmt.Errorf("failed to update summary: %w", err)
+	}
+	return nil
+}
+
+// SetTitle renames the conversation.
+func (c *Conversation) SetTitle(title string) error {
+	return c.locked(func() error {
+		c.Title = title
+		c.UpdatedAt = time.Now()
+		return c.saveMeta()
+	})
+}
+
+// Info returns the metadata of the conversation as last read.
+func (c *Conversation) Info() ConversationInfo {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	return c.Messages
+	return c.info()
 }
 
-// get messages with max window size
-func (c *Conversation) GetMessages() []*schema.Message {
+func (c *Conversation) info() ConversationInfo {
+	return ConversationInfo{
+		ID:           c.ID,
+		Title:        c.Title,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+		MessageCount: len(c.Messages),
+	}
+}
+
+const snippetRunes = 40
+
+// match reports whether the title or a message contains query, which is lower case,
+// with the text around the first match.
+func (c *Conversation) match(query string) (string, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if len(c.Messages) > c.maxWindowSize {
-		return c.Messages[len(c.Messages)-c.maxWindowSize:]
+	if strings.Contains(strings.ToLower(c.Title), query) {
+		return c.Title, true
+	}
+	for _, msg := range c.Messages {
+		if snippet, ok := snippetOf(msg.Content, query); ok {
+			return snippet, true
+		}
+	}
+	return "", false
+}
+
+func snippetOf(content, query string) (string, bool) {
+	lower := strings.ToLower(content)
+	idx := strings.Index(lower, query)
+	if idx < 0 {
+		return "", false
+	}
+	// lowering may change the byte length of some runes, cut the lowered text then
+	text := content
+	if len(lower) != len(content) {
+		text = lower
 	}
 
-	return c.Messages
+	runes := []rune(text)
+	at := len([]rune(text[:idx]))
+	from, to := at-snippetRunes, at+len([]rune(query))+snippetRunes
+	prefix, suffix := "...", "..."
+	if from <= 0 {
+		from, prefix = 0, ""
+	}
+	if to >= len(runes) {
+		to, suffix = len(runes), ""
+	}
+	return prefix + strings.Join(strings.Fields(string(runes[from:to])), " ") + suffix, true
 }
 
-func (c *Conversation) load() error {
-	reader, err := os.Open(c.filePath)
+// create writes the files of a new conversation. c.mu must be held with the directory locked.
+func (c *Conversation) create() error {
+	f, err := os.OpenFile(c.filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return fmt.Errorf("failed to create messages file: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(c.filePath))
+
+	now := time.Now()
+	c.Messages = make([]*schema.Message, 0)
+	c.Title, c.Summary, c.SummarizedCount = "", "", 0
+	c.CreatedAt, c.UpdatedAt = now, now
+	c.file, c.offset, c.meta = nil, 0, nil
+	if err = c.stat(); err != nil {
+		return err
+	}
+	return c.saveMeta()
+}
+
+// refresh picks up what other processes wrote since the files were last read: the new lines
+// of the messages file, or all of it once it was replaced, e.g. by a compaction, and the
+// metadata file. Bad lines are moved to the quarantine. c.mu must be held with the directory locked.
+func (c *Conversation) refresh() error {
+	fi, err := os.Stat(c.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrConversationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat messages file: %w", err)
 	}
-	defer reader.Close()
 
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-		var msg schema.Message
-		if err := json.Unmarshal([]byte(line), &msg); err != nil {
-			return fmt.Errorf("failed to unmarshal message: %w", err)
+	if c.file == nil || !os.SameFile(fi, c.file) || fi.Size() < c.offset {
+		c.Messages = make([]*schema.Message, 0)
+		c.offset = 0
+		c.meta = nil
+	}
+	if fi.Size() > c.offset {
+		msgs, bad, end, err := readMessages(c.filePath, c.offset)
+		if err != nil {
+			return err
+		}
+		read := len(c.Messages)
+		c.Messages = append(c.Messages, msgs...)
+		c.offset = end
+		if len(bad) > 0 {
+			if err = c.quarantine(bad, read+bad[0].Index); err != nil {
+				return err
+			}
 		}
-		c.Messages = append(c.Messages, &msg)
+	}
+	if err = c.stat(); err != nil {
+		return err
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanner error: %w", err)
+	return c.refreshMeta()
+}
+
+// quarantine moves bad lines out of the messages file, which is rewritten with the messages read.
+// firstBad is the number of messages before the first bad line. The metadata file is read
+// again by refresh afterwards.
+func (c *Conversation) quarantine(bad []badLine, firstBad int) error {
+	if err := quarantine(c.memory.dir, c.ID, bad); err != nil {
+		return err
+	}
+	data, err := encodeMessages(c.Messages)
+	if err != nil {
+		return err
+	}
+	if err = writeFileAtomic(c.filePath, data); err != nil {
+		return fmt.Errorf("failed to rewrite messages file: %w", err)
 	}
+	return resetSummary(c.memory.dir, c.ID, firstBad)
+}
 
+// stat records the messages file as read up to its end, after c wrote it.
+func (c *Conversation) stat() error {
+	fi, err := os.Stat(c.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat messages file: %w", err)
+	}
+	c.file, c.offset = fi, fi.Size()
 	return nil
 }
 
-func (c *Conversation) save(msg *schema.Message) {
-	str, _ := json.Marshal(msg)
+// refreshMeta reads the metadata file if it changed. Conversations written before it existed
+// get their times from the messages file and their title from the first user message.
+func (c *Conversation) refreshMeta() error {
+	fi, err := os.Stat(c.metaPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		if c.meta == nil && c.CreatedAt.IsZero() {
+			c.CreatedAt, c.UpdatedAt = c.file.ModTime(), c.file.ModTime()
+		}
+		if c.Title == "" {
+			for _, msg := range c.Messages {
+				if msg.Role == schema.User {
+					c.Title = defaultTitle(msg.Content)
+					break
+				}
+			}
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat metadata file: %w", err)
+	}
+	if c.meta != nil && os.SameFile(fi, c.meta) && fi.ModTime().Equal(c.meta.ModTime()) && fi.Size() == c.meta.Size() {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.metaPath)
+	if err != nil {
+		return fmt.Errorf("failed to read metadata file: %w", err)
+	}
+	var meta conversationMeta
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	c.Title, c.CreatedAt, c.UpdatedAt = meta.Title, meta.CreatedAt, meta.UpdatedAt
+	c.Summary, c.SummarizedCount = meta.Summary, meta.SummarizedCount
+	if c.SummarizedCount > len(c.Messages) {
+		// the messages it covered are gone, e.g. quarantined
+		c.Summary, c.SummarizedCount = "", 0
+	}
+	c.meta = fi
+	return nil
+}
 
-	// Append to file
-	f, err := os.OpenFile(c.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// resetSummary drops the running summary of conversation id when it covers more than the
+// firstBad messages read before the first bad line. A bad line may have been a readable message
+// when the summary was made, e.g. one appended after a line cut by a crash, so removing it shifts
+// the messages after it, and the summary no longer covers the first SummarizedCount ones. The next
+// UpdateSummary makes it again from the start. The directory must be locked.
+func resetSummary(dir, id string, firstBad int) error {
+	path := filepath.Join(dir, id+metaFileExt)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
-		return
+		return fmt.Errorf("failed to read metadata file: %w", err)
+	}
+	var meta conversationMeta
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
-	defer f.Close()
-	f.Write(str)
-	f.WriteString("\n")
+	if meta.SummarizedCount <= firstBad {
+		return nil
+	}
+
+	meta.Summary, meta.SummarizedCount = "", 0
+	if data, err = json.Marshal(&meta); err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err = writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	return nil
+}
+
+// saveMeta replaces the metadata file. c.mu must be held with the directory locked.
+func (c *Conversation) saveMeta() error {
+	data, err := json.Marshal(&conversationMeta{
+		Title:           c.Title,
+		CreatedAt:       c.CreatedAt,
+		UpdatedAt:       c.UpdatedAt,
+		Summary:         c.Summary,
+		SummarizedCount: c.SummarizedCount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err = writeFileAtomic(c.metaPath, data); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
+
+	fi, err := os.Stat(c.metaPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat metadata file: %w", err)
+	}
+	c.meta = fi
+	return nil
 }
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestCorruptedLinesAreQuarantined(t *testing.T) {
+	dir := t.TempDir()
+	good, _ := encodeMessages([]*schema.Message{schema.UserMessage("first"), schema.AssistantMessage("second", nil)})
+	lines := strings.SplitAfter(string(good), "\n")
+	// a bad line in the middle, and a last line cut by a crash
+	content := lines[0] + "{not json\n" + lines[1] + `{"role":"user","con`
+	if err := os.WriteFile(filepath.Join(dir, "c1.jsonl"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := mustMemory(t, SimpleMemoryConfig{Dir: dir})
+	con, err := m.GetConversation("c1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := con.GetFullMessages()
+	if err != nil || len(msgs) != 2 || msgs[1].Content != "second" {
+		t.Fatalf("expected the two good messages, got %v (%v)", msgs, err)
+	}
+
+	// the messages file only keeps good lines, so that appends land after them
+	mustAppend(t, con, schema.UserMessage("third"))
+	data, _ := os.ReadFile(filepath.Join(dir, "c1.jsonl"))
+	if got := strings.Count(string(data), "\n"); got != 3 {
+		t.Fatalf("expected 3 lines after the rewrite, got %d:\n%s", got, data)
+	}
+
+	q, err := os.Open(filepath.Join(dir, quarantineDir, "c1.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	n := 0
+	for sc := bufio.NewScanner(q); sc.Scan(); n++ {
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 quarantined lines, got %d", n)
+	}
+}
+
+func TestInstancesShareDirectory(t *testing.T) {
+	dir := t.TempDir()
+	// two instances stand for the web server and the CLI
+	server := mustMemory(t, SimpleMemoryConfig{Dir: dir})
+	cli := mustMemory(t, SimpleMemoryConfig{Dir: dir})
+
+	mustAppend(t, mustConversation(t, server, "c1"), schema.UserMessage("from server"))
+	cliCon := mustConversation(t, cli, "c1")
+	mustAppend(t, cliCon, schema.AssistantMessage("from cli", nil))
+
+	serverCon := mustConversation(t, server, "c1")
+	msgs, err := serverCon.GetFullMessages()
+	if err != nil || len(msgs) != 2 || msgs[1].Content != "from cli" {
+		t.Fatalf("server did not see the append of the cli: %v (%v)", msgs, err)
+	}
+
+	if err = cli.RenameConversation("c1", "Shared"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = serverCon.GetMessages(); err != nil || serverCon.Info().Title != "Shared" {
+		t.Fatalf("server did not see the rename: %q (%v)", serverCon.Info().Title, err)
+	}
+
+	res, err := cli.CompactAll()
+	if err != nil || res.Conversations != 1 || res.Messages != 2 {
+		t.Fatalf("unexpected compaction: %+v (%v)", res, err)
+	}
+	mustAppend(t, serverCon, schema.UserMessage("after compaction"))
+	if msgs, err = cliCon.GetFullMessages(); err != nil || len(msgs) != 3 {
+		t.Fatalf("expected 3 messages after compaction, got %v (%v)", msgs, err)
+	}
+
+	if err = cli.DeleteConversation("c1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = serverCon.Append(schema.UserMessage("too late")); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected ErrConversationNotFound, got %v", err)
+	}
+	if _, err = server.GetConversation("../c1", true); err == nil {
+		t.Fatalf("expected an invalid id error")
+	}
+}
+
+func TestRecordTurn(t *testing.T) {
+	m := mustMemory(t, SimpleMemoryConfig{
+		Dir:             t.TempDir(),
+		MaxWindowTokens: 2,
+		TokenCounter:    func(msg *schema.Message) int { return len(msg.Content) },
+	})
+	summarized := 0
+	m.SetSummarizer(func(_ context.Context, previous string, msgs []*schema.Message) (string, error) {
+		summarized += len(msgs)
+		return "S", nil
+	})
+	con := mustConversation(t, m, "c1")
+
+	reply := schema.StreamReaderFromArray([]*schema.Message{
+		schema.AssistantMessage("a", nil), schema.AssistantMessage("b", nil)})
+	if err := con.RecordTurn(context.Background(), "q1", reply); err != nil {
+		t.Fatal(err)
+	}
+	// a run that produced nothing, e.g. cancelled at once, only keeps the query
+	if err := con.RecordTurn(context.Background(), "q2", schema.StreamReaderFromArray([]*schema.Message(nil))); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, err := con.GetFullMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, msg := range msgs {
+		got = append(got, string(msg.Role)+":"+msg.Content)
+	}
+	if strings.Join(got, " ") != "user:q1 assistant:ab user:q2" {
+		t.Fatalf("unexpected messages %v", got)
+	}
+	// q1 left the window of 2 tokens after the first turn, the second one has no reply to summarize after
+	if summarized != 1 || con.Summary != "S" || con.SummarizedCount != 1 {
+		t.Fatalf("expected the turns to update the summary, summarized %d messages, count %d", summarized, con.SummarizedCount)
+	}
+}
+
+func TestRemovedLinesResetSummary(t *testing.T) {
+	good, _ := encodeMessages([]*schema.Message{schema.UserMessage("m1"), schema.AssistantMessage("m2", nil),
+		schema.UserMessage("m3"), schema.AssistantMessage("m4", nil)})
+	lines := strings.SplitAfter(string(good), "\n")
+	// the bad line may have been a message when the summary was made, the ones after it move up
+	content := lines[0] + lines[1] + "{not json\n" + lines[2] + lines[3]
+
+	cases := []struct {
+		name       string
+		summarized int
+		kept       bool
+	}{
+		{name: "before the bad line", summarized: 2, kept: true},
+		{name: "past the bad line", summarized: 3},
+	}
+	for _, c := range cases {
+		for _, compact := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s compact=%v", c.name, compact), func(t *testing.T) {
+				dir := t.TempDir()
+				meta, _ := json.Marshal(&conversationMeta{Title: "t", Summary: "S", SummarizedCount: c.summarized})
+				if err := os.WriteFile(filepath.Join(dir, "c1"+metaFileExt), meta, 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "c1.jsonl"), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+
+				m := mustMemory(t, SimpleMemoryConfig{Dir: dir})
+				if compact {
+					if res, err := m.Compact("c1"); err != nil || res.Quarantined != 1 {
+						t.Fatalf("unexpected compaction: %+v (%v)", res, err)
+					}
+				}
+				con, err := m.GetConversation("c1", false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(con.Messages) != 4 {
+					t.Fatalf("expected the 4 good messages, got %d", len(con.Messages))
+				}
+				if kept := con.Summary == "S" && con.SummarizedCount == c.summarized; kept != c.kept {
+					t.Fatalf("summary %q of %d messages, want kept=%v", con.Summary, con.SummarizedCount, c.kept)
+				}
+				if !c.kept && (con.Summary != "" || con.SummarizedCount != 0) {
+					t.Fatalf("expected the summary to be reset, got %q of %d messages", con.Summary, con.SummarizedCount)
+				}
+			})
+		}
+	}
+}
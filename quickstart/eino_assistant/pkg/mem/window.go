@@ -0,0 +1,192 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// TokenCounter estimates the number of tokens a message costs in the prompt.
+type TokenCounter func(msg *schema.Message) int
+
+// ApproxTokenCounter is the default TokenCounter. It counts about four bytes per token for
+// ASCII text, which overestimates English a little, and one token per rune for other text,
+// since tokenizers spend at least one token on most CJK characters, plus a small per-message
+// overhead for the role and the separators.
+func ApproxTokenCounter(msg *schema.Message) int {
+	var c textCounter
+	c.add(msg.Content)
+	c.add(msg.ReasoningContent)
+	for _, tc := range msg.ToolCalls {
+		c.add(tc.Function.Name)
+		c.add(tc.Function.Arguments)
+	}
+	return (c.asciiBytes+3)/4 + c.otherRunes + 4
+}
+
+// textCounter sums the ASCII bytes and the other runes of texts.
+type textCounter struct {
+	asciiBytes int
+	otherRunes int
+}
+
+func (c *textCounter) add(s string) {
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			c.asciiBytes++
+		} else {
+			c.otherRunes++
+		}
+	}
+}
+
+// Summarizer folds messages that left the window into the running summary of a conversation.
+// previous is the summary so far, empty for the first call.
+type Summarizer func(ctx context.Context, previous string, msgs []*schema.Message) (string, error)
+
+const summaryPrompt = `You maintain the running summary of a conversation between a user and an assistant.
+Merge the previous summary and the new messages into one concise summary.
+Keep facts, decisions, names, open questions and the user's preferences; drop greetings and repetitions.
+Answer with the summary only.`
+
+// NewModelSummarizer returns a Summarizer asking cm to update the summary.
+func NewModelSummarizer(cm model.BaseChatModel) Summarizer {
+	return func(ctx context.Context, previous string, msgs []*schema.Message) (string, error) {
+		var sb strings.Builder
+		if previous != "" {
+			sb.WriteString("Previous summary:\n")
+			sb.WriteString(previous)
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("New messages:\n")
+		for _, msg := range msgs {
+			sb.WriteString(string(msg.Role))
+			sb.WriteString(": ")
+			sb.WriteString(msg.Content)
+			for _, tc := range msg.ToolCalls {
+				fmt.Fprintf(&sb, " [call %s(%s)]", tc.Function.Name, tc.Function.Arguments)
+			}
+			sb.WriteString("\n")
+		}
+
+		out, err := cm.Generate(ctx, []*schema.Message{
+			schema.SystemMessage(summaryPrompt),
+			schema.UserMessage(sb.String()),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize conversation: %w", err)
+		}
+		return strings.TrimSpace(out.Content), nil
+	}
+}
+
+// summaryMessage carries the running summary in front of the window.
+func summaryMessage(summary string) *schema.Message {
+	return schema.SystemMessage("Summary of the earlier conversation:\n" + summary)
+}
+
+// windowPolicy picks the recent messages sent to the model with each query.
+type windowPolicy struct {
+	// maxMessages and maxTokens bound the window, zero means unbounded.
+	maxMessages int
+	maxTokens   int
+	counter     TokenCounter
+}
+
+// blocks splits msgs into the units the window keeps or drops as a whole: an assistant message
+// calling tools together with the results of its calls, or any other single message.
+// Tool results whose call is not in msgs cannot be sent to the model and are skipped.
+func blocks(msgs []*schema.Message) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(msgs); {
+		msg := msgs[i]
+		if msg.Role == schema.Tool {
+			i++
+			continue
+		}
+		end := i + 1
+		if msg.Role == schema.Assistant && len(msg.ToolCalls) > 0 {
+			calls := make(map[string]bool, len(msg.ToolCalls))
+			for _, tc := range msg.ToolCalls {
+				calls[tc.ID] = true
+			}
+			for end < len(msgs) && msgs[end].Role == schema.Tool && calls[msgs[end].ToolCallID] {
+				end++
+			}
+		}
+		out = append(out, [2]int{i, end})
+		i = end
+	}
+	return out
+}
+
+// start returns the index of the first message of the window over msgs, when reserved tokens
+// are already taken, e.g. by the summary. The newest block is always kept, even over budget,
+// since the model cannot answer without it.
+func (p *windowPolicy) start(msgs []*schema.Message, reserved int) int {
+	bs := blocks(msgs)
+	if len(bs) == 0 {
+		return len(msgs)
+	}
+	count, tokens := 0, reserved
+	first := bs[len(bs)-1][0]
+	for i := len(bs) - 1; i >= 0; i-- {
+		b := bs[i]
+		n := b[1] - b[0]
+		t := 0
+		for _, msg := range msgs[b[0]:b[1]] {
+			t += p.counter(msg)
+		}
+		if i < len(bs)-1 {
+			if p.maxMessages > 0 && count+n > p.maxMessages {
+				break
+			}
+			if p.maxTokens > 0 && tokens+t > p.maxTokens {
+				break
+			}
+		}
+		count += n
+		tokens += t
+		first = b[0]
+	}
+	return first
+}
+
+// window returns the messages of msgs from start on, skipping tool results left without their call.
+func window(msgs []*schema.Message, start int) []*schema.Message {
+	out := make([]*schema.Message, 0, len(msgs)-start)
+	for _, b := range blocks(msgs[start:]) {
+		out = append(out, msgs[start+b[0]:start+b[1]]...)
+	}
+	return out
+}
+
+// defaultTitle derives a title from the first user message.
+func defaultTitle(content string) string {
+	const maxTitleRunes = 40
+	title := strings.Join(strings.Fields(content), " ")
+	if utf8.RuneCountInString(title) <= maxTitleRunes {
+		return title
+	}
+	return string([]rune(title)[:maxTitleRunes]) + "..."
+}
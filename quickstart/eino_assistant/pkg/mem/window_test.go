@@ -0,0 +1,162 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mem
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func mustMemory(t *testing.T, cfg SimpleMemoryConfig) *SimpleMemory {
+	m, err := NewSimpleMemory(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func mustConversation(t *testing.T, m *SimpleMemory, id string) *Conversation {
+	con, err := m.GetConversation(id, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return con
+}
+
+func mustAppend(t *testing.T, con *Conversation, msgs ...*schema.Message) {
+	for _, msg := range msgs {
+		if err := con.Append(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func mustMessages(t *testing.T, con *Conversation) []*schema.Message {
+	msgs, err := con.GetMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msgs
+}
+
+func toolTurn(query, callID, result, answer string) []*schema.Message {
+	return []*schema.Message{
+		schema.UserMessage(query),
+		schema.AssistantMessage("", []schema.ToolCall{{ID: callID, Function: schema.FunctionCall{Name: "search", Arguments: "{}"}}}),
+		schema.ToolMessage(result, callID),
+		schema.AssistantMessage(answer, nil),
+	}
+}
+
+func TestWindowKeepsToolCallsWithResults(t *testing.T) {
+	m := mustMemory(t, SimpleMemoryConfig{
+		Dir:           t.TempDir(),
+		MaxWindowSize: 2,
+		TokenCounter:  func(*schema.Message) int { return 1 },
+	})
+	con := mustConversation(t, m, "c1")
+	mustAppend(t, con, toolTurn("q1", "call_1", "r1", "a1")...)
+	mustAppend(t, con, toolTurn("q2", "call_2", "r2", "a2")...)
+
+	// the last two messages would start with the tool result of call_2, without its call
+	got := mustMessages(t, con)
+	if len(got) != 1 || got[0].Content != "a2" {
+		t.Fatalf("expected only the final answer, got %d messages: %v", len(got), got)
+	}
+
+	m.policy.maxMessages = 4
+	got = mustMessages(t, con)
+	if len(got) != 4 || got[0].Content != "q2" || got[2].ToolCallID != "call_2" {
+		t.Fatalf("expected the whole second turn, got %v", got)
+	}
+}
+
+func TestWindowTokenBudgetAndSummary(t *testing.T) {
+	m := mustMemory(t, SimpleMemoryConfig{
+		Dir:             t.TempDir(),
+		MaxWindowTokens: 2,
+		TokenCounter:    func(msg *schema.Message) int { return len(msg.Content) },
+	})
+	con := mustConversation(t, m, "c1")
+	mustAppend(t, con, schema.UserMessage("aa"), schema.UserMessage("b"), schema.UserMessage("c"))
+	got := mustMessages(t, con)
+	if len(got) != 2 || got[0].Content != "b" {
+		t.Fatalf("expected the last two messages, got %v", got)
+	}
+
+	var folded []*schema.Message
+	m.SetSummarizer(func(_ context.Context, previous string, msgs []*schema.Message) (string, error) {
+		folded = msgs
+		return "S", nil
+	})
+	if err := con.UpdateSummary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(folded) != 1 || folded[0].Content != "aa" || con.SummarizedCount != 1 {
+		t.Fatalf("expected the first message to be summarized, got %v (count %d)", folded, con.SummarizedCount)
+	}
+
+	// the summary costs tokens of the budget too, only the newest message still fits
+	got = mustMessages(t, con)
+	if len(got) != 2 || got[0].Role != schema.System || got[1].Content != "c" {
+		t.Fatalf("expected the summary and the last message, got %v", got)
+	}
+
+	// a new instance reads the title and the summary from the metadata file
+	reopened, err := mustMemory(t, SimpleMemoryConfig{Dir: m.dir}).GetConversation("c1", false)
+	if err != nil || reopened.Title != "aa" || reopened.Summary != "S" {
+		t.Fatalf("metadata not persisted: %+v", reopened)
+	}
+}
+
+func TestApproxTokenCounter(t *testing.T) {
+	// 8 ASCII bytes are 2 tokens, each of the 4 CJK runes (12 bytes) is one token
+	if got := ApproxTokenCounter(schema.UserMessage("abcdefgh")); got != 2+4 {
+		t.Fatalf("ASCII text: got %d tokens, want 6", got)
+	}
+	if got := ApproxTokenCounter(schema.UserMessage("你好世界")); got != 4+4 {
+		t.Fatalf("CJK text: got %d tokens, want 8", got)
+	}
+}
+
+func TestRenameAndSearch(t *testing.T) {
+	m := mustMemory(t, SimpleMemoryConfig{Dir: t.TempDir()})
+	mustAppend(t, mustConversation(t, m, "c1"), schema.UserMessage("How do I build a Graph in Eino?"))
+	mustAppend(t, mustConversation(t, m, "c2"), schema.UserMessage("what is the weather"))
+
+	if err := m.RenameConversation("c2", "Weather"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.RenameConversation("missing", "x"); !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("expected ErrConversationNotFound, got %v", err)
+	}
+
+	res, err := m.SearchConversations("graph", 0)
+	if err != nil || len(res) != 1 || res[0].ID != "c1" || res[0].Snippet == "" {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+	res, err = m.SearchConversations("WEATHER", 0)
+	if err != nil || len(res) != 1 || res[0].Title != "Weather" {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+	if infos, err := m.ListConversationInfos(); err != nil || len(infos) != 2 || infos[0].ID != "c2" {
+		t.Fatalf("expected the renamed conversation first, got %+v", infos)
+	}
+}
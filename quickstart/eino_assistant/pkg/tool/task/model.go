@@ -0,0 +1,282 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eino-contrib/jsonschema"
+	"github.com/google/uuid"
+)
+
+// Timestamp is a point in time encoded in RFC 3339. It also decodes the shorter layouts that
+// people, models and datetime-local inputs write, in the local time zone. An empty string or
+// null decodes to the zero time.
+type Timestamp struct {
+	time.Time
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+func NewTimestamp(t time.Time) *Timestamp {
+	return &Timestamp{Time: t}
+}
+
+// ParseTimestamp parses s in one of the layouts Timestamp decodes.
+func ParseTimestamp(s string) (Timestamp, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return Timestamp{Time: t}, nil
+		}
+	}
+	return Timestamp{}, fmt.Errorf("invalid time %q, expected RFC 3339, YYYY-MM-DD HH:MM or YYYY-MM-DD", s)
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format(time.RFC3339))
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Timestamp{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("time must be a string: %w", err)
+	}
+	if s == "" {
+		*t = Timestamp{}
+		return nil
+	}
+	parsed, err := ParseTimestamp(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// JSONSchema describes Timestamp as a string to the model, instead of the struct it is.
+func (Timestamp) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:     "string",
+		Examples: []any{"2025-01-15T18:00:00+08:00", "2025-01-15 18:00", "2025-01-15"},
+	}
+}
+
+// isSet reports whether an optional time was given, "" and null decode to an unset time.
+func isSet(t *Timestamp) bool {
+	return t != nil && !t.IsZero()
+}
+
+var priorityRanks = map[Priority]int{
+	PriorityLow:    0,
+	PriorityMedium: 1,
+	PriorityHigh:   2,
+	PriorityUrgent: 3,
+}
+
+// Rank orders priorities from low to urgent, an unset priority counts as medium.
+func (p Priority) Rank() int {
+	if p == "" {
+		return priorityRanks[PriorityMedium]
+	}
+	return priorityRanks[p]
+}
+
+func (p Priority) valid() bool {
+	_, ok := priorityRanks[p]
+	return ok || p == ""
+}
+
+// Next returns when the occurrence after one due at due is due, or false once past Until.
+// Monthly and yearly occurrences fall on AnchorDay, or the day of due if not set, and on the
+// last day of shorter months instead of overflowing.
+func (r *Recurrence) Next(due time.Time) (time.Time, bool) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	day := due.Day()
+	if r.AnchorDay > 0 {
+		day = r.AnchorDay
+	}
+
+	var next time.Time
+	switch r.Frequency {
+	case FrequencyDaily:
+		next = due.AddDate(0, 0, interval)
+	case FrequencyWeekly:
+		next = due.AddDate(0, 0, 7*interval)
+	case FrequencyMonthly:
+		next = addMonths(due, interval, day)
+	case FrequencyYearly:
+		next = addMonths(due, 12*interval, day)
+	default:
+		return time.Time{}, false
+	}
+
+	if isSet(r.Until) && next.After(r.Until.Time) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// addMonths returns t moved by months, on day clamped to the length of the month.
+func addMonths(t time.Time, months, day int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func (r *Recurrence) validate() error {
+	switch r.Frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+	default:
+		return fmt.Errorf("invalid recurrence frequency %q, expected daily, weekly, monthly or yearly", r.Frequency)
+	}
+	if r.Interval < 0 {
+		return fmt.Errorf("recurrence interval must not be negative")
+	}
+	if r.AnchorDay < 0 || r.AnchorDay > 31 {
+		return fmt.Errorf("recurrence anchor day must be between 1 and 31")
+	}
+	return nil
+}
+
+// normalizeTags trims, lowers and deduplicates tags.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func normalizeSubtasks(subtasks []*Subtask) ([]*Subtask, error) {
+	if subtasks == nil {
+		return nil, nil
+	}
+	out := make([]*Subtask, 0, len(subtasks))
+	for _, st := range subtasks {
+		if st == nil {
+			continue
+		}
+		if strings.TrimSpace(st.Title) == "" {
+			return nil, fmt.Errorf("subtask title is required")
+		}
+		cp := *st
+		if cp.ID == "" {
+			cp.ID = uuid.New().String()
+		}
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+// validate checks and normalizes the fields of a task given by the user, for an add or an update.
+func (t *Task) validate() error {
+	if !t.Priority.valid() {
+		return fmt.Errorf("invalid priority %q, expected low, medium, high or urgent", t.Priority)
+	}
+	if t.Deadline != nil && t.Deadline.IsZero() {
+		t.Deadline = nil
+	}
+	if t.Recurrence != nil {
+		if err := t.Recurrence.validate(); err != nil {
+			return err
+		}
+	}
+	t.Tags = normalizeTags(t.Tags)
+	subtasks, err := normalizeSubtasks(t.Subtasks)
+	if err != nil {
+		return err
+	}
+	t.Subtasks = subtasks
+	return nil
+}
+
+// nextOccurrence returns the task to add when the recurring task t is completed, if any.
+func (t *Task) nextOccurrence(now time.Time) *Task {
+	if t.Recurrence == nil || !isSet(t.Deadline) {
+		return nil
+	}
+	// keep the day of the first deadline, a month clamped to a shorter one must not shift the next ones
+	recurrence := t.Recurrence
+	if recurrence.AnchorDay == 0 && (recurrence.Frequency == FrequencyMonthly || recurrence.Frequency == FrequencyYearly) {
+		anchored := *recurrence
+		anchored.AnchorDay = t.Deadline.Day()
+		recurrence = &anchored
+	}
+
+	due, ok := recurrence.Next(t.Deadline.Time)
+	if !ok {
+		return nil
+	}
+	// an occurrence completed late must not spawn one already overdue
+	for !due.After(now) {
+		if due, ok = recurrence.Next(due); !ok {
+			return nil
+		}
+	}
+
+	next := &Task{
+		ID:         uuid.New().String(),
+		Title:      t.Title,
+		Content:    t.Content,
+		Priority:   t.Priority,
+		Tags:       t.Tags,
+		Deadline:   NewTimestamp(due),
+		Recurrence: recurrence,
+		CreatedAt:  Timestamp{Time: now},
+	}
+	for _, st := range t.Subtasks {
+		next.Subtasks = append(next.Subtasks, &Subtask{ID: uuid.New().String(), Title: st.Title})
+	}
+	return next
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReminderConfig configures a ReminderScheduler.
+type ReminderConfig struct {
+	// Storage is scanned for uncompleted tasks with a deadline. Required.
+	Storage TaskStore
+	// Horizon is how far ahead a task counts as upcoming. Default 24h.
+	Horizon time.Duration
+	// Interval is the time between two scans. Default 1 minute.
+	Interval time.Duration
+	// MaxTasks is the number of tasks of each kind written in the reminder text. Default 5.
+	MaxTasks int
+	// OnDue is called by the scans of Start when a task becomes upcoming, and again when
+	// it becomes overdue. Optional.
+	OnDue func(ctx context.Context, task *Task, overdue bool)
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Reminder lists the tasks the user should be reminded of at a point in time.
+type Reminder struct {
+	At       time.Time
+	Horizon  time.Duration
+	MaxTasks int
+	// Overdue tasks are the most overdue first, upcoming tasks the soonest first.
+	Overdue  []*Task
+	Upcoming []*Task
+}
+
+// ReminderScheduler scans the tasks for overdue and upcoming ones. Its Reminder is meant to
+// be given to the agent as context on each turn, and Start notifies tasks as they fall due.
+type ReminderScheduler struct {
+	config ReminderConfig
+
+	mu     sync.Mutex
+	last   *Reminder
+	notify map[string]bool // task ID => notified as overdue
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewReminderScheduler(config *ReminderConfig) (*ReminderScheduler, error) {
+	if config == nil || config.Storage == nil {
+		return nil, fmt.Errorf("storage cannot be empty")
+	}
+	cfg := *config
+	if cfg.Horizon <= 0 {
+		cfg.Horizon = 24 * time.Hour
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.MaxTasks <= 0 {
+		cfg.MaxTasks = 5
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &ReminderScheduler{config: cfg, notify: make(map[string]bool)}, nil
+}
+
+var (
+	defaultReminders     *ReminderScheduler
+	defaultRemindersOnce sync.Once
+)
+
+// GetDefaultReminderScheduler returns the scheduler of the default storage, which logs
+// the tasks falling due once started. Like GetDefaultStorage, it panics when it cannot be created.
+func GetDefaultReminderScheduler() *ReminderScheduler {
+	defaultRemindersOnce.Do(func() {
+		s, err := NewReminderScheduler(&ReminderConfig{
+			Storage: GetDefaultStorage(),
+			OnDue: func(ctx context.Context, task *Task, overdue bool) {
+				state := "due soon"
+				if overdue {
+					state = "overdue"
+				}
+				log.Printf("[task] %s: %s (deadline %s)", state, task.Title, task.Deadline.Format(time.RFC3339))
+			},
+		})
+		if err != nil {
+			panic(fmt.Sprintf("create reminder scheduler failed: %v", err))
+		}
+		defaultReminders = s
+	})
+	return defaultReminders
+}
+
+// Scan lists the overdue and upcoming tasks now.
+func (s *ReminderScheduler) Scan() (*Reminder, error) {
+	now := s.config.Now()
+	isDone := false
+	tasks, err := s.config.Storage.List(&ListParams{
+		IsDone:    &isDone,
+		DueBefore: NewTimestamp(now.Add(s.config.Horizon)),
+		SortBy:    SortByDeadline,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reminder{At: now, Horizon: s.config.Horizon, MaxTasks: s.config.MaxTasks}
+	for _, t := range tasks {
+		if t.Deadline.Before(now) {
+			r.Overdue = append(r.Overdue, t)
+		} else {
+			r.Upcoming = append(r.Upcoming, t)
+		}
+	}
+
+	s.mu.Lock()
+	s.last = r
+	s.mu.Unlock()
+	return r, nil
+}
+
+// Current returns the last Reminder if it is more recent than the scan interval, or scans again.
+func (s *ReminderScheduler) Current() (*Reminder, error) {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+	if last != nil && s.config.Now().Sub(last.At) < s.config.Interval {
+		return last, nil
+	}
+	return s.Scan()
+}
+
+// Start scans the tasks every interval until ctx is done or Stop is called, calling OnDue
+// for the tasks that fell due since the previous scan. Starting a started scheduler does nothing.
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+		for {
+			s.tick(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}(s.done)
+}
+
+// Stop stops the scans of Start and waits for the current one to end.
+func (s *ReminderScheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (s *ReminderScheduler) tick(ctx context.Context) {
+	r, err := s.Scan()
+	if err != nil {
+		log.Printf("[task] reminder scan failed: %v", err)
+		return
+	}
+	if s.config.OnDue == nil {
+		return
+	}
+
+	var due []*Task
+	var overdue []bool
+	s.mu.Lock()
+	seen := make(map[string]bool, len(r.Overdue)+len(r.Upcoming))
+	for _, t := range r.Overdue {
+		seen[t.ID] = true
+		if notified, ok := s.notify[t.ID]; !ok || !notified {
+			s.notify[t.ID] = true
+			due, overdue = append(due, t), append(overdue, true)
+		}
+	}
+	for _, t := range r.Upcoming {
+		seen[t.ID] = true
+		if _, ok := s.notify[t.ID]; !ok {
+			s.notify[t.ID] = false
+			due, overdue = append(due, t), append(overdue, false)
+		}
+	}
+	// forget completed, deleted or postponed tasks, so that they are notified again if they fall due again
+	for id := range s.notify {
+		if !seen[id] {
+			delete(s.notify, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for i, t := range due {
+		s.config.OnDue(ctx, t, overdue[i])
+	}
+}
+
+// String renders the reminder for the prompt of the agent, "none" if there is nothing to remind.
+func (r *Reminder) String() string {
+	if r == nil || len(r.Overdue)+len(r.Upcoming) == 0 {
+		return "none"
+	}
+
+	var sb strings.Builder
+	write := func(header string, tasks []*Task) {
+		if len(tasks) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "%s (%d):\n", header, len(tasks))
+		for i, t := range tasks {
+			if i == r.MaxTasks {
+				fmt.Fprintf(&sb, "  - ... and %d more, list them with the task_manager tool\n", len(tasks)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "  - [%s] %s, due %s (%s), id: %s\n", priorityName(t.Priority), t.Title,
+				t.Deadline.Format("2006-01-02 15:04"), relative(t.Deadline.Sub(r.At)), t.ID)
+		}
+	}
+	write("Overdue tasks", r.Overdue)
+	write(fmt.Sprintf("Tasks due within %s", r.Horizon), r.Upcoming)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func priorityName(p Priority) Priority {
+	if p == "" {
+		return PriorityMedium
+	}
+	return p
+}
+
+func relative(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < 0 {
+		return (-d).String() + " ago"
+	}
+	return "in " + d.String()
+}
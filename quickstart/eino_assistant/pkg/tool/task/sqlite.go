@@ -0,0 +1,402 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Every task is a JSON document in data, the other columns copy the fields queries filter on.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tasks (
+	id         TEXT PRIMARY KEY,
+	data       TEXT NOT NULL,
+	completed  INTEGER NOT NULL,
+	deleted    INTEGER NOT NULL,
+	deadline   INTEGER,
+	created_at INTEGER NOT NULL,
+	deleted_at INTEGER
+);
+CREATE INDEX IF NOT EXISTS tasks_deadline ON tasks (deleted, completed, deadline);
+CREATE INDEX IF NOT EXISTS tasks_created_at ON tasks (deleted, created_at);
+`
+
+// SQLiteStore is the TaskStore kept in an embedded SQLite database. Every change runs in
+// a database transaction, and so does the function given to Tx.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %v", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve database path: %v", err)
+	}
+	// immediate transactions take the write lock when they begin: a deferred one reading first
+	// fails with SQLITE_BUSY, without waiting on the busy timeout, when another process wrote meanwhile
+	params := url.Values{}
+	params.Set("_busy_timeout", "5000")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_txlock", "immediate")
+	// the path is escaped, so that a '?', '#' or '%' in it is not read as part of the options
+	dsnPath := filepath.ToSlash(abs)
+	if !strings.HasPrefix(dsnPath, "/") {
+		dsnPath = "/" + dsnPath // a Windows drive, file:///C:/...
+	}
+	dsn := &url.URL{Scheme: "file", Path: dsnPath, RawQuery: params.Encode()}
+	db, err := sql.Open("sqlite3", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	// a single connection serializes the writers of this process, others wait on the busy timeout
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables: %v", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Add(task *Task) error {
+	return s.write(func(o sqliteOps) error { return o.add(task) })
+}
+
+func (s *SQLiteStore) Get(id string) (*Task, error) {
+	return sqliteOps{s.db}.get(id)
+}
+
+func (s *SQLiteStore) List(params *ListParams) ([]*Task, error) {
+	return sqliteOps{s.db}.list(params)
+}
+
+func (s *SQLiteStore) Update(task *Task) (updated *Task, err error) {
+	err = s.write(func(o sqliteOps) error {
+		updated, err = o.update(task)
+		return err
+	})
+	return updated, err
+}
+
+func (s *SQLiteStore) Delete(id string) error {
+	return s.write(func(o sqliteOps) error { return o.delete(id) })
+}
+
+// Tx runs fn in a database transaction. Other calls of this process wait until fn returns.
+func (s *SQLiteStore) Tx(fn func(tx TaskStore) error) error {
+	return s.write(func(o sqliteOps) error {
+		tx := &sqliteTx{o: o}
+		defer func() { tx.done = true }()
+		return fn(tx)
+	})
+}
+
+func (s *SQLiteStore) Purge(deletedBefore time.Time) (n int, err error) {
+	err = s.write(func(o sqliteOps) error {
+		n, err = o.purge(deletedBefore)
+		return err
+	})
+	return n, err
+}
+
+func (s *SQLiteStore) All() ([]*Task, error) {
+	return sqliteOps{s.db}.all()
+}
+
+func (s *SQLiteStore) Put(tasks ...*Task) error {
+	return s.write(func(o sqliteOps) error { return o.put(tasks) })
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) write(fn func(o sqliteOps) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	if err = fn(sqliteOps{tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// sqlRunner is implemented by both *sql.DB and *sql.Tx.
+type sqlRunner interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// sqliteOps implements the operations of SQLiteStore, in a transaction for the writes.
+type sqliteOps struct {
+	q sqlRunner
+}
+
+func (o sqliteOps) load(id string) (*Task, bool, error) {
+	var data []byte
+	err := o.q.QueryRow(`SELECT data FROM tasks WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	task, err := decodeTask(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal task %s: %v", id, err)
+	}
+	return task, true, nil
+}
+
+func (o sqliteOps) save(tasks []*Task) error {
+	for _, t := range tasks {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Errorf("failed to marshal task: %v", err)
+		}
+		_, err = o.q.Exec(`INSERT OR REPLACE INTO tasks (id, data, completed, deleted, deadline, created_at, deleted_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			t.ID, string(data), t.Completed, t.IsDeleted, unixNano(t.Deadline), t.CreatedAt.UnixNano(), unixNano(t.DeletedAt))
+		if err != nil {
+			return fmt.Errorf("failed to save task %s: %v", t.ID, err)
+		}
+	}
+	return nil
+}
+
+func unixNano(t *Timestamp) any {
+	if !isSet(t) {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+func (o sqliteOps) add(task *Task) error {
+	c := newChangeSet(o.load)
+	if err := c.add(task, time.Now()); err != nil {
+		return err
+	}
+	return o.save(c.tasks())
+}
+
+func (o sqliteOps) get(id string) (*Task, error) {
+	task, ok, err := o.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || task.IsDeleted {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	return task, nil
+}
+
+func (o sqliteOps) update(task *Task) (*Task, error) {
+	c := newChangeSet(o.load)
+	updated, err := c.update(task, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err = o.save(c.tasks()); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (o sqliteOps) delete(id string) error {
+	c := newChangeSet(o.load)
+	if err := c.delete(id, time.Now()); err != nil {
+		return err
+	}
+	return o.save(c.tasks())
+}
+
+// list narrows the tasks with the indexed columns, and leaves the rest to filterTasks.
+func (o sqliteOps) list(params *ListParams) ([]*Task, error) {
+	if params == nil {
+		params = &ListParams{}
+	}
+	where := []string{"deleted = 0"}
+	var args []any
+	if params.IsDone != nil {
+		where = append(where, "completed = ?")
+		args = append(args, *params.IsDone)
+	}
+	for _, r := range []struct {
+		cond string
+		t    *Timestamp
+	}{
+		{"deadline >= ?", params.DueAfter},
+		{"deadline <= ?", params.DueBefore},
+		{"created_at >= ?", params.CreatedAfter},
+		{"created_at <= ?", params.CreatedBefore},
+	} {
+		if isSet(r.t) {
+			where = append(where, r.cond)
+			args = append(args, r.t.UnixNano())
+		}
+	}
+
+	tasks, err := o.query(`SELECT data FROM tasks WHERE `+strings.Join(where, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	return filterTasks(tasks, params)
+}
+
+func (o sqliteOps) purge(deletedBefore time.Time) (int, error) {
+	res, err := o.q.Exec(`DELETE FROM tasks WHERE deleted = 1 AND (deleted_at IS NULL OR deleted_at < ?)`, deletedBefore.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge tasks: %v", err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (o sqliteOps) all() ([]*Task, error) {
+	return o.query(`SELECT data FROM tasks ORDER BY created_at, id`)
+}
+
+func (o sqliteOps) put(tasks []*Task) error {
+	for _, t := range tasks {
+		if t.ID == "" {
+			return fmt.Errorf("task id is required")
+		}
+	}
+	return o.save(tasks)
+}
+
+func (o sqliteOps) query(query string, args ...any) ([]*Task, error) {
+	rows, err := o.q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks: %v", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		var data []byte
+		if err = rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		task, err := decodeTask(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal task: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+// sqliteTx is the store given to the function of SQLiteStore.Tx.
+type sqliteTx struct {
+	o    sqliteOps
+	done bool
+}
+
+func (tx *sqliteTx) check() error {
+	if tx.done {
+		return fmt.Errorf("transaction is over")
+	}
+	return nil
+}
+
+func (tx *sqliteTx) Add(task *Task) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.o.add(task)
+}
+
+func (tx *sqliteTx) Get(id string) (*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	return tx.o.get(id)
+}
+
+func (tx *sqliteTx) List(params *ListParams) ([]*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	return tx.o.list(params)
+}
+
+func (tx *sqliteTx) Update(task *Task) (*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	return tx.o.update(task)
+}
+
+func (tx *sqliteTx) Delete(id string) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.o.delete(id)
+}
+
+// Tx runs fn as part of the current transaction.
+func (tx *sqliteTx) Tx(fn func(tx TaskStore) error) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return fn(tx)
+}
+
+func (tx *sqliteTx) Purge(deletedBefore time.Time) (int, error) {
+	if err := tx.check(); err != nil {
+		return 0, err
+	}
+	return tx.o.purge(deletedBefore)
+}
+
+func (tx *sqliteTx) All() ([]*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	return tx.o.all()
+}
+
+func (tx *sqliteTx) Put(tasks ...*Task) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.o.put(tasks)
+}
+
+// Close does nothing, the transaction ends with its function.
+func (tx *sqliteTx) Close() error {
+	return nil
+}
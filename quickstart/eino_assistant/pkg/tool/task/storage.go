@@ -18,39 +18,28 @@ package task
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
-var defaultStorage *Storage
+// compactMinLines is the number of stale lines tasks.jsonl may hold before it is rewritten.
+const compactMinLines = 100
 
+// Storage is the JSONL TaskStore. Tasks are cached in memory, and a change of one task
+// appends it to tasks.jsonl, where the last line of a task wins. The file is rewritten once
+// it holds more stale lines than live ones, and for changes of several tasks.
 type Storage struct {
 	filePath string
 	mu       sync.RWMutex
 	cache    map[string]*Task
-	dirty    bool
-}
-
-func GetDefaultStorage() *Storage {
-	if defaultStorage == nil {
-		InitDefaultStorage("./data/task")
-	}
-	return defaultStorage
-}
-
-func InitDefaultStorage(dataDir string) error {
-	s, err := NewStorage(dataDir)
-	if err != nil {
-		return err
-	}
-	defaultStorage = s
-	return nil
+	lines    int // lines of the file, stale ones included
 }
 
 func NewStorage(dataDir string) (*Storage, error) {
@@ -76,205 +65,512 @@ func (s *Storage) loadFromDisk() error {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var task Task
-		if err := json.Unmarshal(scanner.Bytes(), &task); err != nil {
-			return fmt.Errorf("failed to unmarshal task: %v", err)
+	torn := false
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			task, derr := decodeTask(line)
+			switch {
+			case derr == nil:
+				s.cache[task.ID] = task
+				s.lines++
+			case err == io.EOF:
+				// a last line without its newline was cut by a crash while appending
+				torn = true
+			default:
+				return fmt.Errorf("failed to unmarshal task: %v", derr)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
 		}
-		s.cache[task.ID] = &task
 	}
 
-	return scanner.Err()
+	// appending after a cut line would glue the next task to it
+	if torn || s.lines > 2*len(s.cache)+compactMinLines {
+		return s.rewrite(s.cache)
+	}
+	return nil
+}
+
+func (s *Storage) changes() *changeSet {
+	return newChangeSet(func(id string) (*Task, bool, error) {
+		t, ok := s.cache[id]
+		return t, ok, nil
+	})
 }
 
 func (s *Storage) Add(task *Task) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	task.CreatedAt = time.Now().Format(time.RFC3339)
-	task.IsDeleted = false
-	s.cache[task.ID] = task
-
-	// 直接追加到文件末尾
-	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open file: %v", err)
-	}
-	defer file.Close()
-
-	data, err := json.Marshal(task)
-	if err != nil {
-		return fmt.Errorf("failed to marshal task: %v", err)
+	c := s.changes()
+	if err := c.add(task, time.Now()); err != nil {
+		return err
 	}
+	return s.commit(c)
+}
 
-	if _, err := file.Write(append(data, '\n')); err != nil {
-		return fmt.Errorf("failed to write task: %v", err)
-	}
+// Get returns a copy of the task.
+func (s *Storage) Get(id string) (*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	if err := file.Sync(); err != nil {
-		return fmt.Errorf("failed to sync file: %v", err)
+	task, exists := s.cache[id]
+	if !exists || task.IsDeleted {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
 	}
-
-	return nil
+	cp := *task
+	return &cp, nil
 }
 
+// List returns copies of the tasks matching params, uncompleted ones first.
 func (s *Storage) List(params *ListParams) ([]*Task, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var activeTasks, completedTasks []*Task
+	all := make([]*Task, 0, len(s.cache))
 	for _, task := range s.cache {
-		if task.IsDeleted {
-			continue
-		}
-
-		if params.Query != "" && !contains(task.Title, params.Query) && !contains(task.Content, params.Query) {
-			continue
-		}
+		all = append(all, task)
+	}
+	return filterTasks(all, params)
+}
 
-		if params.IsDone != nil {
-			if task.Completed != *params.IsDone {
-				continue
-			}
-		}
+func (s *Storage) Update(task *Task) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		if task.Completed {
-			completedTasks = append(completedTasks, task)
-		} else {
-			activeTasks = append(activeTasks, task)
-		}
+	c := s.changes()
+	updated, err := c.update(task, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err = s.commit(c); err != nil {
+		return nil, err
 	}
+	return updated, nil
+}
 
-	// 按创建时间排序（最新的在前面）
-	sort.Slice(activeTasks, func(i, j int) bool {
-		return activeTasks[i].CreatedAt > activeTasks[j].CreatedAt
-	})
-	sort.Slice(completedTasks, func(i, j int) bool {
-		return completedTasks[i].CreatedAt > completedTasks[j].CreatedAt
-	})
+func (s *Storage) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// 合并列表：未完成的在前，已完成的在后
-	tasks := append(activeTasks, completedTasks...)
+	c := s.changes()
+	if err := c.delete(id, time.Now()); err != nil {
+		return err
+	}
+	return s.commit(c)
+}
+
+// Tx runs fn on a view of the tasks, its changes are committed together when it returns nil.
+// Other calls wait until fn returns.
+func (s *Storage) Tx(fn func(tx TaskStore) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if params.Limit != nil && len(tasks) > *params.Limit {
-		tasks = tasks[:*params.Limit]
+	tx := &jsonlTx{s: s, c: s.changes()}
+	err := fn(tx)
+	tx.done = true
+	if err != nil {
+		return err
 	}
+	return s.commit(tx.c)
+}
 
-	return tasks, nil
+func (s *Storage) Purge(deletedBefore time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.changes()
+	n := purge(c, s.cache, deletedBefore)
+	return n, s.commit(c)
+}
+
+func (s *Storage) All() ([]*Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make([]*Task, 0, len(s.cache))
+	for _, task := range s.cache {
+		cp := *task
+		all = append(all, &cp)
+	}
+	sortByCreation(all)
+	return all, nil
 }
 
-func (s *Storage) Update(task *Task) error {
+func (s *Storage) Put(tasks ...*Task) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	existing, exists := s.cache[task.ID]
-	if !exists || existing.IsDeleted {
-		return fmt.Errorf("task not found: %s", task.ID)
+	c := s.changes()
+	if err := putAll(c, tasks); err != nil {
+		return err
 	}
+	return s.commit(c)
+}
 
-	// 只更新非空字段
-	updated := *existing // 创建副本
-	if task.Title != "" {
-		updated.Title = task.Title
+func (s *Storage) Close() error {
+	return nil
+}
+
+func putAll(c *changeSet, tasks []*Task) error {
+	for _, t := range tasks {
+		if t.ID == "" {
+			return fmt.Errorf("task id is required")
+		}
+		cp := *t
+		c.put(&cp)
 	}
-	if task.Content != "" {
-		updated.Content = task.Content
+	return nil
+}
+
+func purge(c *changeSet, tasks map[string]*Task, deletedBefore time.Time) int {
+	n := 0
+	for id, t := range tasks {
+		if t.purgeable(deletedBefore) {
+			c.remove(id)
+			n++
+		}
 	}
-	if task.Deadline != "" {
-		updated.Deadline = task.Deadline
+	return n
+}
+
+// merge returns the cache with the changes of c applied.
+func (s *Storage) merge(c *changeSet) map[string]*Task {
+	merged := make(map[string]*Task, len(s.cache)+len(c.changed))
+	for id, t := range s.cache {
+		merged[id] = t
 	}
-	// Completed 字段需要特殊处理，因为它是布尔值
-	if task.Completed != existing.Completed {
-		updated.Completed = task.Completed
+	for id, t := range c.changed {
+		if t == nil {
+			delete(merged, id)
+		} else {
+			merged[id] = t
+		}
 	}
-
-	s.cache[task.ID] = &updated
-	s.dirty = true
-
-	return s.syncToDisk()
+	return merged
 }
 
-func (s *Storage) Delete(id string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// commit saves the changes of c, then caches them. A single changed task is appended to the
+// file, a line being all or nothing as a cut one is dropped when loading. Several changes,
+// and purges, rewrite the file, so that a crash cannot keep only some of them.
+func (s *Storage) commit(c *changeSet) error {
+	switch {
+	case len(c.order) == 0:
+		return nil
+	case len(c.order) > 1 || c.changed[c.order[0]] == nil:
+		return s.rewrite(s.merge(c))
+	}
 
-	task, exists := s.cache[id]
-	if !exists || task.IsDeleted {
-		return fmt.Errorf("task not found: %s", id)
+	task := c.changed[c.order[0]]
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %v", err)
 	}
 
-	// 标记删除
-	task.IsDeleted = true
-	s.dirty = true
+	// 直接追加到文件末尾
+	file, err := os.OpenFile(s.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write task: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %v", err)
+	}
 
-	return s.syncToDisk()
+	s.cache[task.ID] = task
+	s.lines++
+	if s.lines > 2*len(s.cache)+compactMinLines {
+		return s.rewrite(s.cache)
+	}
+	return nil
 }
 
-func (s *Storage) syncToDisk() error {
-	if !s.dirty {
-		return nil
+// rewrite replaces the file with one line per task and makes tasks the cache.
+func (s *Storage) rewrite(tasks map[string]*Task) error {
+	all := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		all = append(all, task)
 	}
+	sortByCreation(all)
 
 	// 创建临时文件
-	tmpFile := s.filePath + ".tmp"
-	file, err := os.Create(tmpFile)
+	file, err := os.CreateTemp(filepath.Dir(s.filePath), ".tasks-*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %v", err)
 	}
-	defer file.Close()
+	tmpFile := file.Name()
+	defer os.Remove(tmpFile) // no-op once renamed
 
-	// 写入数据到临时文件
-	for _, task := range s.cache {
+	writer := bufio.NewWriter(file)
+	for _, task := range all {
 		data, err := json.Marshal(task)
 		if err != nil {
-			os.Remove(tmpFile) // 清理临时文件
+			file.Close()
 			return fmt.Errorf("failed to marshal task: %v", err)
 		}
-
-		if _, err := file.Write(append(data, '\n')); err != nil {
-			os.Remove(tmpFile) // 清理临时文件
-			return fmt.Errorf("failed to write task: %v", err)
-		}
+		writer.Write(data)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write tasks: %v", err)
 	}
 
 	// 确保所有数据都写入磁盘
 	if err := file.Sync(); err != nil {
-		os.Remove(tmpFile)
+		file.Close()
 		return fmt.Errorf("failed to sync file: %v", err)
 	}
-
-	// 关闭文件
 	if err := file.Close(); err != nil {
-		os.Remove(tmpFile)
 		return fmt.Errorf("failed to close file: %v", err)
 	}
 
-	// 备份现有文件（如果存在）
-	if _, err := os.Stat(s.filePath); err == nil {
-		backupFile := s.filePath + ".bak"
-		if err := os.Rename(s.filePath, backupFile); err != nil {
-			os.Remove(tmpFile)
-			return fmt.Errorf("failed to backup file: %v", err)
-		}
-	}
-
 	// 将临时文件重命名为正式文件
 	if err := os.Rename(tmpFile, s.filePath); err != nil {
-		// 如果重命名失败，尝试恢复备份
-		if backupErr := os.Rename(s.filePath+".bak", s.filePath); backupErr != nil {
-			return fmt.Errorf("failed to rename temp file and restore backup: %v, backup error: %v", err, backupErr)
-		}
 		return fmt.Errorf("failed to rename temp file: %v", err)
 	}
 
-	// 删除备份文件
-	os.Remove(s.filePath + ".bak")
+	s.cache = tasks
+	s.lines = len(all)
+	return nil
+}
+
+// jsonlTx is the store given to the function of Storage.Tx, the lock of the storage is held
+// meanwhile. Its changes stay in c until the commit.
+type jsonlTx struct {
+	s    *Storage
+	c    *changeSet
+	done bool
+}
+
+func (tx *jsonlTx) check() error {
+	if tx.done {
+		return fmt.Errorf("transaction is over")
+	}
+	return nil
+}
+
+func (tx *jsonlTx) Add(task *Task) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.c.add(task, time.Now())
+}
+
+func (tx *jsonlTx) Get(id string) (*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	task, err := tx.c.getLive(id)
+	if err != nil {
+		return nil, err
+	}
+	cp := *task
+	return &cp, nil
+}
+
+func (tx *jsonlTx) List(params *ListParams) ([]*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	merged := tx.s.merge(tx.c)
+	all := make([]*Task, 0, len(merged))
+	for _, task := range merged {
+		all = append(all, task)
+	}
+	return filterTasks(all, params)
+}
+
+func (tx *jsonlTx) Update(task *Task) (*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	return tx.c.update(task, time.Now())
+}
+
+func (tx *jsonlTx) Delete(id string) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return tx.c.delete(id, time.Now())
+}
+
+// Tx runs fn as part of the current transaction.
+func (tx *jsonlTx) Tx(fn func(tx TaskStore) error) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return fn(tx)
+}
+
+func (tx *jsonlTx) Purge(deletedBefore time.Time) (int, error) {
+	if err := tx.check(); err != nil {
+		return 0, err
+	}
+	return purge(tx.c, tx.s.merge(tx.c), deletedBefore), nil
+}
+
+func (tx *jsonlTx) All() ([]*Task, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	merged := tx.s.merge(tx.c)
+	all := make([]*Task, 0, len(merged))
+	for _, task := range merged {
+		cp := *task
+		all = append(all, &cp)
+	}
+	sortByCreation(all)
+	return all, nil
+}
 
-	s.dirty = false
+func (tx *jsonlTx) Put(tasks ...*Task) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	return putAll(tx.c, tasks)
+}
+
+// Close does nothing, the transaction ends with its function.
+func (tx *jsonlTx) Close() error {
 	return nil
 }
 
+// taskLess returns the order asked by params. Ties are broken by creation time, newest first,
+// and tasks without a deadline come last when sorting by deadline, whatever the order.
+func taskLess(params *ListParams) (func(a, b *Task) bool, error) {
+	var cmp func(a, b *Task) int
+	desc := false
+	switch params.SortBy {
+	case "", SortByCreatedAt:
+		cmp = func(a, b *Task) int { return a.CreatedAt.Compare(b.CreatedAt.Time) }
+		desc = true
+	case SortByUpdatedAt:
+		cmp = func(a, b *Task) int { return a.lastUpdate().Compare(b.lastUpdate()) }
+		desc = true
+	case SortByDeadline:
+		cmp = func(a, b *Task) int { return a.Deadline.Compare(b.Deadline.Time) }
+	case SortByPriority:
+		cmp = func(a, b *Task) int { return a.Priority.Rank() - b.Priority.Rank() }
+		desc = true
+	case SortByTitle:
+		cmp = func(a, b *Task) int { return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)) }
+	default:
+		return nil, fmt.Errorf("invalid sort_by %q", params.SortBy)
+	}
+	switch params.Order {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return nil, fmt.Errorf("invalid order %q, expected asc or desc", params.Order)
+	}
+
+	byDeadline := params.SortBy == SortByDeadline
+	return func(a, b *Task) bool {
+		if byDeadline && isSet(a.Deadline) != isSet(b.Deadline) {
+			return isSet(a.Deadline)
+		}
+		c := 0
+		if !byDeadline || isSet(a.Deadline) {
+			c = cmp(a, b)
+		}
+		if desc {
+			c = -c
+		}
+		if c == 0 {
+			return a.CreatedAt.After(b.CreatedAt.Time)
+		}
+		return c < 0
+	}, nil
+}
+
+func (t *Task) lastUpdate() time.Time {
+	if t.UpdatedAt != nil {
+		return t.UpdatedAt.Time
+	}
+	return t.CreatedAt.Time
+}
+
+func (t *Task) matches(query string) bool {
+	if contains(t.Title, query) || contains(t.Content, query) {
+		return true
+	}
+	for _, tag := range t.Tags {
+		if contains(tag, query) {
+			return true
+		}
+	}
+	for _, st := range t.Subtasks {
+		if contains(st.Title, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags reports whether t has all the tags, which are normalized.
+func (t *Task) hasTags(tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range t.Tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeTask decodes a stored task. Tasks stored before deadlines were typed may hold
+// a deadline in free text, which is moved to the content when it does not parse.
+func decodeTask(data []byte) (*Task, error) {
+	task := &Task{}
+	err := json.Unmarshal(data, task)
+	if err != nil {
+		var legacy struct {
+			Deadline string `json:"deadline"`
+		}
+		if json.Unmarshal(data, &legacy) != nil || legacy.Deadline == "" {
+			return nil, err
+		}
+		var fields map[string]json.RawMessage
+		if uerr := json.Unmarshal(data, &fields); uerr != nil {
+			return nil, err
+		}
+		delete(fields, "deadline")
+		if data, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+		task = &Task{}
+		if err = json.Unmarshal(data, task); err != nil {
+			return nil, err
+		}
+		task.Content = strings.TrimSpace(task.Content + "\n(deadline: " + legacy.Deadline + ")")
+	}
+
+	if task.Deadline != nil && task.Deadline.IsZero() {
+		task.Deadline = nil
+	}
+	return task, nil
+}
+
 func contains(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
 }
@@ -0,0 +1,441 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTaskNotFound is returned for a task that does not exist or is deleted.
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskStore persists tasks. Implementations must be safe for concurrent use.
+type TaskStore interface {
+	// Add stores a new task, setting its timestamps.
+	Add(task *Task) error
+	// Get returns a copy of the task.
+	Get(id string) (*Task, error)
+	// List returns copies of the tasks matching params, uncompleted ones first.
+	List(params *ListParams) ([]*Task, error)
+	// Update sets the non-empty fields of task on the stored task and returns the result.
+	// Completing a recurring task adds its next occurrence, see Task.NextID.
+	Update(task *Task) (*Task, error)
+	// Delete soft-deletes the task, it is kept until purged.
+	Delete(id string) error
+
+	// Tx runs fn with a store whose changes are all committed when fn returns nil,
+	// and all discarded when it returns an error. The store must not be used after fn returns.
+	Tx(fn func(tx TaskStore) error) error
+	// Purge removes for good the tasks deleted before the given time and returns their number.
+	Purge(deletedBefore time.Time) (int, error)
+
+	// All returns every stored task, deleted ones included, oldest first.
+	All() ([]*Task, error)
+	// Put stores the tasks as they are, replacing the ones with the same IDs. It is meant for imports.
+	Put(tasks ...*Task) error
+
+	Close() error
+}
+
+// Store kinds accepted by OpenStore.
+const (
+	StoreJSONL  = "jsonl"
+	StoreSQLite = "sqlite"
+)
+
+// OpenStore opens a store of the given kind: a directory holding tasks.jsonl for StoreJSONL,
+// a database file for StoreSQLite.
+func OpenStore(kind, path string) (TaskStore, error) {
+	switch kind {
+	case "", StoreJSONL:
+		return NewStorage(path)
+	case StoreSQLite:
+		return NewSQLiteStore(path)
+	default:
+		return nil, fmt.Errorf("unknown task store %q, expected %s or %s", kind, StoreJSONL, StoreSQLite)
+	}
+}
+
+var (
+	defaultStorage     TaskStore
+	defaultStorageOnce sync.Once
+)
+
+// GetDefaultStorage returns the store shared by the tool, the web UI and the reminders.
+// Unless InitDefaultStorage was called first, it is chosen by environment variables:
+//   - TASK_STORE: "jsonl" (default) or "sqlite".
+//   - TASK_DIR: directory of the store, defaults to "./data/task".
+func GetDefaultStorage() TaskStore {
+	defaultStorageOnce.Do(func() {
+		dir := os.Getenv("TASK_DIR")
+		if dir == "" {
+			dir = "./data/task"
+		}
+		s, err := openDefaultStorage(dir)
+		if err != nil {
+			panic(fmt.Sprintf("open task store failed: %v", err))
+		}
+		defaultStorage = s
+	})
+	if defaultStorage == nil {
+		panic("no default task store: InitDefaultStorage failed")
+	}
+	return defaultStorage
+}
+
+// InitDefaultStorage makes the store in dataDir the default one, of the kind given by TASK_STORE.
+// It fails once the default store is chosen, by an earlier call to it or to GetDefaultStorage.
+func InitDefaultStorage(dataDir string) error {
+	var err error
+	initialized := false
+	defaultStorageOnce.Do(func() {
+		initialized = true
+		defaultStorage, err = openDefaultStorage(dataDir)
+	})
+	if !initialized {
+		return errors.New("default task store already initialized")
+	}
+	return err
+}
+
+// openDefaultStorage opens the store of the kind given by TASK_STORE in dir.
+func openDefaultStorage(dir string) (TaskStore, error) {
+	path := dir
+	kind := strings.ToLower(os.Getenv("TASK_STORE"))
+	if kind == StoreSQLite {
+		path = filepath.Join(dir, "tasks.db")
+	}
+	return OpenStore(kind, path)
+}
+
+// changeSet records the tasks written by the operations of a store on top of the stored ones,
+// so that the backends share the rules of Add, Update and Delete and only differ in how
+// the written tasks are read and saved. A nil task records a purged one.
+type changeSet struct {
+	load    func(id string) (*Task, bool, error)
+	changed map[string]*Task
+	order   []string
+}
+
+func newChangeSet(load func(id string) (*Task, bool, error)) *changeSet {
+	return &changeSet{load: load, changed: make(map[string]*Task)}
+}
+
+func (c *changeSet) get(id string) (*Task, bool, error) {
+	if t, ok := c.changed[id]; ok {
+		return t, t != nil, nil
+	}
+	return c.load(id)
+}
+
+func (c *changeSet) put(t *Task) {
+	c.set(t.ID, t)
+}
+
+func (c *changeSet) remove(id string) {
+	c.set(id, nil)
+}
+
+func (c *changeSet) set(id string, t *Task) {
+	if _, ok := c.changed[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.changed[id] = t
+}
+
+// tasks returns the written tasks in the order they were first written.
+func (c *changeSet) tasks() []*Task {
+	out := make([]*Task, 0, len(c.order))
+	for _, id := range c.order {
+		if t := c.changed[id]; t != nil {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (c *changeSet) add(task *Task, now time.Time) error {
+	if err := task.validate(); err != nil {
+		return err
+	}
+	if task.Recurrence != nil && !isSet(task.Deadline) {
+		return fmt.Errorf("a recurring task needs a deadline")
+	}
+	if task.ID == "" {
+		return fmt.Errorf("task id is required")
+	}
+	if _, exists, err := c.get(task.ID); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("task already exists: %s", task.ID)
+	}
+
+	task.CreatedAt = Timestamp{Time: now}
+	task.UpdatedAt, task.CompletedAt, task.DeletedAt, task.NextID = nil, nil, nil, ""
+	if task.Completed {
+		task.CompletedAt = NewTimestamp(now)
+	}
+	task.IsDeleted = false
+	cp := *task
+	c.put(&cp)
+	return nil
+}
+
+func (c *changeSet) getLive(id string) (*Task, error) {
+	existing, exists, err := c.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || existing.IsDeleted {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, id)
+	}
+	return existing, nil
+}
+
+func (c *changeSet) update(task *Task, now time.Time) (*Task, error) {
+	if err := task.validate(); err != nil {
+		return nil, err
+	}
+	existing, err := c.getLive(task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只更新非空字段
+	updated := *existing // 创建副本
+	if task.Title != "" {
+		updated.Title = task.Title
+	}
+	if task.Content != "" {
+		updated.Content = task.Content
+	}
+	if task.Deadline != nil {
+		updated.Deadline = task.Deadline
+	}
+	if task.Priority != "" {
+		updated.Priority = task.Priority
+	}
+	if task.Tags != nil {
+		updated.Tags = task.Tags
+	}
+	if task.Subtasks != nil {
+		updated.Subtasks = task.Subtasks
+	}
+	if task.Recurrence != nil {
+		updated.Recurrence = task.Recurrence
+	}
+	if updated.Recurrence != nil && !isSet(updated.Deadline) {
+		return nil, fmt.Errorf("a recurring task needs a deadline")
+	}
+	// Completed 字段需要特殊处理，因为它是布尔值
+	if task.Completed != existing.Completed {
+		updated.Completed = task.Completed
+		updated.CompletedAt = nil
+		if updated.Completed {
+			updated.CompletedAt = NewTimestamp(now)
+			// the recurrence moves on to the next occurrence, so that completing
+			// this one again does not add another
+			if next := updated.nextOccurrence(now); next != nil {
+				c.put(next)
+				updated.NextID = next.ID
+				updated.Recurrence = nil
+			}
+		}
+	}
+	updated.UpdatedAt = NewTimestamp(now)
+	c.put(&updated)
+
+	cp := updated
+	return &cp, nil
+}
+
+func (c *changeSet) delete(id string, now time.Time) error {
+	existing, err := c.getLive(id)
+	if err != nil {
+		return err
+	}
+	// 标记删除
+	deleted := *existing
+	deleted.IsDeleted = true
+	deleted.DeletedAt = NewTimestamp(now)
+	c.put(&deleted)
+	return nil
+}
+
+// purgeable reports whether t was deleted before the given time. Tasks deleted before
+// deletion times were recorded count as deleted long ago.
+func (t *Task) purgeable(deletedBefore time.Time) bool {
+	return t.IsDeleted && (t.DeletedAt == nil || t.DeletedAt.Before(deletedBefore))
+}
+
+// filterTasks returns copies of the live tasks matching params in the order they ask for.
+// Backends may narrow the tasks beforehand, but the final word is given here.
+func filterTasks(all []*Task, params *ListParams) ([]*Task, error) {
+	if params == nil {
+		params = &ListParams{}
+	}
+	less, err := taskLess(params)
+	if err != nil {
+		return nil, err
+	}
+	tags := normalizeTags(params.Tags)
+	priorities := make(map[Priority]bool, len(params.Priorities))
+	for _, p := range params.Priorities {
+		if !p.valid() {
+			return nil, fmt.Errorf("invalid priority %q", p)
+		}
+		priorities[p] = true
+	}
+
+	var activeTasks, completedTasks []*Task
+	for _, task := range all {
+		if task.IsDeleted {
+			continue
+		}
+
+		if params.Query != "" && !task.matches(params.Query) {
+			continue
+		}
+
+		if params.IsDone != nil {
+			if task.Completed != *params.IsDone {
+				continue
+			}
+		}
+
+		if !task.hasTags(tags) {
+			continue
+		}
+		if len(priorities) > 0 && !priorities[task.Priority] && !(task.Priority == "" && priorities[PriorityMedium]) {
+			continue
+		}
+		if (isSet(params.DueAfter) || isSet(params.DueBefore)) && !isSet(task.Deadline) {
+			continue
+		}
+		if isSet(params.DueAfter) && task.Deadline.Before(params.DueAfter.Time) {
+			continue
+		}
+		if isSet(params.DueBefore) && task.Deadline.After(params.DueBefore.Time) {
+			continue
+		}
+		if isSet(params.CreatedAfter) && task.CreatedAt.Before(params.CreatedAfter.Time) {
+			continue
+		}
+		if isSet(params.CreatedBefore) && task.CreatedAt.After(params.CreatedBefore.Time) {
+			continue
+		}
+
+		cp := *task
+		if task.Completed {
+			completedTasks = append(completedTasks, &cp)
+		} else {
+			activeTasks = append(activeTasks, &cp)
+		}
+	}
+
+	sort.SliceStable(activeTasks, func(i, j int) bool {
+		return less(activeTasks[i], activeTasks[j])
+	})
+	sort.SliceStable(completedTasks, func(i, j int) bool {
+		return less(completedTasks[i], completedTasks[j])
+	})
+
+	// 合并列表：未完成的在前，已完成的在后
+	tasks := append(activeTasks, completedTasks...)
+
+	if params.Limit != nil && *params.Limit >= 0 && len(tasks) > *params.Limit {
+		tasks = tasks[:*params.Limit]
+	}
+
+	return tasks, nil
+}
+
+func sortByCreation(tasks []*Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt.Time)
+	})
+}
+
+// Export writes every task of store, deleted ones included, as JSON lines.
+func Export(store TaskStore, w io.Writer) (int, error) {
+	tasks, err := store.All()
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(w)
+	for _, t := range tasks {
+		data, err := json.Marshal(t)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal task: %v", err)
+		}
+		bw.Write(data)
+		bw.WriteByte('\n')
+	}
+	return len(tasks), bw.Flush()
+}
+
+// Import stores the tasks read as JSON lines, as written by Export or found in tasks.jsonl,
+// in a single transaction. Tasks with the IDs of stored ones replace them.
+func Import(store TaskStore, r io.Reader) (int, error) {
+	var tasks []*Task
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, err := br.ReadBytes('\n')
+		if len(strings.TrimSpace(string(data))) > 0 {
+			task, derr := decodeTask(data)
+			if derr != nil {
+				return 0, fmt.Errorf("line %d: %v", line, derr)
+			}
+			if task.ID == "" {
+				return 0, fmt.Errorf("line %d: task id is required", line)
+			}
+			tasks = append(tasks, task)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := store.Tx(func(tx TaskStore) error { return tx.Put(tasks...) }); err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
+
+// Copy stores every task of src in dst, e.g. to move from one backend to another.
+func Copy(dst, src TaskStore) (int, error) {
+	tasks, err := src.All()
+	if err != nil {
+		return 0, err
+	}
+	if err = dst.Tx(func(tx TaskStore) error { return tx.Put(tasks...) }); err != nil {
+		return 0, err
+	}
+	return len(tasks), nil
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func eachStore(t *testing.T, fn func(t *testing.T, s TaskStore)) {
+	for _, kind := range []string{StoreJSONL, StoreSQLite} {
+		t.Run(kind, func(t *testing.T) {
+			path := t.TempDir()
+			if kind == StoreSQLite {
+				path = filepath.Join(path, "tasks.db")
+			}
+			s, err := OpenStore(kind, path)
+			if err != nil {
+				skipWithoutSQLite(t, kind, err)
+				t.Fatal(err)
+			}
+			defer s.Close()
+			fn(t, s)
+		})
+	}
+}
+
+// skipWithoutSQLite skips the test when the SQLite store failed to open, as it does in a test
+// binary built with CGO_ENABLED=0, where go-sqlite3 is only a stub.
+func skipWithoutSQLite(t *testing.T, kind string, err error) {
+	if kind == StoreSQLite {
+		t.Skipf("sqlite store unavailable: %v", err)
+	}
+}
+
+func TestStoreTx(t *testing.T) {
+	eachStore(t, func(t *testing.T, s TaskStore) {
+		if err := s.Add(&Task{ID: "a", Title: "a"}); err != nil {
+			t.Fatal(err)
+		}
+
+		failed := errors.New("failed")
+		err := s.Tx(func(tx TaskStore) error {
+			if err := tx.Add(&Task{ID: "b", Title: "b"}); err != nil {
+				return err
+			}
+			if _, err := tx.Update(&Task{ID: "a", Title: "renamed"}); err != nil {
+				return err
+			}
+			if got, err := tx.Get("b"); err != nil || got.Title != "b" {
+				t.Fatalf("the transaction should see its own changes, got %v (%v)", got, err)
+			}
+			return failed
+		})
+		if !errors.Is(err, failed) {
+			t.Fatalf("expected the error of the function, got %v", err)
+		}
+		if _, err = s.Get("b"); !errors.Is(err, ErrTaskNotFound) {
+			t.Fatalf("expected the add to be rolled back, got %v", err)
+		}
+		if a, _ := s.Get("a"); a.Title != "a" {
+			t.Fatalf("expected the update to be rolled back, got %q", a.Title)
+		}
+
+		err = s.Tx(func(tx TaskStore) error {
+			if err := tx.Add(&Task{ID: "b", Title: "b"}); err != nil {
+				return err
+			}
+			return tx.Delete("a")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks, _ := s.List(nil)
+		if len(tasks) != 1 || tasks[0].ID != "b" {
+			t.Fatalf("expected only b after the commit, got %v", tasks)
+		}
+	})
+}
+
+func TestStorePurge(t *testing.T) {
+	eachStore(t, func(t *testing.T, s TaskStore) {
+		for _, id := range []string{"a", "b", "c"} {
+			if err := s.Add(&Task{ID: id, Title: id}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_ = s.Delete("a")
+		cutoff := time.Now()
+		time.Sleep(time.Millisecond)
+		_ = s.Delete("b")
+
+		n, err := s.Purge(cutoff)
+		if err != nil || n != 1 {
+			t.Fatalf("expected a to be purged, got %d (%v)", n, err)
+		}
+		all, _ := s.All()
+		if len(all) != 2 || all[0].ID != "b" || !all[0].IsDeleted || all[0].DeletedAt == nil {
+			t.Fatalf("expected b deleted and c, got %v", all)
+		}
+	})
+}
+
+func TestExportImport(t *testing.T) {
+	src, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = src.Add(&Task{ID: "a", Title: "a", Tags: []string{"x"}, Deadline: NewTimestamp(time.Now().Add(time.Hour))})
+	_ = src.Add(&Task{ID: "b", Title: "b"})
+	_ = src.Delete("b")
+
+	dst, err := NewSQLiteStore(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		skipWithoutSQLite(t, StoreSQLite, err)
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	if n, err := Copy(dst, src); err != nil || n != 2 {
+		t.Fatalf("copy: %d (%v)", n, err)
+	}
+	// deleted tasks are copied too, so that they can still be purged
+	if all, _ := dst.All(); len(all) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(all))
+	}
+	if due, _ := dst.List(&ListParams{DueBefore: NewTimestamp(time.Now().Add(2 * time.Hour))}); len(due) != 1 || due[0].Tags[0] != "x" {
+		t.Fatalf("unexpected tasks due %v", due)
+	}
+
+	var buf bytes.Buffer
+	if n, err := Export(dst, &buf); err != nil || n != 2 {
+		t.Fatalf("export: %d (%v)", n, err)
+	}
+	back, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Import(back, &buf); err != nil || n != 2 {
+		t.Fatalf("import: %d (%v)", n, err)
+	}
+	if a, err := back.Get("a"); err != nil || a.CreatedAt.Unix() != mustGet(t, src, "a").CreatedAt.Unix() {
+		t.Fatalf("expected the task to keep its timestamps, got %v (%v)", a, err)
+	}
+
+	if _, err = Import(back, strings.NewReader(`{"title":"no id"}`)); err == nil {
+		t.Fatalf("expected an error for a task without id")
+	}
+}
+
+func mustGet(t *testing.T, s TaskStore, id string) *Task {
+	task, err := s.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func TestSQLiteStoreEscapesPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a?b#c%d", "tasks.db")
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		skipWithoutSQLite(t, StoreSQLite, err)
+		t.Fatal(err)
+	}
+	if err = s.Add(&Task{ID: "a", Title: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if _, err = os.Stat(path); err != nil {
+		t.Fatalf("the database should be at the given path: %v", err)
+	}
+	s, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, err := s.Get("a"); err != nil || got.Title != "a" {
+		t.Fatalf("expected the stored task, got %v (%v)", got, err)
+	}
+}
+
+func TestJSONLAppendsAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Add(&Task{ID: "a", Title: "a"})
+	_, _ = s.Update(&Task{ID: "a", Title: "b"})
+	_, _ = s.Update(&Task{ID: "a", Title: "c"})
+
+	path := filepath.Join(dir, "tasks.jsonl")
+	data, _ := os.ReadFile(path)
+	if got := strings.Count(string(data), "\n"); got != 3 {
+		t.Fatalf("expected one appended line per change, got %d:\n%s", got, data)
+	}
+
+	// a line cut by a crash is dropped, and the file rewritten so that appends stay readable
+	if err = os.WriteFile(path, append(data, `{"id":"b","ti`...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := mustGet(t, s, "a"); a.Title != "c" {
+		t.Fatalf("expected the last line to win, got %q", a.Title)
+	}
+	data, _ = os.ReadFile(path)
+	if got := strings.Count(string(data), "\n"); got != 1 || strings.Contains(string(data), `"ti`+"\n") {
+		t.Fatalf("expected the file to be compacted, got:\n%s", data)
+	}
+}
@@ -40,10 +40,54 @@ type Task struct {
 	Title     string `json:"title" jsonschema_description:"title of the task"`
 	Content   string `json:"content" jsonschema_description:"content of the task"`
 	Completed bool   `json:"completed" jsonschema_description:"completed status of the task"`
-	Deadline  string `json:"deadline" jsonschema_description:"deadline of the task"`
-	IsDeleted bool   `json:"is_deleted" jsonschema:"-"`
 
-	CreatedAt string `json:"created_at" jsonschema_description:"created time of the task"`
+	Priority Priority   `json:"priority,omitempty" jsonschema:"enum=low,enum=medium,enum=high,enum=urgent" jsonschema_description:"priority of the task, medium if not set"`
+	Tags     []string   `json:"tags,omitempty" jsonschema_description:"tags of the task, an update replaces all of them"`
+	Deadline *Timestamp `json:"deadline,omitempty" jsonschema_description:"deadline of the task"`
+	Subtasks []*Subtask `json:"subtasks,omitempty" jsonschema_description:"steps of the task, an update replaces all of them"`
+
+	// Recurrence makes the task repeat: completing it adds the next occurrence, due one period later.
+	Recurrence *Recurrence `json:"recurrence,omitempty" jsonschema_description:"repeats the task, it needs a deadline"`
+	// NextID is the ID of the occurrence added when this recurring task was completed.
+	NextID string `json:"next_id,omitempty" jsonschema:"-"`
+
+	IsDeleted bool       `json:"is_deleted" jsonschema:"-"`
+	DeletedAt *Timestamp `json:"deleted_at,omitempty" jsonschema:"-"`
+
+	CreatedAt   Timestamp  `json:"created_at" jsonschema:"-"`
+	UpdatedAt   *Timestamp `json:"updated_at,omitempty" jsonschema:"-"`
+	CompletedAt *Timestamp `json:"completed_at,omitempty" jsonschema:"-"`
+}
+
+type Subtask struct {
+	ID        string `json:"id,omitempty" jsonschema_description:"id of the subtask, set by the tool"`
+	Title     string `json:"title" jsonschema_description:"title of the subtask"`
+	Completed bool   `json:"completed" jsonschema_description:"completed status of the subtask"`
+}
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+	FrequencyYearly  Frequency = "yearly"
+)
+
+type Recurrence struct {
+	Frequency Frequency  `json:"frequency" jsonschema:"enum=daily,enum=weekly,enum=monthly,enum=yearly" jsonschema_description:"how often the task repeats"`
+	Interval  int        `json:"interval,omitempty" jsonschema_description:"number of periods between occurrences, 1 if not set"`
+	Until     *Timestamp `json:"until,omitempty" jsonschema_description:"no occurrence is due after this time"`
+	AnchorDay int        `json:"anchor_day,omitempty" jsonschema_description:"day of the month monthly and yearly occurrences fall on, the day of the first deadline if not set"`
 }
 
 type TaskRequest struct {
@@ -52,10 +96,31 @@ type TaskRequest struct {
 	List   *ListParams `json:"list" jsonschema_description:"list parameters"`
 }
 
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByDeadline  SortField = "deadline"
+	SortByPriority  SortField = "priority"
+	SortByTitle     SortField = "title"
+)
+
 type ListParams struct {
-	Query  string `json:"query" jsonschema_description:"query to search"`
+	Query  string `json:"query" jsonschema_description:"query to search in title, content, tags and subtasks"`
 	IsDone *bool  `json:"is_done" jsonschema_description:"filter by completed status"`
 	Limit  *int   `json:"limit" jsonschema_description:"limit the number of results"`
+
+	Tags       []string   `json:"tags,omitempty" jsonschema_description:"only tasks having all these tags"`
+	Priorities []Priority `json:"priorities,omitempty" jsonschema_description:"only tasks having one of these priorities"`
+
+	DueAfter      *Timestamp `json:"due_after,omitempty" jsonschema_description:"only tasks due at or after this time"`
+	DueBefore     *Timestamp `json:"due_before,omitempty" jsonschema_description:"only tasks due at or before this time"`
+	CreatedAfter  *Timestamp `json:"created_after,omitempty" jsonschema_description:"only tasks created at or after this time"`
+	CreatedBefore *Timestamp `json:"created_before,omitempty" jsonschema_description:"only tasks created at or before this time"`
+
+	SortBy SortField `json:"sort_by,omitempty" jsonschema:"enum=created_at,enum=updated_at,enum=deadline,enum=priority,enum=title" jsonschema_description:"sort key, created_at if not set; uncompleted tasks always come first"`
+	Order  string    `json:"order,omitempty" jsonschema:"enum=asc,enum=desc" jsonschema_description:"sort order, newest, soonest, most important or alphabetical first if not set"`
 }
 
 type TaskResponse struct {
@@ -71,7 +136,7 @@ type TaskToolImpl struct {
 }
 
 type TaskToolConfig struct {
-	Storage *Storage
+	Storage TaskStore
 }
 
 func defaultTaskToolConfig(ctx context.Context) (*TaskToolConfig, error) {
@@ -157,12 +222,28 @@ func (t *TaskToolImpl) Invoke(ctx context.Context, req *TaskRequest) (res *TaskR
 			res.Error = "id is required"
 			return res, nil
 		}
-		if err := t.config.Storage.Update(req.Task); err != nil {
+		// the task and the occurrence its completion adds are read in the same transaction
+		err := t.config.Storage.Tx(func(tx TaskStore) error {
+			updated, err := tx.Update(req.Task)
+			if err != nil {
+				return err
+			}
+			res.TaskList = []*Task{updated}
+			if updated.NextID != "" {
+				next, err := tx.Get(updated.NextID)
+				if err != nil {
+					return err
+				}
+				res.TaskList = append(res.TaskList, next)
+			}
+			return nil
+		})
+		if err != nil {
+			res.TaskList = nil
 			res.Status = "error"
 			res.Error = fmt.Sprintf("failed to update task: %v", err)
 			return res, nil
 		}
-		res.TaskList = []*Task{req.Task}
 
 	case ActionDelete:
 		if req.Task == nil || req.Task.ID == "" {
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2025 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampLayouts(t *testing.T) {
+	for _, s := range []string{`"2025-01-15T18:00:00+08:00"`, `"2025-01-15T18:00"`, `"2025-01-15 18:00"`, `"2025-01-15"`} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(s), &ts); err != nil || ts.IsZero() {
+			t.Fatalf("failed to decode %s: %v", s, err)
+		}
+	}
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"tomorrow"`), &ts); err == nil {
+		t.Fatalf("expected an error for a free-text time")
+	}
+
+	// a legacy free-text deadline is kept in the content instead of failing the load
+	task, err := decodeTask([]byte(`{"id":"1","title":"a","content":"b","deadline":"next friday"}`))
+	if err != nil || task.Deadline != nil || !strings.Contains(task.Content, "next friday") {
+		t.Fatalf("unexpected legacy task %+v (%v)", task, err)
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	jan31 := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	r := &Recurrence{Frequency: FrequencyMonthly}
+	if next, _ := r.Next(jan31); !next.Equal(time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the end of February, got %v", next)
+	}
+
+	// the anchor day keeps the end of the month after a shorter one
+	task := &Task{Deadline: NewTimestamp(jan31), Recurrence: &Recurrence{Frequency: FrequencyMonthly}}
+	feb := task.nextOccurrence(jan31)
+	if feb == nil || !feb.Deadline.Equal(time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the end of February, got %+v", feb)
+	}
+	mar := feb.nextOccurrence(feb.Deadline.Time)
+	if mar == nil || !mar.Deadline.Equal(time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the end of March, got %+v", mar)
+	}
+	if task.Recurrence.AnchorDay != 0 {
+		t.Fatalf("the recurrence of the completed task must not change")
+	}
+
+	r = &Recurrence{Frequency: FrequencyWeekly, Interval: 2, Until: NewTimestamp(jan31.AddDate(0, 0, 20))}
+	if next, ok := r.Next(jan31); !ok || !next.Equal(jan31.AddDate(0, 0, 14)) {
+		t.Fatalf("expected two weeks later, got %v %v", next, ok)
+	}
+	if _, ok := r.Next(jan31.AddDate(0, 0, 14)); ok {
+		t.Fatalf("expected no occurrence after until")
+	}
+}
+
+func TestCompleteRecurringTask(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().Add(time.Hour)
+	if err = s.Add(&Task{
+		ID: "daily", Title: "standup", Deadline: NewTimestamp(due),
+		Recurrence: &Recurrence{Frequency: FrequencyDaily},
+		Subtasks:   []*Subtask{{Title: "notes"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := s.Update(&Task{ID: "daily", Completed: true})
+	if err != nil || done.NextID == "" || done.Recurrence != nil {
+		t.Fatalf("expected the next occurrence to be added, got %+v (%v)", done, err)
+	}
+	next, err := s.Get(done.NextID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Completed || !next.Deadline.Equal(due.AddDate(0, 0, 1)) || next.Recurrence == nil || next.Subtasks[0].Completed {
+		t.Fatalf("unexpected next occurrence %+v", next)
+	}
+}
+
+func TestListFiltersAndSort(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, task := range []*Task{
+		{ID: "1", Title: "a", Priority: PriorityLow, Tags: []string{"Work"}, Deadline: NewTimestamp(now.Add(2 * time.Hour))},
+		{ID: "2", Title: "b", Priority: PriorityUrgent, Tags: []string{"work", "home"}, Deadline: NewTimestamp(now.Add(-time.Hour))},
+		{ID: "3", Title: "c", Priority: PriorityHigh, Deadline: NewTimestamp(now.Add(72 * time.Hour))},
+		{ID: "4", Title: "d"},
+	} {
+		if err = s.Add(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(params *ListParams) string {
+		tasks, err := s.List(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, task := range tasks {
+			out = append(out, task.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(&ListParams{Tags: []string{"WORK"}, SortBy: SortByPriority}); got != "2,1" {
+		t.Fatalf("tag filter: got %s", got)
+	}
+	if got := ids(&ListParams{DueBefore: NewTimestamp(now.Add(24 * time.Hour)), SortBy: SortByDeadline}); got != "2,1" {
+		t.Fatalf("due filter: got %s", got)
+	}
+	if got := ids(&ListParams{SortBy: SortByDeadline, Order: "desc"}); got != "3,1,2,4" {
+		t.Fatalf("deadline sort: got %s", got)
+	}
+	if got := ids(&ListParams{Priorities: []Priority{PriorityHigh, PriorityUrgent}, SortBy: SortByTitle}); got != "2,3" {
+		t.Fatalf("priority filter: got %s", got)
+	}
+}
+
+func TestReminder(t *testing.T) {
+	s, err := NewStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	_ = s.Add(&Task{ID: "late", Title: "report", Deadline: NewTimestamp(now.Add(-time.Hour))})
+	_ = s.Add(&Task{ID: "soon", Title: "call", Priority: PriorityHigh, Deadline: NewTimestamp(now.Add(time.Hour))})
+	_ = s.Add(&Task{ID: "later", Title: "trip", Deadline: NewTimestamp(now.Add(48 * time.Hour))})
+	_ = s.Add(&Task{ID: "done", Title: "old", Completed: true, Deadline: NewTimestamp(now.Add(-time.Hour))})
+
+	var notified []string
+	rs, err := NewReminderScheduler(&ReminderConfig{Storage: s, OnDue: func(_ context.Context, task *Task, overdue bool) {
+		notified = append(notified, task.ID)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := rs.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Overdue) != 1 || r.Overdue[0].ID != "late" || len(r.Upcoming) != 1 || r.Upcoming[0].ID != "soon" {
+		t.Fatalf("unexpected reminder %s", r)
+	}
+	if text := r.String(); !strings.Contains(text, "[high] call") || !strings.Contains(text, "ago") {
+		t.Fatalf("unexpected reminder text:\n%s", text)
+	}
+
+	// a task is notified once
+	rs.tick(context.Background())
+	rs.tick(context.Background())
+	if strings.Join(notified, ",") != "late,soon" {
+		t.Fatalf("unexpected notifications %v", notified)
+	}
+}